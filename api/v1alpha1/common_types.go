@@ -33,6 +33,12 @@ limitations under the License.
 
 package v1alpha1
 
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 // CopyMethodType defines the methods for creating point-in-time copies of
 // volumes.
 //+kubebuilder:validation:Enum=Direct;None;Clone;Snapshot
@@ -50,6 +56,20 @@ const (
 	CopyMethodSnapshot CopyMethodType = "Snapshot"
 )
 
+// DeletionPolicyType determines what happens to the replicated data when a
+// ReplicationSource or ReplicationDestination is deleted.
+//+kubebuilder:validation:Enum=Retain;Delete
+type DeletionPolicyType string
+
+const (
+	// DeletionPolicyRetain leaves the replicated data in place when the object
+	// is deleted.
+	DeletionPolicyRetain DeletionPolicyType = "Retain"
+	// DeletionPolicyDelete removes the replicated data when the object is
+	// deleted.
+	DeletionPolicyDelete DeletionPolicyType = "Delete"
+)
+
 const (
 	// ConditionReconciled is a status condition type that indicates whether the
 	// CR has been successfully reconciled
@@ -59,6 +79,9 @@ const (
 	// ReconciledReasonError indicates an error was encountered while
 	// reconciling the CR
 	ReconciledReasonError string = "ReconcileError"
+	// ReconciledReasonVolumeInUse indicates the data mover is unable to
+	// proceed because the volume is exclusively in use by another Pod
+	ReconciledReasonVolumeInUse string = "VolumeInUse"
 )
 
 const (
@@ -67,8 +90,107 @@ const (
 	SynchronizingReasonSched   string = "WaitingForSchedule"
 	SynchronizingReasonManual  string = "WaitingForManual"
 	SynchronizingReasonCleanup string = "CleaningUp"
+	// SynchronizingReasonQueued indicates the synchronization is waiting for
+	// others to complete because the concurrency limit has been reached
+	SynchronizingReasonQueued string = "Queued"
 )
 
+const (
+	// ConditionDegraded is a status condition type that indicates whether the
+	// most recent synchronization attempt has failed
+	ConditionDegraded string = "Degraded"
+	// DegradedReasonSyncFailed indicates the most recent synchronization
+	// attempt failed. It will be retried.
+	DegradedReasonSyncFailed string = "SyncFailed"
+	// DegradedReasonSyncSucceeded indicates the most recent synchronization
+	// completed successfully
+	DegradedReasonSyncSucceeded string = "SyncSucceeded"
+	// DegradedReasonSyncTimedOut indicates the most recent synchronization
+	// exceeded its syncTimeout
+	DegradedReasonSyncTimedOut string = "SyncTimedOut"
+	// DegradedReasonRetriesExhausted indicates the most recent
+	// synchronization failed on each of the attempts allowed by its
+	// retryPolicy
+	DegradedReasonRetriesExhausted string = "RetriesExhausted"
+	// DegradedReasonRepositoryLocked indicates the most recent synchronization
+	// attempt failed because the backup repository is locked
+	DegradedReasonRepositoryLocked string = "RepositoryLocked"
+)
+
+// SyncResultType describes the outcome of a synchronization attempt
+//+kubebuilder:validation:Enum=Succeeded;Failed;TimedOut;RetriesExhausted
+type SyncResultType string
+
+const (
+	// SyncResultSucceeded indicates the synchronization completed successfully
+	SyncResultSucceeded SyncResultType = "Succeeded"
+	// SyncResultFailed indicates the synchronization attempt failed
+	SyncResultFailed SyncResultType = "Failed"
+	// SyncResultTimedOut indicates the synchronization was stopped because it
+	// exceeded its syncTimeout
+	SyncResultTimedOut SyncResultType = "TimedOut"
+	// SyncResultRetriesExhausted indicates the synchronization was abandoned
+	// after reaching the maxAttempts of its retryPolicy
+	SyncResultRetriesExhausted SyncResultType = "RetriesExhausted"
+)
+
+// SyncResult records the outcome of the most recent synchronization attempt
+type SyncResult struct {
+	// result is the outcome of the attempt, either Succeeded or Failed.
+	Result SyncResultType `json:"result"`
+	// attempts is the number of attempts made for the current
+	// synchronization, including this one.
+	//+optional
+	Attempts int32 `json:"attempts,omitempty"`
+	// exitCode is the exit code of the data mover container for a failed
+	// attempt.
+	//+optional
+	ExitCode *int32 `json:"exitCode,omitempty"`
+	// message describes the reason for a failure. It is taken from the data
+	// mover's termination message or the final lines of its log.
+	//+optional
+	Message string `json:"message,omitempty"`
+	// time is when the result was recorded.
+	//+optional
+	Time *metav1.Time `json:"time,omitempty"`
+}
+
+// RetryExhaustedActionType determines what happens once a synchronization has
+// failed on each of the attempts allowed by its retryPolicy.
+//+kubebuilder:validation:Enum=Retry;Skip;Pause
+type RetryExhaustedActionType string
+
+const (
+	// RetryExhaustedRetry continues to retry the synchronization
+	RetryExhaustedRetry RetryExhaustedActionType = "Retry"
+	// RetryExhaustedSkip abandons the synchronization, and the next one
+	// begins at the following scheduled time
+	RetryExhaustedSkip RetryExhaustedActionType = "Skip"
+	// RetryExhaustedPause abandons the synchronization and pauses replication
+	RetryExhaustedPause RetryExhaustedActionType = "Pause"
+)
+
+// RetryPolicySpec determines how a synchronization is retried when the data
+// mover fails.
+type RetryPolicySpec struct {
+	// maxAttempts is the number of times the data mover is run during a
+	// synchronization before the onExhausted action is taken. When it is set,
+	// each failed mover Pod counts as an attempt.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	MaxAttempts *int32 `json:"maxAttempts,omitempty"`
+	// backoffDelay is the amount of time to wait after a failed attempt before
+	// the next one is started.
+	//+optional
+	BackoffDelay *metav1.Duration `json:"backoffDelay,omitempty"`
+	// onExhausted is the action taken after maxAttempts failed attempts:
+	// Retry continues to retry the synchronization, Skip waits for the next
+	// scheduled synchronization, and Pause sets paused to true. Defaults to
+	// Retry.
+	//+optional
+	OnExhausted RetryExhaustedActionType `json:"onExhausted,omitempty"`
+}
+
 type SyncthingPeer struct {
 	// TCP address of the Syncthing peer
 	Address string `json:"address"`
@@ -87,3 +209,173 @@ type SyncthingPeerStatus struct {
 	// Whether the peer is currently connected
 	Connected bool `json:"connected"`
 }
+
+// MoverPodTemplateSpec contains settings that are applied to the Pods that
+// VolSync creates to perform data movement.
+type MoverPodTemplateSpec struct {
+	// resources are the compute resources required by the data mover
+	// container.
+	//+optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// nodeSelector must match a node's labels for the mover Pod to be
+	// scheduled on that node.
+	//+optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// tolerations allow the mover Pod to be scheduled onto nodes with matching
+	// taints.
+	//+optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+	// affinity contains the scheduling constraints for the mover Pod.
+	//+optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+	// priorityClassName is the name of the PriorityClass to assign to the
+	// mover Pod.
+	//+optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+	// image overrides the container image of the data mover. It takes
+	// precedence over an image set in the namespace's volsync-mover-images
+	// ConfigMap and over the image that VolSync is configured with. It is
+	// only permitted if the operator allows custom mover images.
+	//+optional
+	Image string `json:"image,omitempty"`
+	// customCA is a bundle of certificate authorities that the mover trusts
+	// when connecting to remote storage. It replaces the default bundle that
+	// VolSync is configured with.
+	//+optional
+	CustomCA *CustomCASpec `json:"customCA,omitempty"`
+	// proxy configures the HTTP proxy used by the mover. It replaces the
+	// default proxy settings that VolSync is configured with.
+	//+optional
+	Proxy *ProxySpec `json:"proxy,omitempty"`
+}
+
+// CustomCASpec refers to a bundle of PEM-encoded certificate authority
+// certificates that are trusted in addition to the system's. Exactly one of
+// configMapName or secretName must be provided.
+type CustomCASpec struct {
+	// configMapName is the name of a ConfigMap in the object's namespace that
+	// holds the bundle
+	//+optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// secretName is the name of a Secret in the object's namespace that holds
+	// the bundle
+	//+optional
+	SecretName string `json:"secretName,omitempty"`
+	// key is the key of the bundle within the ConfigMap or Secret. Defaults to
+	// "ca.crt".
+	//+optional
+	Key string `json:"key,omitempty"`
+}
+
+// ProxySpec configures the HTTP proxy that the mover uses to reach remote
+// storage
+type ProxySpec struct {
+	// httpProxy is the proxy for HTTP requests (HTTP_PROXY)
+	//+optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+	// httpsProxy is the proxy for HTTPS requests (HTTPS_PROXY)
+	//+optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	// noProxy is a comma-separated list of hosts and domains that are
+	// reached without the proxy (NO_PROXY)
+	//+optional
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// VolumeGroupSpec selects a set of PersistentVolumeClaims that are replicated
+// together. Exactly one of pvcNames or selector must be provided.
+type VolumeGroupSpec struct {
+	// pvcNames lists the names of the PVCs in the group.
+	//+optional
+	PVCNames []string `json:"pvcNames,omitempty"`
+	// selector selects the PVCs in the group by their labels. It may only be
+	// used with a ReplicationSource.
+	//+optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// VolumeGroupMember describes one of the volumes of a volume group
+type VolumeGroupMember struct {
+	// pvcName is the name of the PVC
+	PVCName string `json:"pvcName"`
+	// image is the object holding this volume's replicated data. It is only
+	// set on a ReplicationDestination.
+	//+optional
+	Image *corev1.TypedLocalObjectReference `json:"image,omitempty"`
+}
+
+// VolumeGroupStatus describes the most recent synchronization of a volume
+// group
+type VolumeGroupStatus struct {
+	// time is the single point in time represented by the data of all the
+	// group's volumes. On a ReplicationSource, it is when the copies of the
+	// volumes were created (it is not set for copyMethod Direct). On a
+	// ReplicationDestination, it is when the images were created.
+	//+optional
+	Time *metav1.Time `json:"time,omitempty"`
+	// members are the volumes of the group, ordered by PVC name.
+	//+optional
+	Members []VolumeGroupMember `json:"members,omitempty"`
+}
+
+// ResticSnapshot describes a snapshot in a restic repository
+type ResticSnapshot struct {
+	// id is the restic ID of the snapshot
+	ID string `json:"id"`
+	// time is when the snapshot was taken
+	Time metav1.Time `json:"time"`
+	// size is the total size of the files in the snapshot, if restic recorded
+	// it when the snapshot was taken
+	//+optional
+	Size *resource.Quantity `json:"size,omitempty"`
+	// paths are the paths that were saved in the snapshot
+	//+optional
+	Paths []string `json:"paths,omitempty"`
+}
+
+// ResticRepositoryStats summarizes the contents of a restic repository
+type ResticRepositoryStats struct {
+	// snapshotCount is the number of snapshots in the repository
+	SnapshotCount int32 `json:"snapshotCount"`
+	// totalSize is the amount of data stored in the repository, as of the last
+	// repository check
+	//+optional
+	TotalSize *resource.Quantity `json:"totalSize,omitempty"`
+}
+
+// ResticSecretKeysSpec selects the keys of the repository Secret that are
+// passed to restic as environment variables. Keys are matched with shell file
+// name patterns (e.g., "AWS_*"). RESTIC_REPOSITORY and RESTIC_PASSWORD are
+// always passed.
+type ResticSecretKeysSpec struct {
+	// allow lists the patterns of the keys that are passed. All keys are
+	// passed if it is empty.
+	//+optional
+	Allow []string `json:"allow,omitempty"`
+	// deny lists the patterns of the keys that are not passed. It takes
+	// precedence over allow.
+	//+optional
+	Deny []string `json:"deny,omitempty"`
+}
+
+// ResticIdentitySpec configures the identity that the restic mover uses to
+// authenticate to cloud storage with a token of its ServiceAccount
+type ResticIdentitySpec struct {
+	// serviceAccountAnnotations are added to the mover's ServiceAccount, such
+	// as to associate it with a cloud IAM role (e.g.,
+	// "eks.amazonaws.com/role-arn").
+	//+optional
+	ServiceAccountAnnotations map[string]string `json:"serviceAccountAnnotations,omitempty"`
+	// tokenAudience is the audience of a ServiceAccount token that is
+	// projected into the mover (e.g., "sts.amazonaws.com"). The token's path
+	// is provided in the AWS_WEB_IDENTITY_TOKEN_FILE and
+	// AZURE_FEDERATED_TOKEN_FILE environment variables. No token is projected
+	// if it is unset.
+	//+optional
+	TokenAudience string `json:"tokenAudience,omitempty"`
+	// tokenExpirationSeconds is the requested lifetime of the projected
+	// token. Defaults to 3600.
+	//+kubebuilder:validation:Minimum=600
+	//+optional
+	TokenExpirationSeconds *int64 `json:"tokenExpirationSeconds,omitempty"`
+}
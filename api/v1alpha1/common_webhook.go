@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This file may be used, at your option, according to either the GNU AGPL 3.0 or
+the Apache V2 license.
+
+---
+This program is free software: you can redistribute it and/or modify it under
+the terms of the GNU Affero General Public License as published by the Free
+Software Foundation, either version 3 of the License, or (at your option) any
+later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE.  See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along
+with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+---
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// defaultCopyMethod fills in the copyMethod if it has not been specified and
+// replaces the deprecated "None" with its equivalent, "Direct".
+func defaultCopyMethod(copyMethod *CopyMethodType) {
+	if *copyMethod == "" || *copyMethod == CopyMethodNone {
+		*copyMethod = CopyMethodDirect
+	}
+}
+
+// defaultDeletionPolicy sets an empty deletionPolicy to Retain
+func defaultDeletionPolicy(policy *DeletionPolicyType) {
+	if *policy == "" {
+		*policy = DeletionPolicyRetain
+	}
+}
+
+// validateSchedule ensures that a trigger's cronspec can be parsed the same
+// way the controllers parse it
+func validateSchedule(schedule *string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if schedule == nil {
+		return allErrs
+	}
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+	if _, err := parser.Parse(*schedule); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath, *schedule, err.Error()))
+	}
+	return allErrs
+}
+
+// validateMethodCount ensures exactly one replication method has been
+// specified. methods lists the field names of the methods that are set.
+func validateMethodCount(methods []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(methods) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath, "a replication method must be specified"))
+	} else if len(methods) > 1 {
+		allErrs = append(allErrs, field.Forbidden(fldPath,
+			"only a single replication method can be provided, found: "+strings.Join(methods, ", ")))
+	}
+	return allErrs
+}
+
+// validateVolumeGroup ensures a volume group selects its PVCs in exactly one
+// way and is only used with the methods that support it. methods lists the
+// field names of the methods that are set.
+func validateVolumeGroup(group *VolumeGroupSpec, allowSelector bool, methods []string,
+	fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(group.PVCNames) > 0 && group.Selector != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "only one of pvcNames or selector may be provided"))
+	} else if len(group.PVCNames) == 0 && group.Selector == nil {
+		allErrs = append(allErrs, field.Required(fldPath, "either pvcNames or selector must be provided"))
+	}
+	if group.Selector != nil {
+		if !allowSelector {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("selector"),
+				"the PVCs must be listed in pvcNames"))
+		} else if _, err := metav1.LabelSelectorAsSelector(group.Selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("selector"), group.Selector, err.Error()))
+		}
+	}
+	for _, method := range methods {
+		if method != "rclone" && method != "restic" {
+			allErrs = append(allErrs, field.Forbidden(fldPath,
+				"volume groups are only supported by the rclone and restic methods"))
+			break
+		}
+	}
+	return allErrs
+}
+
+// minLockAge is the minimum age at which repository locks may be considered
+// stale. Restic refreshes the locks of running processes every 5 minutes, so
+// a younger lock may still be in use by another mover sharing the repository.
+const minLockAge = 30 * time.Minute
+
+// validateLockAge ensures the age at which repository locks are considered
+// stale is at least minLockAge
+func validateLockAge(age *metav1.Duration, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if age != nil && age.Duration < minLockAge {
+		allErrs = append(allErrs, field.Invalid(fldPath, age.Duration.String(),
+			"must be at least "+minLockAge.String()))
+	}
+	return allErrs
+}
+
+// validatePatterns ensures the restic path patterns can be passed to the mover,
+// one per line
+func validatePatterns(patterns []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, p := range patterns {
+		if strings.TrimSpace(p) == "" || strings.ContainsAny(p, "\r\n") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), p,
+				"must be a non-empty, single-line pattern"))
+		}
+	}
+	return allErrs
+}
+
+// validateTags ensures the restic snapshot tags can be passed to the mover as
+// a comma-separated list
+func validateTags(tags []string, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, t := range tags {
+		if t == "" || strings.ContainsAny(t, ", \t\r\n") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i), t,
+				"must be non-empty and may not contain commas or whitespace"))
+		}
+	}
+	return allErrs
+}
+
+// validateSecretKeys ensures the patterns that select the repository Secret's
+// keys are well-formed
+func validateSecretKeys(keys *ResticSecretKeysSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if keys == nil {
+		return allErrs
+	}
+	validate := func(patterns []string, fldPath *field.Path) {
+		for i, p := range patterns {
+			if _, err := path.Match(p, ""); p == "" || err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i), p, "must be a valid key name pattern"))
+			}
+		}
+	}
+	validate(keys.Allow, fldPath.Child("allow"))
+	validate(keys.Deny, fldPath.Child("deny"))
+	return allErrs
+}
+
+// validateIdentity ensures the annotations for the mover's ServiceAccount are
+// valid
+func validateIdentity(identity *ResticIdentitySpec, fldPath *field.Path) field.ErrorList {
+	if identity == nil {
+		return field.ErrorList{}
+	}
+	return apivalidation.ValidateAnnotations(identity.ServiceAccountAnnotations,
+		fldPath.Child("serviceAccountAnnotations"))
+}
+
+// validateCustomCA ensures a CA bundle is taken from exactly one ConfigMap or
+// Secret
+func validateCustomCA(ca *CustomCASpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if ca == nil {
+		return allErrs
+	}
+	if ca.ConfigMapName != "" && ca.SecretName != "" {
+		allErrs = append(allErrs, field.Forbidden(fldPath, "only one of configMapName or secretName may be provided"))
+	} else if ca.ConfigMapName == "" && ca.SecretName == "" {
+		allErrs = append(allErrs, field.Required(fldPath, "either configMapName or secretName must be provided"))
+	}
+	return allErrs
+}
@@ -74,6 +74,11 @@ type ReplicationDestinationVolumeOptions struct {
 	//+kubebuilder:validation:MinItems=1
 	//+optional
 	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// volumeMode is the volumeMode of the destination volume that is created.
+	// Use Block to replicate raw block volumes. Defaults to Filesystem.
+	//+kubebuilder:validation:Enum=Filesystem;Block
+	//+optional
+	VolumeMode *corev1.PersistentVolumeMode `json:"volumeMode,omitempty"`
 	// volumeSnapshotClassName can be used to specify the VSC to be used if
 	// copyMethod is Snapshot. If not set, the default VSC is used.
 	//+optional
@@ -134,6 +139,32 @@ type ReplicationDestinationExternalSpec struct {
 	Parameters map[string]string `json:"parameters,omitempty"`
 }
 
+// ImageRetainPolicy defines which of the point-in-time images produced by a
+// ReplicationDestination are preserved. An image is kept if it is selected by
+// any of the rules. The most recent image is always kept.
+type ImageRetainPolicy struct {
+	// last defines the number of most recent images to be kept
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	Last *int32 `json:"last,omitempty"`
+	// hourly defines the number of images to be kept hourly
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Hourly *int32 `json:"hourly,omitempty"`
+	// daily defines the number of images to be kept daily
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Daily *int32 `json:"daily,omitempty"`
+	// weekly defines the number of images to be kept weekly
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Weekly *int32 `json:"weekly,omitempty"`
+	// monthly defines the number of images to be kept monthly
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Monthly *int32 `json:"monthly,omitempty"`
+}
+
 // ReplicationDestinationSpec defines the desired state of
 // ReplicationDestination
 type ReplicationDestinationSpec struct {
@@ -154,9 +185,44 @@ type ReplicationDestinationSpec struct {
 	// provider.
 	//+optional
 	External *ReplicationDestinationExternalSpec `json:"external,omitempty"`
+	// moverPodTemplate can be used to customize the Pods that are created to
+	// perform data movement.
+	//+optional
+	MoverPodTemplate *MoverPodTemplateSpec `json:"moverPodTemplate,omitempty"`
+	// volumeGroup lists the PVCs that receive the data of a ReplicationSource's
+	// sourceVolumeGroup. Each PVC is used if it exists, otherwise it is
+	// created based on the method's volume options.
+	//+optional
+	VolumeGroup *VolumeGroupSpec `json:"volumeGroup,omitempty"`
+	// imageRetain determines how many of the point-in-time images (snapshots)
+	// created by previous synchronizations are preserved. If not provided,
+	// only the latest image is kept.
+	//+optional
+	ImageRetain *ImageRetainPolicy `json:"imageRetain,omitempty"`
 	// paused can be used to temporarily stop replication. Defaults to "false".
 	//+optional
 	Paused bool `json:"paused,omitempty"`
+	// retryPolicy determines how the synchronization is retried when the data
+	// mover fails. By default, it is retried until it succeeds.
+	//+optional
+	RetryPolicy *RetryPolicySpec `json:"retryPolicy,omitempty"`
+	// deletionPolicy determines whether the replicated data is removed when
+	// this object is deleted. With Delete, the retained images are removed
+	// before the object is. Defaults to Retain.
+	//+optional
+	DeletionPolicy DeletionPolicyType `json:"deletionPolicy,omitempty"`
+}
+
+// ReplicationDestinationResticStatus defines the status of Restic-based
+// replication on the ReplicationDestination
+type ReplicationDestinationResticStatus struct {
+	// snapshots lists the most recent snapshots in the repository, newest
+	// first
+	//+optional
+	Snapshots []ResticSnapshot `json:"snapshots,omitempty"`
+	// repository summarizes the contents of the repository
+	//+optional
+	Repository *ResticRepositoryStats `json:"repository,omitempty"`
 }
 
 type ReplicationDestinationRsyncStatus struct {
@@ -181,6 +247,15 @@ type ReplicationDestinationResticSpec struct {
 	ReplicationDestinationVolumeOptions `json:",inline"`
 	// Repository is the secret name containing repository info
 	Repository string `json:"repository,omitempty"`
+	// secretKeys selects the keys of the repository Secret that are passed to
+	// restic as environment variables. All of the Secret's keys are also
+	// available as files in /credentials.
+	//+optional
+	SecretKeys *ResticSecretKeysSpec `json:"secretKeys,omitempty"`
+	// identity configures the mover to authenticate to the repository's
+	// storage with its ServiceAccount
+	//+optional
+	Identity *ResticIdentitySpec `json:"identity,omitempty"`
 	// cacheCapacity can be used to set the size of the restic metadata cache volume
 	//+optional
 	CacheCapacity *resource.Quantity `json:"cacheCapacity,omitempty"`
@@ -198,6 +273,47 @@ type ReplicationDestinationResticSpec struct {
 	// +kubebuilder:validation:Format="date-time"
 	//+optional
 	RestoreAsOf *string `json:"restoreAsOf,omitempty"`
+	// unlockStaleLocksAfter causes locks in the repository that are older
+	// than this duration to be removed before the mover runs. Such locks are
+	// typically left behind when a mover is interrupted. Locks are not removed
+	// if it is unset. It must be at least 30m: restic only refreshes the locks
+	// of running processes every 5 minutes, so a shorter age could remove a
+	// lock that is still held by another mover using the same repository.
+	//+kubebuilder:validation:Type=string
+	//+kubebuilder:validation:Format=duration
+	//+optional
+	UnlockStaleLocksAfter *metav1.Duration `json:"unlockStaleLocksAfter,omitempty"`
+	// listSnapshots is the number of the repository's most recent snapshots
+	// to list in the status. It defaults to 10, and 0 disables the list.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=20
+	//+optional
+	ListSnapshots *int32 `json:"listSnapshots,omitempty"`
+	// include limits the restore to the files and directories that match one
+	// of these patterns. Patterns use restic's --include syntax, and those that
+	// begin with "/" are relative to the root of the volume. Everything is
+	// restored if it is empty.
+	//+optional
+	Include []string `json:"include,omitempty"`
+	// hostname selects the snapshots to restore from. It must match the
+	// hostname used by the ReplicationSource that created them (normally
+	// "<namespace>/<name>" of the ReplicationSource). Defaults to "volsync",
+	// the hostname of backups made by prior versions.
+	//+optional
+	Hostname string `json:"hostname,omitempty"`
+	// tags, when set, limit the restore to the snapshots that have all of
+	// these tags
+	//+optional
+	Tags []string `json:"tags,omitempty"`
+}
+
+// RetainedImage is a point-in-time image that has been preserved by a
+// ReplicationDestination.
+type RetainedImage struct {
+	// image is the object holding the replicated data
+	Image corev1.TypedLocalObjectReference `json:"image"`
+	// creationTime is the time the image was created
+	CreationTime metav1.Time `json:"creationTime"`
 }
 
 // ReplicationDestinationStatus defines the observed state of ReplicationDestination
@@ -219,12 +335,31 @@ type ReplicationDestinationStatus struct {
 	// lastManualSync is set to the last spec.trigger.manual when the manual sync is done.
 	//+optional
 	LastManualSync string `json:"lastManualSync,omitempty"`
+	// lastSyncResult is the outcome of the most recent synchronization
+	// attempt.
+	//+optional
+	LastSyncResult *SyncResult `json:"lastSyncResult,omitempty"`
+	// moverVersion describes the version of the data mover that is used for
+	// this object. In most cases, this is the container image.
+	//+optional
+	MoverVersion string `json:"moverVersion,omitempty"`
 	// latestImage in the object holding the most recent consistent replicated
 	// image.
 	//+optional
 	LatestImage *corev1.TypedLocalObjectReference `json:"latestImage,omitempty"`
+	// images is the list of point-in-time images that are currently being
+	// retained, ordered from newest to oldest.
+	//+optional
+	Images []RetainedImage `json:"images,omitempty"`
+	// volumeGroup contains the images of each of the volumes of the
+	// volumeGroup from the most recent synchronization.
+	//+optional
+	VolumeGroup *VolumeGroupStatus `json:"volumeGroup,omitempty"`
 	// rsync contains status information for Rsync-based replication.
 	Rsync *ReplicationDestinationRsyncStatus `json:"rsync,omitempty"`
+	// restic contains status information for Restic-based replication.
+	//+optional
+	Restic *ReplicationDestinationResticStatus `json:"restic,omitempty"`
 	// external contains provider-specific status information. For more details,
 	// please see the documentation of the specific replication provider being
 	// used.
@@ -239,6 +374,7 @@ type ReplicationDestinationStatus struct {
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:scope=Namespaced
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 //+kubebuilder:printcolumn:name="Last sync",type="string",format="date-time",JSONPath=`.status.lastSyncTime`
 //+kubebuilder:printcolumn:name="Duration",type="string",JSONPath=`.status.lastSyncDuration`
 //+kubebuilder:printcolumn:name="Next sync",type="string",format="date-time",JSONPath=`.status.nextSyncTime`
@@ -0,0 +1,188 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This file may be used, at your option, according to either the GNU AGPL 3.0 or
+the Apache V2 license.
+
+---
+This program is free software: you can redistribute it and/or modify it under
+the terms of the GNU Affero General Public License as published by the Free
+Software Foundation, either version 3 of the License, or (at your option) any
+later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE.  See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along
+with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+---
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var replicationdestinationlog = logf.Log.WithName("replicationdestination-resource")
+
+func (r *ReplicationDestination) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//nolint:lll
+//+kubebuilder:webhook:path=/mutate-volsync-backube-v1alpha1-replicationdestination,mutating=true,failurePolicy=fail,sideEffects=None,groups=volsync.backube,resources=replicationdestinations,verbs=create;update,versions=v1alpha1,name=mreplicationdestination.volsync.backube,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ReplicationDestination{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ReplicationDestination) Default() {
+	replicationdestinationlog.V(1).Info("default", "name", r.Name, "namespace", r.Namespace)
+
+	defaultDeletionPolicy(&r.Spec.DeletionPolicy)
+	if r.Spec.Rsync != nil {
+		defaultCopyMethod(&r.Spec.Rsync.CopyMethod)
+	}
+	if r.Spec.Rclone != nil {
+		defaultCopyMethod(&r.Spec.Rclone.CopyMethod)
+	}
+	if r.Spec.Restic != nil {
+		defaultCopyMethod(&r.Spec.Restic.CopyMethod)
+	}
+}
+
+//nolint:lll
+//+kubebuilder:webhook:path=/validate-volsync-backube-v1alpha1-replicationdestination,mutating=false,failurePolicy=fail,sideEffects=None,groups=volsync.backube,resources=replicationdestinations,verbs=create;update,versions=v1alpha1,name=vreplicationdestination.volsync.backube,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ReplicationDestination{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ReplicationDestination) ValidateCreate() error {
+	replicationdestinationlog.V(1).Info("validate create", "name", r.Name, "namespace", r.Namespace)
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ReplicationDestination) ValidateUpdate(old runtime.Object) error {
+	replicationdestinationlog.V(1).Info("validate update", "name", r.Name, "namespace", r.Namespace)
+	// Objects that predate the webhook may not pass validation. They still
+	// need to be updatable while they are being removed.
+	if !r.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	return r.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ReplicationDestination) ValidateDelete() error {
+	return nil
+}
+
+func (r *ReplicationDestination) validate() error {
+	allErrs := r.validateSpec(field.NewPath("spec"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("ReplicationDestination").GroupKind(), r.Name, allErrs)
+}
+
+func (r *ReplicationDestination) validateSpec(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	methods := []string{}
+	if r.Spec.Rsync != nil {
+		methods = append(methods, "rsync")
+		allErrs = append(allErrs, r.Spec.Rsync.ReplicationDestinationVolumeOptions.validate(fldPath.Child("rsync"))...)
+	}
+	if r.Spec.Rclone != nil {
+		methods = append(methods, "rclone")
+		allErrs = append(allErrs, r.Spec.Rclone.ReplicationDestinationVolumeOptions.validate(fldPath.Child("rclone"))...)
+	}
+	if r.Spec.Restic != nil {
+		methods = append(methods, "restic")
+		allErrs = append(allErrs, r.Spec.Restic.ReplicationDestinationVolumeOptions.validate(fldPath.Child("restic"))...)
+		allErrs = append(allErrs, validateLockAge(r.Spec.Restic.UnlockStaleLocksAfter,
+			fldPath.Child("restic", "unlockStaleLocksAfter"))...)
+		allErrs = append(allErrs, validatePatterns(r.Spec.Restic.Include, fldPath.Child("restic", "include"))...)
+		allErrs = append(allErrs, validateTags(r.Spec.Restic.Tags, fldPath.Child("restic", "tags"))...)
+		allErrs = append(allErrs, validateSecretKeys(r.Spec.Restic.SecretKeys, fldPath.Child("restic", "secretKeys"))...)
+		allErrs = append(allErrs, validateIdentity(r.Spec.Restic.Identity, fldPath.Child("restic", "identity"))...)
+		if len(r.Spec.Restic.Include) > 0 && r.Spec.Restic.VolumeMode != nil &&
+			*r.Spec.Restic.VolumeMode == corev1.PersistentVolumeBlock {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("restic", "include"),
+				"a Block volume must be restored in its entirety"))
+		}
+	}
+	if r.Spec.External != nil {
+		methods = append(methods, "external")
+	}
+	allErrs = append(allErrs, validateMethodCount(methods, fldPath)...)
+
+	if r.Spec.VolumeGroup != nil {
+		allErrs = append(allErrs, validateVolumeGroup(r.Spec.VolumeGroup, false, methods,
+			fldPath.Child("volumeGroup"))...)
+		if (r.Spec.Rclone != nil && r.Spec.Rclone.DestinationPVC != nil) ||
+			(r.Spec.Restic != nil && r.Spec.Restic.DestinationPVC != nil) {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("volumeGroup"),
+				"destinationPVC may not be used with volumeGroup"))
+		}
+	}
+
+	if r.Spec.Trigger != nil {
+		allErrs = append(allErrs, validateSchedule(r.Spec.Trigger.Schedule, fldPath.Child("trigger", "schedule"))...)
+	}
+	if r.Spec.MoverPodTemplate != nil {
+		allErrs = append(allErrs, validateCustomCA(r.Spec.MoverPodTemplate.CustomCA,
+			fldPath.Child("moverPodTemplate", "customCA"))...)
+	}
+
+	return allErrs
+}
+
+// validate ensures the destination volume can be provisioned and that the
+// requested point-in-time image can be created
+func (o *ReplicationDestinationVolumeOptions) validate(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if o.DestinationPVC == nil {
+		if o.Capacity == nil {
+			allErrs = append(allErrs, field.Required(fldPath.Child("capacity"),
+				"capacity must be provided when destinationPVC is not"))
+		}
+		if len(o.AccessModes) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("accessModes"),
+				"accessModes must be provided when destinationPVC is not"))
+		}
+	}
+
+	switch o.CopyMethod {
+	case CopyMethodDirect, CopyMethodNone, CopyMethodSnapshot:
+	default:
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("copyMethod"), o.CopyMethod,
+			[]string{string(CopyMethodDirect), string(CopyMethodNone), string(CopyMethodSnapshot)}))
+	}
+
+	return allErrs
+}
@@ -156,8 +156,64 @@ type ReplicationSourceResticSpec struct {
 	ReplicationSourceVolumeOptions `json:",inline"`
 	// PruneIntervalDays define how often to prune the repository
 	PruneIntervalDays *int32 `json:"pruneIntervalDays,omitempty"`
+	// checkIntervalDays defines how often to verify the integrity of the
+	// repository with "restic check". The repository is not checked if it is
+	// unset.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	CheckIntervalDays *int32 `json:"checkIntervalDays,omitempty"`
+	// checkReadDataSubset additionally reads and verifies a subset of the
+	// repository's data during the check (e.g., "10%" or "1/5"). It is passed
+	// to restic's --read-data-subset option.
+	//+optional
+	CheckReadDataSubset string `json:"checkReadDataSubset,omitempty"`
+	// unlockStaleLocksAfter causes locks in the repository that are older
+	// than this duration to be removed before the mover runs. Such locks are
+	// typically left behind when a mover is interrupted. Locks are not removed
+	// if it is unset. It must be at least 30m: restic only refreshes the locks
+	// of running processes every 5 minutes, so a shorter age could remove a
+	// lock that is still held by another mover using the same repository.
+	//+kubebuilder:validation:Type=string
+	//+kubebuilder:validation:Format=duration
+	//+optional
+	UnlockStaleLocksAfter *metav1.Duration `json:"unlockStaleLocksAfter,omitempty"`
+	// listSnapshots is the number of the repository's most recent snapshots
+	// to list in the status. It defaults to 10, and 0 disables the list.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=20
+	//+optional
+	ListSnapshots *int32 `json:"listSnapshots,omitempty"`
+	// exclude is a list of patterns for files and directories that should not
+	// be backed up. Patterns use restic's --exclude syntax, and those that
+	// begin with "/" are relative to the root of the volume.
+	//+optional
+	Exclude []string `json:"exclude,omitempty"`
+	// excludeIfPresent excludes the directories that contain a file with one
+	// of these names (e.g., "CACHEDIR.TAG"), like restic's --exclude-if-present
+	//+optional
+	ExcludeIfPresent []string `json:"excludeIfPresent,omitempty"`
+	// hostname identifies this volume's snapshots in the repository. It is
+	// recorded in each backup, and only the snapshots with this hostname are
+	// removed by the retention policy. It is set to "<namespace>/<name>" when
+	// the ReplicationSource is created. If it is unset, as for objects created
+	// by prior versions, "volsync" is used.
+	//+optional
+	Hostname string `json:"hostname,omitempty"`
+	// tags are added to each backup. When set, only the snapshots that have
+	// all of these tags are removed by the retention policy.
+	//+optional
+	Tags []string `json:"tags,omitempty"`
 	// Repository is the secret name containing repository info
 	Repository string `json:"repository,omitempty"`
+	// secretKeys selects the keys of the repository Secret that are passed to
+	// restic as environment variables. All of the Secret's keys are also
+	// available as files in /credentials.
+	//+optional
+	SecretKeys *ResticSecretKeysSpec `json:"secretKeys,omitempty"`
+	// identity configures the mover to authenticate to the repository's
+	// storage with its ServiceAccount
+	//+optional
+	Identity *ResticIdentitySpec `json:"identity,omitempty"`
 	// ResticRetainPolicy define the retain policy
 	//+optional
 	Retain *ResticRetainPolicy `json:"retain,omitempty"`
@@ -178,6 +234,25 @@ type ReplicationSourceResticStatus struct {
 	// lastPruned in the object holding the time of last pruned
 	//+optional
 	LastPruned *metav1.Time `json:"lastPruned,omitempty"`
+	// lastChecked is the time of the last repository integrity check
+	//+optional
+	LastChecked *metav1.Time `json:"lastChecked,omitempty"`
+	// lastCheckResult is the outcome of the last repository integrity check,
+	// either Succeeded or Failed
+	//+kubebuilder:validation:Enum=Succeeded;Failed
+	//+optional
+	LastCheckResult string `json:"lastCheckResult,omitempty"`
+	// lastCheckMessage describes the errors found by the last repository
+	// integrity check
+	//+optional
+	LastCheckMessage string `json:"lastCheckMessage,omitempty"`
+	// snapshots lists the most recent snapshots in the repository, newest
+	// first
+	//+optional
+	Snapshots []ResticSnapshot `json:"snapshots,omitempty"`
+	// repository summarizes the contents of the repository
+	//+optional
+	Repository *ResticRepositoryStats `json:"repository,omitempty"`
 }
 
 // define the Syncthing field
@@ -188,10 +263,77 @@ type ReplicationSourceSyncthingSpec struct {
 	ServiceType *corev1.ServiceType `json:"serviceType,omitempty"`
 }
 
+// HookErrorPolicy determines what happens when a hook fails
+//+kubebuilder:validation:Enum=Fail;Continue
+type HookErrorPolicy string
+
+const (
+	// HookErrorPolicyFail causes the synchronization to be retried
+	HookErrorPolicyFail HookErrorPolicy = "Fail"
+	// HookErrorPolicyContinue ignores the error and proceeds with the
+	// synchronization
+	HookErrorPolicyContinue HookErrorPolicy = "Continue"
+)
+
+// DefaultHookTimeoutSeconds is the timeout used for hooks that don't specify
+// one
+const DefaultHookTimeoutSeconds int32 = 30
+
+// HookSpec defines a command that is executed inside a running Pod
+type HookSpec struct {
+	// podSelector selects the Pod(s) in the ReplicationSource's namespace in
+	// which the command is run. The command is run in each matching Pod that
+	// is Running.
+	PodSelector metav1.LabelSelector `json:"podSelector"`
+	// container is the name of the container in which the command is run.
+	// Defaults to the first container in the Pod.
+	//+optional
+	Container string `json:"container,omitempty"`
+	// command is the command (and arguments) to execute. It is not run in a
+	// shell.
+	//+kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+	// timeoutSeconds is the amount of time the command is permitted to run
+	// before it is considered to have failed. Defaults to 30.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// onError determines whether a failure of the command should cause the
+	// synchronization to be retried ("Fail") or be ignored ("Continue").
+	// Defaults to "Fail".
+	//+optional
+	OnError HookErrorPolicy `json:"onError,omitempty"`
+}
+
+// SyncHooksSpec defines the hooks that are run around the creation of the
+// point-in-time copy (Snapshot or Clone) of the source volume.
+type SyncHooksSpec struct {
+	// pre hooks are run, in order, immediately before the point-in-time copy
+	// is created. They can be used to quiesce an application.
+	//+optional
+	Pre []HookSpec `json:"pre,omitempty"`
+	// post hooks are run, in order, once the point-in-time copy has been
+	// taken, or if taking it fails. They are also run if one of the pre hooks
+	// fails. They can be used to resume an application.
+	//+optional
+	Post []HookSpec `json:"post,omitempty"`
+	// serviceAccountName is the ServiceAccount, in the ReplicationSource's
+	// namespace, whose permissions are used to run the hooks. It must be
+	// allowed to create "pods/exec" for each of the selected Pods. Defaults to
+	// "default".
+	//+optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
 // ReplicationSourceSpec defines the desired state of ReplicationSource
 type ReplicationSourceSpec struct {
 	// sourcePVC is the name of the PersistentVolumeClaim (PVC) to replicate.
 	SourcePVC string `json:"sourcePVC,omitempty"`
+	// sourceVolumeGroup selects a group of PVCs to replicate together, instead
+	// of a single sourcePVC. The point-in-time copies of all the volumes are
+	// created together before any data is moved.
+	//+optional
+	SourceVolumeGroup *VolumeGroupSpec `json:"sourceVolumeGroup,omitempty"`
 	// trigger determines when the latest state of the volume will be captured
 	// (and potentially replicated to the destination).
 	//+optional
@@ -212,9 +354,34 @@ type ReplicationSourceSpec struct {
 	// provider.
 	//+optional
 	External *ReplicationSourceExternalSpec `json:"external,omitempty"`
+	// moverPodTemplate can be used to customize the Pods that are created to
+	// perform data movement.
+	//+optional
+	MoverPodTemplate *MoverPodTemplateSpec `json:"moverPodTemplate,omitempty"`
+	// hooks are commands that are run in application Pods to make the
+	// point-in-time copy of the source volume application-consistent. They
+	// are only used with the Snapshot and Clone copyMethods.
+	//+optional
+	Hooks *SyncHooksSpec `json:"hooks,omitempty"`
 	// paused can be used to temporarily stop replication. Defaults to "false".
 	//+optional
 	Paused bool `json:"paused,omitempty"`
+	// syncTimeout is the maximum amount of time a synchronization may run. It
+	// is applied as the activeDeadlineSeconds of the mover's Job. A
+	// synchronization that exceeds it is stopped, and the next one begins at
+	// the following scheduled time. The default is no limit.
+	//+optional
+	SyncTimeout *metav1.Duration `json:"syncTimeout,omitempty"`
+	// retryPolicy determines how the synchronization is retried when the data
+	// mover fails. By default, it is retried until it succeeds.
+	//+optional
+	RetryPolicy *RetryPolicySpec `json:"retryPolicy,omitempty"`
+	// deletionPolicy determines whether the replicated data is removed when
+	// this object is deleted. With Delete, the mover removes the data it
+	// stored remotely (e.g., the object's backups in a restic repository or the
+	// rclone destination path). Defaults to Retain.
+	//+optional
+	DeletionPolicy DeletionPolicyType `json:"deletionPolicy,omitempty"`
 }
 
 type ReplicationSourceRsyncStatus struct {
@@ -262,6 +429,18 @@ type ReplicationSourceStatus struct {
 	// lastManualSync is set to the last spec.trigger.manual when the manual sync is done.
 	//+optional
 	LastManualSync string `json:"lastManualSync,omitempty"`
+	// lastSyncResult is the outcome of the most recent synchronization
+	// attempt.
+	//+optional
+	LastSyncResult *SyncResult `json:"lastSyncResult,omitempty"`
+	// moverVersion describes the version of the data mover that is used for
+	// this object. In most cases, this is the container image.
+	//+optional
+	MoverVersion string `json:"moverVersion,omitempty"`
+	// volumeGroup describes the most recent synchronization of the
+	// sourceVolumeGroup.
+	//+optional
+	VolumeGroup *VolumeGroupStatus `json:"volumeGroup,omitempty"`
 	// rsync contains status information for Rsync-based replication.
 	Rsync *ReplicationSourceRsyncStatus `json:"rsync,omitempty"`
 	// external contains provider-specific status information. For more details,
@@ -283,6 +462,7 @@ type ReplicationSourceStatus struct {
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:scope=Namespaced
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 //+kubebuilder:printcolumn:name="Source",type="string",JSONPath=`.spec.sourcePVC`
 //+kubebuilder:printcolumn:name="Last sync",type="string",format="date-time",JSONPath=`.status.lastSyncTime`
 //+kubebuilder:printcolumn:name="Duration",type="string",JSONPath=`.status.lastSyncDuration`
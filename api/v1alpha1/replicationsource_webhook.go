@@ -0,0 +1,249 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This file may be used, at your option, according to either the GNU AGPL 3.0 or
+the Apache V2 license.
+
+---
+This program is free software: you can redistribute it and/or modify it under
+the terms of the GNU Affero General Public License as published by the Free
+Software Foundation, either version 3 of the License, or (at your option) any
+later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE.  See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along
+with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+---
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var replicationsourcelog = logf.Log.WithName("replicationsource-resource")
+
+func (r *ReplicationSource) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//nolint:lll
+//+kubebuilder:webhook:path=/mutate-volsync-backube-v1alpha1-replicationsource,mutating=true,failurePolicy=fail,sideEffects=None,groups=volsync.backube,resources=replicationsources,verbs=create;update,versions=v1alpha1,name=mreplicationsource.volsync.backube,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ReplicationSource{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ReplicationSource) Default() {
+	replicationsourcelog.V(1).Info("default", "name", r.Name, "namespace", r.Namespace)
+
+	defaultDeletionPolicy(&r.Spec.DeletionPolicy)
+	if r.Spec.Rsync != nil {
+		defaultCopyMethod(&r.Spec.Rsync.CopyMethod)
+	}
+	if r.Spec.Rclone != nil {
+		defaultCopyMethod(&r.Spec.Rclone.CopyMethod)
+	}
+	if r.Spec.Restic != nil {
+		defaultCopyMethod(&r.Spec.Restic.CopyMethod)
+		// Only new objects get a hostname of their own. Existing ones keep
+		// the one their backups were made with.
+		if r.Spec.Restic.Hostname == "" && r.CreationTimestamp.IsZero() && r.Namespace != "" && r.Name != "" {
+			r.Spec.Restic.Hostname = r.Namespace + "/" + r.Name
+		}
+	}
+	if r.Spec.Hooks != nil {
+		for i := range r.Spec.Hooks.Pre {
+			defaultHook(&r.Spec.Hooks.Pre[i])
+		}
+		for i := range r.Spec.Hooks.Post {
+			defaultHook(&r.Spec.Hooks.Post[i])
+		}
+	}
+}
+
+// defaultHook fills in the timeout and error policy of a hook
+func defaultHook(hook *HookSpec) {
+	if hook.TimeoutSeconds == nil {
+		timeout := DefaultHookTimeoutSeconds
+		hook.TimeoutSeconds = &timeout
+	}
+	if hook.OnError == "" {
+		hook.OnError = HookErrorPolicyFail
+	}
+}
+
+//nolint:lll
+//+kubebuilder:webhook:path=/validate-volsync-backube-v1alpha1-replicationsource,mutating=false,failurePolicy=fail,sideEffects=None,groups=volsync.backube,resources=replicationsources,verbs=create;update,versions=v1alpha1,name=vreplicationsource.volsync.backube,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ReplicationSource{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ReplicationSource) ValidateCreate() error {
+	replicationsourcelog.V(1).Info("validate create", "name", r.Name, "namespace", r.Namespace)
+	return r.validate(nil)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ReplicationSource) ValidateUpdate(old runtime.Object) error {
+	replicationsourcelog.V(1).Info("validate update", "name", r.Name, "namespace", r.Namespace)
+	// Objects that predate the webhook may not pass validation. They still
+	// need to be updatable while they are being removed.
+	if !r.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	oldRS, _ := old.(*ReplicationSource)
+	return r.validate(oldRS)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ReplicationSource) ValidateDelete() error {
+	return nil
+}
+
+// validate checks the object. When it is being updated, old is its previous
+// version.
+func (r *ReplicationSource) validate(old *ReplicationSource) error {
+	allErrs := r.validateSpec(field.NewPath("spec"))
+	// Objects that were already set up this way are left alone, so they remain
+	// updatable (e.g., to add finalizers). Their backups are retained.
+	if r.deletesSharedResticBackups() && (old == nil || !old.deletesSharedResticBackups()) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "deletionPolicy"),
+			"the backups of the shared restic hostname \""+legacyResticHostname+
+				"\" can not be removed, set restic.hostname or restic.tags"))
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("ReplicationSource").GroupKind(), r.Name, allErrs)
+}
+
+// legacyResticHostname is the restic hostname of the objects that predate
+// per-object hostnames. It is shared by all of them.
+const legacyResticHostname = "volsync"
+
+// deletesSharedResticBackups returns true if the object would remove the
+// backups of the legacy hostname when deleted. They can't be told apart from
+// the backups of other objects that share the hostname without tags.
+func (r *ReplicationSource) deletesSharedResticBackups() bool {
+	if r.Spec.Restic == nil || r.Spec.DeletionPolicy != DeletionPolicyDelete || len(r.Spec.Restic.Tags) > 0 {
+		return false
+	}
+	return r.Spec.Restic.Hostname == "" || r.Spec.Restic.Hostname == legacyResticHostname
+}
+
+func (r *ReplicationSource) validateSpec(fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	methods := []string{}
+	if r.Spec.Rsync != nil {
+		methods = append(methods, "rsync")
+	}
+	if r.Spec.Rclone != nil {
+		methods = append(methods, "rclone")
+	}
+	if r.Spec.Restic != nil {
+		methods = append(methods, "restic")
+		allErrs = append(allErrs, validateLockAge(r.Spec.Restic.UnlockStaleLocksAfter,
+			fldPath.Child("restic", "unlockStaleLocksAfter"))...)
+		allErrs = append(allErrs, validatePatterns(r.Spec.Restic.Exclude, fldPath.Child("restic", "exclude"))...)
+		allErrs = append(allErrs, validatePatterns(r.Spec.Restic.ExcludeIfPresent,
+			fldPath.Child("restic", "excludeIfPresent"))...)
+		allErrs = append(allErrs, validateTags(r.Spec.Restic.Tags, fldPath.Child("restic", "tags"))...)
+		allErrs = append(allErrs, validateSecretKeys(r.Spec.Restic.SecretKeys, fldPath.Child("restic", "secretKeys"))...)
+		allErrs = append(allErrs, validateIdentity(r.Spec.Restic.Identity, fldPath.Child("restic", "identity"))...)
+	}
+	if r.Spec.Syncthing != nil {
+		methods = append(methods, "syncthing")
+	}
+	if r.Spec.External != nil {
+		methods = append(methods, "external")
+	}
+	allErrs = append(allErrs, validateMethodCount(methods, fldPath)...)
+
+	if r.Spec.SourceVolumeGroup != nil {
+		if r.Spec.SourcePVC != "" {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("sourcePVC"),
+				"sourcePVC may not be used with sourceVolumeGroup"))
+		}
+		allErrs = append(allErrs, validateVolumeGroup(r.Spec.SourceVolumeGroup, true, methods,
+			fldPath.Child("sourceVolumeGroup"))...)
+	}
+
+	if r.Spec.Trigger != nil {
+		allErrs = append(allErrs, validateSchedule(r.Spec.Trigger.Schedule, fldPath.Child("trigger", "schedule"))...)
+		if r.Spec.Syncthing != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("trigger"),
+				"syncthing replication does not support triggers"))
+		}
+	}
+	if r.Spec.SyncTimeout != nil {
+		if r.Spec.SyncTimeout.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("syncTimeout"), r.Spec.SyncTimeout.Duration.String(),
+				"must be greater than zero"))
+		}
+		if r.Spec.Syncthing != nil {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("syncTimeout"),
+				"syncthing replication does not support a sync timeout"))
+		}
+	}
+	if r.Spec.Hooks != nil {
+		for i := range r.Spec.Hooks.Pre {
+			allErrs = append(allErrs, validateHook(&r.Spec.Hooks.Pre[i], fldPath.Child("hooks", "pre").Index(i))...)
+		}
+		for i := range r.Spec.Hooks.Post {
+			allErrs = append(allErrs, validateHook(&r.Spec.Hooks.Post[i], fldPath.Child("hooks", "post").Index(i))...)
+		}
+		if sa := r.Spec.Hooks.ServiceAccountName; sa != "" {
+			for _, msg := range apivalidation.NameIsDNSSubdomain(sa, false) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("hooks", "serviceAccountName"), sa, msg))
+			}
+		}
+	}
+	if r.Spec.MoverPodTemplate != nil {
+		allErrs = append(allErrs, validateCustomCA(r.Spec.MoverPodTemplate.CustomCA,
+			fldPath.Child("moverPodTemplate", "customCA"))...)
+	}
+
+	return allErrs
+}
+
+// validateHook ensures a hook has a command and a usable podSelector
+func validateHook(hook *HookSpec, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if len(hook.Command) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("command"), "a command must be specified"))
+	}
+	if _, err := metav1.LabelSelectorAsSelector(&hook.PodSelector); err != nil {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("podSelector"), hook.PodSelector, err.Error()))
+	} else if len(hook.PodSelector.MatchLabels) == 0 && len(hook.PodSelector.MatchExpressions) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("podSelector"),
+			"the podSelector must not select all Pods"))
+	}
+	return allErrs
+}
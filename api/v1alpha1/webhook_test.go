@@ -0,0 +1,398 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This file may be used, at your option, according to either the GNU AGPL 3.0 or
+the Apache V2 license.
+
+---
+This program is free software: you can redistribute it and/or modify it under
+the terms of the GNU Affero General Public License as published by the Free
+Software Foundation, either version 3 of the License, or (at your option) any
+later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE.  See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along
+with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+---
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ReplicationSource webhook", func() {
+	var rs *ReplicationSource
+	BeforeEach(func() {
+		rs = &ReplicationSource{
+			ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "ns"},
+			Spec: ReplicationSourceSpec{
+				SourcePVC: "pvc",
+				Rsync:     &ReplicationSourceRsyncSpec{},
+			},
+		}
+	})
+
+	It("defaults the copyMethod", func() {
+		rs.Default()
+		Expect(rs.Spec.Rsync.CopyMethod).To(Equal(CopyMethodDirect))
+	})
+	It("replaces the deprecated None copyMethod", func() {
+		rs.Spec.Rsync.CopyMethod = CopyMethodNone
+		rs.Default()
+		Expect(rs.Spec.Rsync.CopyMethod).To(Equal(CopyMethodDirect))
+	})
+	It("leaves an explicit copyMethod alone", func() {
+		rs.Spec.Rsync.CopyMethod = CopyMethodSnapshot
+		rs.Default()
+		Expect(rs.Spec.Rsync.CopyMethod).To(Equal(CopyMethodSnapshot))
+	})
+	It("defaults the restic hostname of new objects only", func() {
+		rs.Spec.Rsync = nil
+		rs.Spec.Restic = &ReplicationSourceResticSpec{}
+		rs.Default()
+		Expect(rs.Spec.Restic.Hostname).To(Equal("ns/rs"))
+		rs.Spec.Restic.Hostname = ""
+		rs.CreationTimestamp = metav1.Now()
+		rs.Default()
+		Expect(rs.Spec.Restic.Hostname).To(BeEmpty())
+	})
+	It("defaults the deletionPolicy to Retain", func() {
+		rs.Default()
+		Expect(rs.Spec.DeletionPolicy).To(Equal(DeletionPolicyRetain))
+		rs.Spec.DeletionPolicy = DeletionPolicyDelete
+		rs.Default()
+		Expect(rs.Spec.DeletionPolicy).To(Equal(DeletionPolicyDelete))
+	})
+	It("refuses to delete the restic backups of the shared legacy hostname", func() {
+		rs.Spec.Rsync = nil
+		rs.Spec.Restic = &ReplicationSourceResticSpec{}
+		rs.Spec.DeletionPolicy = DeletionPolicyDelete
+		err := rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.deletionPolicy"))
+		rs.Spec.Restic.Tags = []string{"app=db"}
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.Restic.Tags = nil
+		rs.Spec.Restic.Hostname = "ns/rs"
+		Expect(rs.ValidateCreate()).To(Succeed())
+
+		// Existing objects that are already set up this way remain updatable
+		rs.Spec.Restic.Hostname = ""
+		old := rs.DeepCopy()
+		Expect(rs.ValidateUpdate(old)).To(Succeed())
+		old.Spec.DeletionPolicy = DeletionPolicyRetain
+		Expect(rs.ValidateUpdate(old)).NotTo(Succeed())
+	})
+
+	It("accepts a valid object", func() {
+		schedule := "*/5 * * * *"
+		rs.Spec.Trigger = &ReplicationSourceTriggerSpec{Schedule: &schedule}
+		Expect(rs.ValidateCreate()).To(Succeed())
+	})
+	It("requires a replication method", func() {
+		rs.Spec.Rsync = nil
+		Expect(rs.ValidateCreate()).NotTo(Succeed())
+	})
+	It("permits only a single replication method", func() {
+		rs.Spec.Restic = &ReplicationSourceResticSpec{}
+		err := rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("rsync, restic"))
+	})
+	It("rejects an invalid schedule", func() {
+		schedule := "61 * * * *"
+		rs.Spec.Trigger = &ReplicationSourceTriggerSpec{Schedule: &schedule}
+		err := rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.trigger.schedule"))
+	})
+	It("rejects triggers for syncthing", func() {
+		rs.Spec.Rsync = nil
+		rs.Spec.Syncthing = &ReplicationSourceSyncthingSpec{}
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.Trigger = &ReplicationSourceTriggerSpec{Manual: "now"}
+		Expect(rs.ValidateCreate()).NotTo(Succeed())
+	})
+	It("validates the syncTimeout", func() {
+		rs.Spec.SyncTimeout = &metav1.Duration{Duration: time.Hour}
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.SyncTimeout = &metav1.Duration{Duration: -time.Minute}
+		err := rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.syncTimeout"))
+		rs.Spec.SyncTimeout = &metav1.Duration{Duration: time.Hour}
+		rs.Spec.Rsync = nil
+		rs.Spec.Syncthing = &ReplicationSourceSyncthingSpec{}
+		Expect(rs.ValidateCreate()).NotTo(Succeed())
+	})
+	It("validates the age of stale restic locks", func() {
+		rs.Spec.Rsync = nil
+		rs.Spec.Restic = &ReplicationSourceResticSpec{
+			UnlockStaleLocksAfter: &metav1.Duration{Duration: 2 * time.Hour},
+		}
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.Restic.UnlockStaleLocksAfter.Duration = 30 * time.Minute
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.Restic.UnlockStaleLocksAfter.Duration = 0
+		err := rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.restic.unlockStaleLocksAfter"))
+		// Restic refreshes its locks every 5 minutes, so short ages are refused
+		rs.Spec.Restic.UnlockStaleLocksAfter.Duration = 10 * time.Minute
+		err = rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("must be at least 30m0s"))
+	})
+	It("validates the restic exclude patterns", func() {
+		rs.Spec.Rsync = nil
+		rs.Spec.Restic = &ReplicationSourceResticSpec{
+			Exclude:          []string{"/cache", "*.tmp"},
+			ExcludeIfPresent: []string{"CACHEDIR.TAG"},
+		}
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.Restic.Exclude = append(rs.Spec.Restic.Exclude, "/tmp\n/var")
+		err := rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.restic.exclude[2]"))
+		rs.Spec.Restic.Exclude = nil
+		rs.Spec.Restic.ExcludeIfPresent = []string{" "}
+		Expect(rs.ValidateCreate()).NotTo(Succeed())
+	})
+	It("validates the restic tags", func() {
+		rs.Spec.Rsync = nil
+		rs.Spec.Restic = &ReplicationSourceResticSpec{Tags: []string{"app=db", "tier-1"}}
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.Restic.Tags = []string{"a,b"}
+		err := rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.restic.tags[0]"))
+	})
+	It("validates the custom CA bundle", func() {
+		rs.Spec.MoverPodTemplate = &MoverPodTemplateSpec{CustomCA: &CustomCASpec{ConfigMapName: "ca"}}
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.MoverPodTemplate.CustomCA.SecretName = "ca"
+		err := rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.moverPodTemplate.customCA"))
+		rs.Spec.MoverPodTemplate.CustomCA = &CustomCASpec{Key: "ca.pem"}
+		Expect(rs.ValidateCreate()).NotTo(Succeed())
+	})
+	It("validates the restic credentials", func() {
+		rs.Spec.Rsync = nil
+		rs.Spec.Restic = &ReplicationSourceResticSpec{
+			SecretKeys: &ResticSecretKeysSpec{Allow: []string{"AWS_*"}, Deny: []string{"AWS_PROFILE"}},
+			Identity: &ResticIdentitySpec{
+				ServiceAccountAnnotations: map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::1:role/r"},
+			},
+		}
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.Restic.SecretKeys.Deny = []string{"AWS_["}
+		err := rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.restic.secretKeys.deny[0]"))
+		rs.Spec.Restic.SecretKeys = nil
+		rs.Spec.Restic.Identity.ServiceAccountAnnotations = map[string]string{"not a key": ""}
+		err = rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.restic.identity.serviceAccountAnnotations"))
+	})
+	It("defaults the timeout and error policy of hooks", func() {
+		rs.Spec.Hooks = &SyncHooksSpec{
+			Pre:  []HookSpec{{Command: []string{"sync"}}},
+			Post: []HookSpec{{Command: []string{"true"}, OnError: HookErrorPolicyContinue}},
+		}
+		rs.Default()
+		Expect(*rs.Spec.Hooks.Pre[0].TimeoutSeconds).To(Equal(DefaultHookTimeoutSeconds))
+		Expect(rs.Spec.Hooks.Pre[0].OnError).To(Equal(HookErrorPolicyFail))
+		Expect(rs.Spec.Hooks.Post[0].OnError).To(Equal(HookErrorPolicyContinue))
+	})
+	It("validates hooks", func() {
+		selector := metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}
+		rs.Spec.Hooks = &SyncHooksSpec{
+			Pre: []HookSpec{{PodSelector: selector, Command: []string{"sync"}}},
+		}
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.Hooks.Post = []HookSpec{{Command: []string{"true"}}}
+		err := rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.hooks.post[0].podSelector"))
+		rs.Spec.Hooks.Post = []HookSpec{{PodSelector: selector}}
+		err = rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.hooks.post[0].command"))
+		rs.Spec.Hooks.Post = nil
+		rs.Spec.Hooks.ServiceAccountName = "Not_Valid"
+		err = rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.hooks.serviceAccountName"))
+	})
+	It("validates the sourceVolumeGroup", func() {
+		rs.Spec.SourcePVC = ""
+		rs.Spec.Rsync = nil
+		rs.Spec.Restic = &ReplicationSourceResticSpec{}
+		rs.Spec.SourceVolumeGroup = &VolumeGroupSpec{PVCNames: []string{"data", "wal"}}
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.SourceVolumeGroup.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}
+		Expect(rs.ValidateCreate()).NotTo(Succeed())
+		rs.Spec.SourceVolumeGroup.PVCNames = nil
+		Expect(rs.ValidateCreate()).To(Succeed())
+		rs.Spec.SourcePVC = "pvc"
+		Expect(rs.ValidateCreate()).NotTo(Succeed())
+	})
+	It("rejects a sourceVolumeGroup for rsync", func() {
+		rs.Spec.SourcePVC = ""
+		rs.Spec.SourceVolumeGroup = &VolumeGroupSpec{PVCNames: []string{"data", "wal"}}
+		err := rs.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("only supported by the rclone and restic methods"))
+	})
+	It("permits updates to objects being deleted", func() {
+		rs.Spec.Rsync = nil
+		Expect(rs.ValidateUpdate(rs.DeepCopy())).NotTo(Succeed())
+		now := metav1.Now()
+		rs.DeletionTimestamp = &now
+		Expect(rs.ValidateUpdate(rs.DeepCopy())).To(Succeed())
+	})
+})
+
+var _ = Describe("ReplicationDestination webhook", func() {
+	var rd *ReplicationDestination
+	BeforeEach(func() {
+		capacity := resource.MustParse("1Gi")
+		rd = &ReplicationDestination{
+			ObjectMeta: metav1.ObjectMeta{Name: "rd", Namespace: "ns"},
+			Spec: ReplicationDestinationSpec{
+				Rsync: &ReplicationDestinationRsyncSpec{
+					ReplicationDestinationVolumeOptions: ReplicationDestinationVolumeOptions{
+						Capacity:    &capacity,
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					},
+				},
+			},
+		}
+		rd.Default()
+	})
+
+	It("defaults the copyMethod", func() {
+		Expect(rd.Spec.Rsync.CopyMethod).To(Equal(CopyMethodDirect))
+	})
+	It("defaults the deletionPolicy", func() {
+		Expect(rd.Spec.DeletionPolicy).To(Equal(DeletionPolicyRetain))
+	})
+	It("accepts a valid object", func() {
+		Expect(rd.ValidateCreate()).To(Succeed())
+	})
+	It("permits only a single replication method", func() {
+		rd.Spec.External = &ReplicationDestinationExternalSpec{}
+		Expect(rd.ValidateCreate()).NotTo(Succeed())
+	})
+	It("rejects an invalid schedule", func() {
+		schedule := "not a schedule"
+		rd.Spec.Trigger = &ReplicationDestinationTriggerSpec{Schedule: &schedule}
+		Expect(rd.ValidateCreate()).NotTo(Succeed())
+	})
+	It("rejects copyMethod Clone", func() {
+		rd.Spec.Rsync.CopyMethod = CopyMethodClone
+		err := rd.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.rsync.copyMethod"))
+	})
+	It("validates the volumeGroup", func() {
+		rd.Spec.Restic = &ReplicationDestinationResticSpec{
+			ReplicationDestinationVolumeOptions: rd.Spec.Rsync.ReplicationDestinationVolumeOptions,
+		}
+		rd.Spec.Rsync = nil
+		rd.Spec.VolumeGroup = &VolumeGroupSpec{PVCNames: []string{"data", "wal"}}
+		Expect(rd.ValidateCreate()).To(Succeed())
+		pvc := "mypvc"
+		rd.Spec.Restic.DestinationPVC = &pvc
+		Expect(rd.ValidateCreate()).NotTo(Succeed())
+		rd.Spec.Restic.DestinationPVC = nil
+		rd.Spec.VolumeGroup = &VolumeGroupSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		}
+		err := rd.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.volumeGroup.selector"))
+	})
+	It("validates the age of stale restic locks", func() {
+		rd.Spec.Restic = &ReplicationDestinationResticSpec{
+			ReplicationDestinationVolumeOptions: rd.Spec.Rsync.ReplicationDestinationVolumeOptions,
+			UnlockStaleLocksAfter:               &metav1.Duration{Duration: -time.Hour},
+		}
+		rd.Spec.Rsync = nil
+		err := rd.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.restic.unlockStaleLocksAfter"))
+	})
+	It("validates the restic include patterns", func() {
+		rd.Spec.Restic = &ReplicationDestinationResticSpec{
+			ReplicationDestinationVolumeOptions: rd.Spec.Rsync.ReplicationDestinationVolumeOptions,
+			Include:                             []string{"/reports"},
+		}
+		rd.Spec.Rsync = nil
+		Expect(rd.ValidateCreate()).To(Succeed())
+		rd.Spec.Restic.Include = []string{""}
+		Expect(rd.ValidateCreate()).NotTo(Succeed())
+		rd.Spec.Restic.Include = nil
+		rd.Spec.Restic.Tags = []string{"my tag"}
+		Expect(rd.ValidateCreate()).NotTo(Succeed())
+		rd.Spec.Restic.Tags = nil
+		rd.Spec.Restic.SecretKeys = &ResticSecretKeysSpec{Allow: []string{""}}
+		Expect(rd.ValidateCreate()).NotTo(Succeed())
+		rd.Spec.Restic.SecretKeys = nil
+		block := corev1.PersistentVolumeBlock
+		rd.Spec.Restic.Include = []string{"/reports"}
+		rd.Spec.Restic.VolumeMode = &block
+		err := rd.ValidateCreate()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.restic.include"))
+	})
+	When("destinationPVC is not provided", func() {
+		It("requires capacity", func() {
+			rd.Spec.Rsync.Capacity = nil
+			err := rd.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.rsync.capacity"))
+		})
+		It("requires accessModes", func() {
+			rd.Spec.Rsync.AccessModes = nil
+			err := rd.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.rsync.accessModes"))
+		})
+	})
+	When("destinationPVC is provided", func() {
+		It("doesn't require capacity or accessModes", func() {
+			pvc := "mypvc"
+			rd.Spec.Rsync.ReplicationDestinationVolumeOptions = ReplicationDestinationVolumeOptions{
+				CopyMethod:     CopyMethodSnapshot,
+				DestinationPVC: &pvc,
+			}
+			Expect(rd.ValidateCreate()).To(Succeed())
+		})
+	})
+})
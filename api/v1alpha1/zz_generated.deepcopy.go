@@ -39,11 +39,152 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomCASpec) DeepCopyInto(out *CustomCASpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomCASpec.
+func (in *CustomCASpec) DeepCopy() *CustomCASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomCASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookSpec) DeepCopyInto(out *HookSpec) {
+	*out = *in
+	in.PodSelector.DeepCopyInto(&out.PodSelector)
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookSpec.
+func (in *HookSpec) DeepCopy() *HookSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HookSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImageRetainPolicy) DeepCopyInto(out *ImageRetainPolicy) {
+	*out = *in
+	if in.Last != nil {
+		in, out := &in.Last, &out.Last
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Hourly != nil {
+		in, out := &in.Hourly, &out.Hourly
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Daily != nil {
+		in, out := &in.Daily, &out.Daily
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Weekly != nil {
+		in, out := &in.Weekly, &out.Weekly
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Monthly != nil {
+		in, out := &in.Monthly, &out.Monthly
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImageRetainPolicy.
+func (in *ImageRetainPolicy) DeepCopy() *ImageRetainPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageRetainPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MoverPodTemplateSpec) DeepCopyInto(out *MoverPodTemplateSpec) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CustomCA != nil {
+		in, out := &in.CustomCA, &out.CustomCA
+		*out = new(CustomCASpec)
+		**out = **in
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxySpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MoverPodTemplateSpec.
+func (in *MoverPodTemplateSpec) DeepCopy() *MoverPodTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MoverPodTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxySpec) DeepCopyInto(out *ProxySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxySpec.
+func (in *ProxySpec) DeepCopy() *ProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicationDestination) DeepCopyInto(out *ReplicationDestination) {
 	*out = *in
@@ -164,6 +305,16 @@ func (in *ReplicationDestinationRcloneSpec) DeepCopy() *ReplicationDestinationRc
 func (in *ReplicationDestinationResticSpec) DeepCopyInto(out *ReplicationDestinationResticSpec) {
 	*out = *in
 	in.ReplicationDestinationVolumeOptions.DeepCopyInto(&out.ReplicationDestinationVolumeOptions)
+	if in.SecretKeys != nil {
+		in, out := &in.SecretKeys, &out.SecretKeys
+		*out = new(ResticSecretKeysSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Identity != nil {
+		in, out := &in.Identity, &out.Identity
+		*out = new(ResticIdentitySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CacheCapacity != nil {
 		in, out := &in.CacheCapacity, &out.CacheCapacity
 		x := (*in).DeepCopy()
@@ -176,7 +327,7 @@ func (in *ReplicationDestinationResticSpec) DeepCopyInto(out *ReplicationDestina
 	}
 	if in.CacheAccessModes != nil {
 		in, out := &in.CacheAccessModes, &out.CacheAccessModes
-		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
 		copy(*out, *in)
 	}
 	if in.Previous != nil {
@@ -189,6 +340,26 @@ func (in *ReplicationDestinationResticSpec) DeepCopyInto(out *ReplicationDestina
 		*out = new(string)
 		**out = **in
 	}
+	if in.UnlockStaleLocksAfter != nil {
+		in, out := &in.UnlockStaleLocksAfter, &out.UnlockStaleLocksAfter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ListSnapshots != nil {
+		in, out := &in.ListSnapshots, &out.ListSnapshots
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationResticSpec.
@@ -201,6 +372,33 @@ func (in *ReplicationDestinationResticSpec) DeepCopy() *ReplicationDestinationRe
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationDestinationResticStatus) DeepCopyInto(out *ReplicationDestinationResticStatus) {
+	*out = *in
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]ResticSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Repository != nil {
+		in, out := &in.Repository, &out.Repository
+		*out = new(ResticRepositoryStats)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationResticStatus.
+func (in *ReplicationDestinationResticStatus) DeepCopy() *ReplicationDestinationResticStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationDestinationResticStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicationDestinationRsyncSpec) DeepCopyInto(out *ReplicationDestinationRsyncSpec) {
 	*out = *in
@@ -212,7 +410,7 @@ func (in *ReplicationDestinationRsyncSpec) DeepCopyInto(out *ReplicationDestinat
 	}
 	if in.ServiceType != nil {
 		in, out := &in.ServiceType, &out.ServiceType
-		*out = new(v1.ServiceType)
+		*out = new(corev1.ServiceType)
 		**out = **in
 	}
 	if in.Address != nil {
@@ -305,6 +503,26 @@ func (in *ReplicationDestinationSpec) DeepCopyInto(out *ReplicationDestinationSp
 		*out = new(ReplicationDestinationExternalSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MoverPodTemplate != nil {
+		in, out := &in.MoverPodTemplate, &out.MoverPodTemplate
+		*out = new(MoverPodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeGroup != nil {
+		in, out := &in.VolumeGroup, &out.VolumeGroup
+		*out = new(VolumeGroupSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageRetain != nil {
+		in, out := &in.ImageRetain, &out.ImageRetain
+		*out = new(ImageRetainPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationDestinationSpec.
@@ -330,16 +548,33 @@ func (in *ReplicationDestinationStatus) DeepCopyInto(out *ReplicationDestination
 	}
 	if in.LastSyncDuration != nil {
 		in, out := &in.LastSyncDuration, &out.LastSyncDuration
-		*out = new(metav1.Duration)
+		*out = new(v1.Duration)
 		**out = **in
 	}
 	if in.NextSyncTime != nil {
 		in, out := &in.NextSyncTime, &out.NextSyncTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastSyncResult != nil {
+		in, out := &in.LastSyncResult, &out.LastSyncResult
+		*out = new(SyncResult)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.LatestImage != nil {
 		in, out := &in.LatestImage, &out.LatestImage
-		*out = new(v1.TypedLocalObjectReference)
+		*out = new(corev1.TypedLocalObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]RetainedImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeGroup != nil {
+		in, out := &in.VolumeGroup, &out.VolumeGroup
+		*out = new(VolumeGroupStatus)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Rsync != nil {
@@ -347,6 +582,11 @@ func (in *ReplicationDestinationStatus) DeepCopyInto(out *ReplicationDestination
 		*out = new(ReplicationDestinationRsyncStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Restic != nil {
+		in, out := &in.Restic, &out.Restic
+		*out = new(ReplicationDestinationResticStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.External != nil {
 		in, out := &in.External, &out.External
 		*out = make(map[string]string, len(*in))
@@ -356,7 +596,7 @@ func (in *ReplicationDestinationStatus) DeepCopyInto(out *ReplicationDestination
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -408,9 +648,14 @@ func (in *ReplicationDestinationVolumeOptions) DeepCopyInto(out *ReplicationDest
 	}
 	if in.AccessModes != nil {
 		in, out := &in.AccessModes, &out.AccessModes
-		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
 		copy(*out, *in)
 	}
+	if in.VolumeMode != nil {
+		in, out := &in.VolumeMode, &out.VolumeMode
+		*out = new(corev1.PersistentVolumeMode)
+		**out = **in
+	}
 	if in.VolumeSnapshotClassName != nil {
 		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
 		*out = new(string)
@@ -558,6 +803,46 @@ func (in *ReplicationSourceResticSpec) DeepCopyInto(out *ReplicationSourceRestic
 		*out = new(int32)
 		**out = **in
 	}
+	if in.CheckIntervalDays != nil {
+		in, out := &in.CheckIntervalDays, &out.CheckIntervalDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.UnlockStaleLocksAfter != nil {
+		in, out := &in.UnlockStaleLocksAfter, &out.UnlockStaleLocksAfter
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.ListSnapshots != nil {
+		in, out := &in.ListSnapshots, &out.ListSnapshots
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeIfPresent != nil {
+		in, out := &in.ExcludeIfPresent, &out.ExcludeIfPresent
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecretKeys != nil {
+		in, out := &in.SecretKeys, &out.SecretKeys
+		*out = new(ResticSecretKeysSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Identity != nil {
+		in, out := &in.Identity, &out.Identity
+		*out = new(ResticIdentitySpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Retain != nil {
 		in, out := &in.Retain, &out.Retain
 		*out = new(ResticRetainPolicy)
@@ -575,7 +860,7 @@ func (in *ReplicationSourceResticSpec) DeepCopyInto(out *ReplicationSourceRestic
 	}
 	if in.CacheAccessModes != nil {
 		in, out := &in.CacheAccessModes, &out.CacheAccessModes
-		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
 		copy(*out, *in)
 	}
 }
@@ -597,6 +882,22 @@ func (in *ReplicationSourceResticStatus) DeepCopyInto(out *ReplicationSourceRest
 		in, out := &in.LastPruned, &out.LastPruned
 		*out = (*in).DeepCopy()
 	}
+	if in.LastChecked != nil {
+		in, out := &in.LastChecked, &out.LastChecked
+		*out = (*in).DeepCopy()
+	}
+	if in.Snapshots != nil {
+		in, out := &in.Snapshots, &out.Snapshots
+		*out = make([]ResticSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Repository != nil {
+		in, out := &in.Repository, &out.Repository
+		*out = new(ResticRepositoryStats)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceResticStatus.
@@ -620,7 +921,7 @@ func (in *ReplicationSourceRsyncSpec) DeepCopyInto(out *ReplicationSourceRsyncSp
 	}
 	if in.ServiceType != nil {
 		in, out := &in.ServiceType, &out.ServiceType
-		*out = new(v1.ServiceType)
+		*out = new(corev1.ServiceType)
 		**out = **in
 	}
 	if in.Address != nil {
@@ -688,6 +989,11 @@ func (in *ReplicationSourceRsyncStatus) DeepCopy() *ReplicationSourceRsyncStatus
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReplicationSourceSpec) DeepCopyInto(out *ReplicationSourceSpec) {
 	*out = *in
+	if in.SourceVolumeGroup != nil {
+		in, out := &in.SourceVolumeGroup, &out.SourceVolumeGroup
+		*out = new(VolumeGroupSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Trigger != nil {
 		in, out := &in.Trigger, &out.Trigger
 		*out = new(ReplicationSourceTriggerSpec)
@@ -718,6 +1024,26 @@ func (in *ReplicationSourceSpec) DeepCopyInto(out *ReplicationSourceSpec) {
 		*out = new(ReplicationSourceExternalSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MoverPodTemplate != nil {
+		in, out := &in.MoverPodTemplate, &out.MoverPodTemplate
+		*out = new(MoverPodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(SyncHooksSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SyncTimeout != nil {
+		in, out := &in.SyncTimeout, &out.SyncTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(RetryPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceSpec.
@@ -743,13 +1069,23 @@ func (in *ReplicationSourceStatus) DeepCopyInto(out *ReplicationSourceStatus) {
 	}
 	if in.LastSyncDuration != nil {
 		in, out := &in.LastSyncDuration, &out.LastSyncDuration
-		*out = new(metav1.Duration)
+		*out = new(v1.Duration)
 		**out = **in
 	}
 	if in.NextSyncTime != nil {
 		in, out := &in.NextSyncTime, &out.NextSyncTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastSyncResult != nil {
+		in, out := &in.LastSyncResult, &out.LastSyncResult
+		*out = new(SyncResult)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeGroup != nil {
+		in, out := &in.VolumeGroup, &out.VolumeGroup
+		*out = new(VolumeGroupStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Rsync != nil {
 		in, out := &in.Rsync, &out.Rsync
 		*out = new(ReplicationSourceRsyncStatus)
@@ -764,7 +1100,7 @@ func (in *ReplicationSourceStatus) DeepCopyInto(out *ReplicationSourceStatus) {
 	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -801,7 +1137,7 @@ func (in *ReplicationSourceSyncthingSpec) DeepCopyInto(out *ReplicationSourceSyn
 	}
 	if in.ServiceType != nil {
 		in, out := &in.ServiceType, &out.ServiceType
-		*out = new(v1.ServiceType)
+		*out = new(corev1.ServiceType)
 		**out = **in
 	}
 }
@@ -871,7 +1207,7 @@ func (in *ReplicationSourceVolumeOptions) DeepCopyInto(out *ReplicationSourceVol
 	}
 	if in.AccessModes != nil {
 		in, out := &in.AccessModes, &out.AccessModes
-		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		*out = make([]corev1.PersistentVolumeAccessMode, len(*in))
 		copy(*out, *in)
 	}
 	if in.VolumeSnapshotClassName != nil {
@@ -891,6 +1227,53 @@ func (in *ReplicationSourceVolumeOptions) DeepCopy() *ReplicationSourceVolumeOpt
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticIdentitySpec) DeepCopyInto(out *ResticIdentitySpec) {
+	*out = *in
+	if in.ServiceAccountAnnotations != nil {
+		in, out := &in.ServiceAccountAnnotations, &out.ServiceAccountAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TokenExpirationSeconds != nil {
+		in, out := &in.TokenExpirationSeconds, &out.TokenExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticIdentitySpec.
+func (in *ResticIdentitySpec) DeepCopy() *ResticIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticRepositoryStats) DeepCopyInto(out *ResticRepositoryStats) {
+	*out = *in
+	if in.TotalSize != nil {
+		in, out := &in.TotalSize, &out.TotalSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticRepositoryStats.
+func (in *ResticRepositoryStats) DeepCopy() *ResticRepositoryStats {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticRepositoryStats)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResticRetainPolicy) DeepCopyInto(out *ResticRetainPolicy) {
 	*out = *in
@@ -936,6 +1319,152 @@ func (in *ResticRetainPolicy) DeepCopy() *ResticRetainPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticSecretKeysSpec) DeepCopyInto(out *ResticSecretKeysSpec) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Deny != nil {
+		in, out := &in.Deny, &out.Deny
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticSecretKeysSpec.
+func (in *ResticSecretKeysSpec) DeepCopy() *ResticSecretKeysSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticSecretKeysSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResticSnapshot) DeepCopyInto(out *ResticSnapshot) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResticSnapshot.
+func (in *ResticSnapshot) DeepCopy() *ResticSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(ResticSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetainedImage) DeepCopyInto(out *RetainedImage) {
+	*out = *in
+	in.Image.DeepCopyInto(&out.Image)
+	in.CreationTime.DeepCopyInto(&out.CreationTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetainedImage.
+func (in *RetainedImage) DeepCopy() *RetainedImage {
+	if in == nil {
+		return nil
+	}
+	out := new(RetainedImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryPolicySpec) DeepCopyInto(out *RetryPolicySpec) {
+	*out = *in
+	if in.MaxAttempts != nil {
+		in, out := &in.MaxAttempts, &out.MaxAttempts
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BackoffDelay != nil {
+		in, out := &in.BackoffDelay, &out.BackoffDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryPolicySpec.
+func (in *RetryPolicySpec) DeepCopy() *RetryPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncHooksSpec) DeepCopyInto(out *SyncHooksSpec) {
+	*out = *in
+	if in.Pre != nil {
+		in, out := &in.Pre, &out.Pre
+		*out = make([]HookSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Post != nil {
+		in, out := &in.Post, &out.Post
+		*out = make([]HookSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncHooksSpec.
+func (in *SyncHooksSpec) DeepCopy() *SyncHooksSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncHooksSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncResult) DeepCopyInto(out *SyncResult) {
+	*out = *in
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncResult.
+func (in *SyncResult) DeepCopy() *SyncResult {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SyncthingPeer) DeepCopyInto(out *SyncthingPeer) {
 	*out = *in
@@ -965,3 +1494,74 @@ func (in *SyncthingPeerStatus) DeepCopy() *SyncthingPeerStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeGroupMember) DeepCopyInto(out *VolumeGroupMember) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(corev1.TypedLocalObjectReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeGroupMember.
+func (in *VolumeGroupMember) DeepCopy() *VolumeGroupMember {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeGroupMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeGroupSpec) DeepCopyInto(out *VolumeGroupSpec) {
+	*out = *in
+	if in.PVCNames != nil {
+		in, out := &in.PVCNames, &out.PVCNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeGroupSpec.
+func (in *VolumeGroupSpec) DeepCopy() *VolumeGroupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeGroupStatus) DeepCopyInto(out *VolumeGroupStatus) {
+	*out = *in
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = (*in).DeepCopy()
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]VolumeGroupMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeGroupStatus.
+func (in *VolumeGroupStatus) DeepCopy() *VolumeGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
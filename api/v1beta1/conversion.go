@@ -0,0 +1,251 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This file may be used, at your option, according to either the GNU AGPL 3.0 or
+the Apache V2 license.
+
+---
+This program is free software: you can redistribute it and/or modify it under
+the terms of the GNU Affero General Public License as published by the Free
+Software Foundation, either version 3 of the License, or (at your option) any
+later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE.  See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along
+with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+---
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/backube/volsync/api/v1alpha1"
+)
+
+// Helpers shared by the conversions to/from the v1alpha1 hub. In v1alpha1,
+// many optional values are pointers. In this version, the zero value means
+// "unset".
+
+func toStringPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func fromStringPtr(p *string) string {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+func toServiceTypePtr(t corev1.ServiceType) *corev1.ServiceType {
+	if t == "" {
+		return nil
+	}
+	return &t
+}
+
+func fromServiceTypePtr(p *corev1.ServiceType) corev1.ServiceType {
+	if p == nil {
+		return ""
+	}
+	return *p
+}
+
+func toCopyMethod(m CopyMethodType) v1alpha1.CopyMethodType {
+	return v1alpha1.CopyMethodType(m)
+}
+
+// fromCopyMethod converts the copyMethod, replacing the deprecated "None"
+// with its equivalent, "Direct"
+func fromCopyMethod(m v1alpha1.CopyMethodType) CopyMethodType {
+	if m == v1alpha1.CopyMethodNone {
+		return CopyMethodDirect
+	}
+	return CopyMethodType(m)
+}
+
+func (r *SyncResult) convertTo() *v1alpha1.SyncResult {
+	if r == nil {
+		return nil
+	}
+	return &v1alpha1.SyncResult{
+		Result:   v1alpha1.SyncResultType(r.Result),
+		Attempts: r.Attempts,
+		ExitCode: r.ExitCode,
+		Message:  r.Message,
+		Time:     r.Time,
+	}
+}
+
+func syncResultFrom(r *v1alpha1.SyncResult) *SyncResult {
+	if r == nil {
+		return nil
+	}
+	return &SyncResult{
+		Result:   SyncResultType(r.Result),
+		Attempts: r.Attempts,
+		ExitCode: r.ExitCode,
+		Message:  r.Message,
+		Time:     r.Time,
+	}
+}
+
+func (r *RetryPolicySpec) convertTo() *v1alpha1.RetryPolicySpec {
+	if r == nil {
+		return nil
+	}
+	return &v1alpha1.RetryPolicySpec{
+		MaxAttempts:  r.MaxAttempts,
+		BackoffDelay: r.BackoffDelay,
+		OnExhausted:  v1alpha1.RetryExhaustedActionType(r.OnExhausted),
+	}
+}
+
+func retryPolicyFrom(r *v1alpha1.RetryPolicySpec) *RetryPolicySpec {
+	if r == nil {
+		return nil
+	}
+	return &RetryPolicySpec{
+		MaxAttempts:  r.MaxAttempts,
+		BackoffDelay: r.BackoffDelay,
+		OnExhausted:  RetryExhaustedActionType(r.OnExhausted),
+	}
+}
+
+func (t *MoverPodTemplateSpec) convertTo() *v1alpha1.MoverPodTemplateSpec {
+	if t == nil {
+		return nil
+	}
+	return &v1alpha1.MoverPodTemplateSpec{
+		Resources:         t.Resources,
+		NodeSelector:      t.NodeSelector,
+		Tolerations:       t.Tolerations,
+		Affinity:          t.Affinity,
+		PriorityClassName: t.PriorityClassName,
+		Image:             t.Image,
+		CustomCA:          (*v1alpha1.CustomCASpec)(t.CustomCA),
+		Proxy:             (*v1alpha1.ProxySpec)(t.Proxy),
+	}
+}
+
+func moverPodTemplateFrom(t *v1alpha1.MoverPodTemplateSpec) *MoverPodTemplateSpec {
+	if t == nil {
+		return nil
+	}
+	return &MoverPodTemplateSpec{
+		Resources:         t.Resources,
+		NodeSelector:      t.NodeSelector,
+		Tolerations:       t.Tolerations,
+		Affinity:          t.Affinity,
+		PriorityClassName: t.PriorityClassName,
+		Image:             t.Image,
+		CustomCA:          (*CustomCASpec)(t.CustomCA),
+		Proxy:             (*ProxySpec)(t.Proxy),
+	}
+}
+
+func (h *SyncHooksSpec) convertTo() *v1alpha1.SyncHooksSpec {
+	if h == nil {
+		return nil
+	}
+	dst := &v1alpha1.SyncHooksSpec{ServiceAccountName: h.ServiceAccountName}
+	for _, hook := range h.Pre {
+		dst.Pre = append(dst.Pre, hook.convertTo())
+	}
+	for _, hook := range h.Post {
+		dst.Post = append(dst.Post, hook.convertTo())
+	}
+	return dst
+}
+
+func syncHooksFrom(h *v1alpha1.SyncHooksSpec) *SyncHooksSpec {
+	if h == nil {
+		return nil
+	}
+	dst := &SyncHooksSpec{ServiceAccountName: h.ServiceAccountName}
+	for i := range h.Pre {
+		dst.Pre = append(dst.Pre, hookFrom(&h.Pre[i]))
+	}
+	for i := range h.Post {
+		dst.Post = append(dst.Post, hookFrom(&h.Post[i]))
+	}
+	return dst
+}
+
+func (h *HookSpec) convertTo() v1alpha1.HookSpec {
+	return v1alpha1.HookSpec{
+		PodSelector:    h.PodSelector,
+		Container:      h.Container,
+		Command:        h.Command,
+		TimeoutSeconds: h.TimeoutSeconds,
+		OnError:        v1alpha1.HookErrorPolicy(h.OnError),
+	}
+}
+
+func hookFrom(h *v1alpha1.HookSpec) HookSpec {
+	return HookSpec{
+		PodSelector:    h.PodSelector,
+		Container:      h.Container,
+		Command:        h.Command,
+		TimeoutSeconds: h.TimeoutSeconds,
+		OnError:        HookErrorPolicy(h.OnError),
+	}
+}
+
+func (g *VolumeGroupStatus) convertTo() *v1alpha1.VolumeGroupStatus {
+	if g == nil {
+		return nil
+	}
+	dst := &v1alpha1.VolumeGroupStatus{Time: g.Time}
+	for _, m := range g.Members {
+		dst.Members = append(dst.Members, v1alpha1.VolumeGroupMember(m))
+	}
+	return dst
+}
+
+func volumeGroupStatusFrom(g *v1alpha1.VolumeGroupStatus) *VolumeGroupStatus {
+	if g == nil {
+		return nil
+	}
+	dst := &VolumeGroupStatus{Time: g.Time}
+	for _, m := range g.Members {
+		dst.Members = append(dst.Members, VolumeGroupMember(m))
+	}
+	return dst
+}
+
+func resticSnapshotsTo(snapshots []ResticSnapshot) []v1alpha1.ResticSnapshot {
+	var dst []v1alpha1.ResticSnapshot
+	for _, s := range snapshots {
+		dst = append(dst, v1alpha1.ResticSnapshot(s))
+	}
+	return dst
+}
+
+func resticSnapshotsFrom(snapshots []v1alpha1.ResticSnapshot) []ResticSnapshot {
+	var dst []ResticSnapshot
+	for _, s := range snapshots {
+		dst = append(dst, ResticSnapshot(s))
+	}
+	return dst
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This file may be used, at your option, according to either the GNU AGPL 3.0 or
+the Apache V2 license.
+
+---
+This program is free software: you can redistribute it and/or modify it under
+the terms of the GNU Affero General Public License as published by the Free
+Software Foundation, either version 3 of the License, or (at your option) any
+later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE.  See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along
+with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+---
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/backube/volsync/api/v1alpha1"
+)
+
+var _ = Describe("ReplicationSource conversion", func() {
+	var hub *v1alpha1.ReplicationSource
+	BeforeEach(func() {
+		schedule := "*/5 * * * *"
+		keys := "mykeys"
+		svcType := corev1.ServiceTypeLoadBalancer
+		port := int32(2222)
+		capacity := resource.MustParse("2Gi")
+		within := "5d"
+		now := metav1.NewTime(time.Now().Truncate(time.Second))
+		maxAttempts := int32(3)
+		checkDays := int32(7)
+		hub = &v1alpha1.ReplicationSource{
+			ObjectMeta: metav1.ObjectMeta{Name: "rs", Namespace: "ns"},
+			Spec: v1alpha1.ReplicationSourceSpec{
+				SourcePVC: "pvc",
+				Trigger:   &v1alpha1.ReplicationSourceTriggerSpec{Schedule: &schedule},
+				Rsync: &v1alpha1.ReplicationSourceRsyncSpec{
+					ReplicationSourceVolumeOptions: v1alpha1.ReplicationSourceVolumeOptions{
+						CopyMethod:  v1alpha1.CopyMethodSnapshot,
+						Capacity:    &capacity,
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					},
+					SSHKeys:     &keys,
+					ServiceType: &svcType,
+					Port:        &port,
+				},
+				Restic: &v1alpha1.ReplicationSourceResticSpec{
+					Repository:            "repo",
+					Retain:                &v1alpha1.ResticRetainPolicy{Within: &within},
+					CheckIntervalDays:     &checkDays,
+					CheckReadDataSubset:   "10%",
+					UnlockStaleLocksAfter: &metav1.Duration{Duration: 6 * time.Hour},
+					Exclude:               []string{"/cache"},
+					ExcludeIfPresent:      []string{"CACHEDIR.TAG"},
+					Hostname:              "volsync",
+					Tags:                  []string{"db"},
+					SecretKeys: &v1alpha1.ResticSecretKeysSpec{
+						Allow: []string{"AWS_*", "RESTIC_*"},
+						Deny:  []string{"RESTIC_COMPRESSION"},
+					},
+					Identity: &v1alpha1.ResticIdentitySpec{
+						ServiceAccountAnnotations: map[string]string{"eks.amazonaws.com/role-arn": "arn:aws:iam::1:role/r"},
+						TokenAudience:             "sts.amazonaws.com",
+					},
+				},
+				Syncthing: &v1alpha1.ReplicationSourceSyncthingSpec{
+					Peers: []v1alpha1.SyncthingPeer{{ID: "peer", Address: "tcp://1.2.3.4:22000"}},
+				},
+				Hooks: &v1alpha1.SyncHooksSpec{
+					Pre: []v1alpha1.HookSpec{{
+						PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+						Command:     []string{"fsfreeze", "-f", "/data"},
+						OnError:     v1alpha1.HookErrorPolicyContinue,
+					}},
+					ServiceAccountName: "hooks",
+				},
+				Paused:      true,
+				SyncTimeout: &metav1.Duration{Duration: 2 * time.Hour},
+				RetryPolicy: &v1alpha1.RetryPolicySpec{
+					MaxAttempts:  &maxAttempts,
+					BackoffDelay: &metav1.Duration{Duration: time.Minute},
+					OnExhausted:  v1alpha1.RetryExhaustedSkip,
+				},
+				DeletionPolicy: v1alpha1.DeletionPolicyDelete,
+			},
+			Status: &v1alpha1.ReplicationSourceStatus{
+				LastSyncTime:   &now,
+				LastManualSync: "manual",
+				MoverVersion:   "Restic container: quay.io/backube/volsync-mover-restic:latest",
+				Rsync:          &v1alpha1.ReplicationSourceRsyncStatus{SSHKeys: &keys, Port: &port},
+				Restic: &v1alpha1.ReplicationSourceResticStatus{
+					LastChecked:      &now,
+					LastCheckResult:  "Failed",
+					LastCheckMessage: "pack abc is damaged",
+					Snapshots: []v1alpha1.ResticSnapshot{{
+						ID:    "4ac1b9e2",
+						Time:  now,
+						Size:  &capacity,
+						Paths: []string{"/data"},
+					}},
+					Repository: &v1alpha1.ResticRepositoryStats{SnapshotCount: 12, TotalSize: &capacity},
+				},
+				Syncthing: &v1alpha1.ReplicationSourceSyncthingStatus{
+					DeviceID: "me",
+					Peers:    []v1alpha1.SyncthingPeerStatus{{ID: "peer", Connected: true}},
+				},
+				Conditions: []metav1.Condition{{Type: ConditionSynchronizing, Status: metav1.ConditionTrue}},
+			},
+		}
+	})
+
+	It("converts from the hub", func() {
+		rs := &ReplicationSource{}
+		Expect(rs.ConvertFrom(hub)).To(Succeed())
+		Expect(rs.Name).To(Equal("rs"))
+		Expect(rs.Spec.Trigger.Schedule).To(Equal("*/5 * * * *"))
+		Expect(rs.Spec.Rsync.SSHKeys).To(Equal("mykeys"))
+		Expect(rs.Spec.Rsync.ServiceType).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(rs.Spec.Rsync.Address).To(BeEmpty())
+		Expect(rs.Spec.Rsync.CopyMethod).To(Equal(CopyMethodSnapshot))
+		Expect(rs.Spec.Restic.Retain.Within).To(Equal("5d"))
+		Expect(rs.Spec.Syncthing.Peers).To(HaveLen(1))
+		Expect(rs.Spec.Hooks.Pre).To(HaveLen(1))
+		Expect(rs.Spec.Hooks.Pre[0].OnError).To(Equal(HookErrorPolicyContinue))
+		Expect(rs.Status.LastManualSync).To(Equal("manual"))
+		Expect(rs.Status.Syncthing.DeviceID).To(Equal("me"))
+		Expect(*rs.Spec.Restic.CheckIntervalDays).To(Equal(int32(7)))
+		Expect(rs.Status.Restic.LastCheckResult).To(Equal("Failed"))
+		Expect(rs.Status.Restic.Snapshots).To(HaveLen(1))
+		Expect(rs.Status.Restic.Repository.SnapshotCount).To(Equal(int32(12)))
+	})
+
+	It("round-trips through this version", func() {
+		rs := &ReplicationSource{}
+		Expect(rs.ConvertFrom(hub)).To(Succeed())
+		result := &v1alpha1.ReplicationSource{}
+		Expect(rs.ConvertTo(result)).To(Succeed())
+		Expect(result).To(Equal(hub))
+	})
+
+	It("converts the deprecated None copyMethod to Direct", func() {
+		hub.Spec.Rsync.CopyMethod = v1alpha1.CopyMethodNone
+		rs := &ReplicationSource{}
+		Expect(rs.ConvertFrom(hub)).To(Succeed())
+		Expect(rs.Spec.Rsync.CopyMethod).To(Equal(CopyMethodDirect))
+	})
+
+	It("handles a missing status", func() {
+		hub.Status = nil
+		rs := &ReplicationSource{}
+		Expect(rs.ConvertFrom(hub)).To(Succeed())
+		result := &v1alpha1.ReplicationSource{}
+		Expect(rs.ConvertTo(result)).To(Succeed())
+		Expect(result.Status).To(BeNil())
+	})
+})
+
+var _ = Describe("ReplicationDestination conversion", func() {
+	var hub *v1alpha1.ReplicationDestination
+	BeforeEach(func() {
+		pvc := "dest"
+		section := "section"
+		asOf := "2022-01-02T03:04:05Z"
+		previous := int32(2)
+		last := int32(3)
+		block := corev1.PersistentVolumeBlock
+		hub = &v1alpha1.ReplicationDestination{
+			ObjectMeta: metav1.ObjectMeta{Name: "rd", Namespace: "ns"},
+			Spec: v1alpha1.ReplicationDestinationSpec{
+				Trigger: &v1alpha1.ReplicationDestinationTriggerSpec{Manual: "once"},
+				Rclone: &v1alpha1.ReplicationDestinationRcloneSpec{
+					ReplicationDestinationVolumeOptions: v1alpha1.ReplicationDestinationVolumeOptions{
+						CopyMethod:     v1alpha1.CopyMethodDirect,
+						DestinationPVC: &pvc,
+					},
+					RcloneConfigSection: &section,
+				},
+				Restic: &v1alpha1.ReplicationDestinationResticSpec{
+					ReplicationDestinationVolumeOptions: v1alpha1.ReplicationDestinationVolumeOptions{
+						VolumeMode: &block,
+					},
+					Repository:            "repo",
+					Previous:              &previous,
+					RestoreAsOf:           &asOf,
+					UnlockStaleLocksAfter: &metav1.Duration{Duration: time.Hour},
+					Include:               []string{"/reports"},
+					Hostname:              "ns/rs",
+					Identity:              &v1alpha1.ResticIdentitySpec{TokenAudience: "sts.amazonaws.com"},
+				},
+				ImageRetain: &v1alpha1.ImageRetainPolicy{Last: &last},
+				VolumeGroup: &v1alpha1.VolumeGroupSpec{PVCNames: []string{"data", "wal"}},
+				MoverPodTemplate: &v1alpha1.MoverPodTemplateSpec{
+					Image:    "mirror.example.com/volsync-mover-restic:canary",
+					CustomCA: &v1alpha1.CustomCASpec{ConfigMapName: "minio-ca"},
+					Proxy:    &v1alpha1.ProxySpec{HTTPSProxy: "http://proxy:3128", NoProxy: ".svc"},
+				},
+				RetryPolicy: &v1alpha1.RetryPolicySpec{OnExhausted: v1alpha1.RetryExhaustedPause},
+			},
+			Status: &v1alpha1.ReplicationDestinationStatus{
+				LatestImage:  &corev1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: "dest"},
+				MoverVersion: "Restic container: mirror.example.com/volsync-mover-restic:canary",
+				Restic: &v1alpha1.ReplicationDestinationResticStatus{
+					Snapshots: []v1alpha1.ResticSnapshot{{
+						ID:   "4ac1b9e2",
+						Time: metav1.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+					}},
+				},
+				Images: []v1alpha1.RetainedImage{{
+					Image:        corev1.TypedLocalObjectReference{Kind: "VolumeSnapshot", Name: "snap"},
+					CreationTime: metav1.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+				}},
+				VolumeGroup: &v1alpha1.VolumeGroupStatus{
+					Members: []v1alpha1.VolumeGroupMember{
+						{PVCName: "data", Image: &corev1.TypedLocalObjectReference{Kind: "VolumeSnapshot", Name: "data-snap"}},
+						{PVCName: "wal", Image: &corev1.TypedLocalObjectReference{Kind: "VolumeSnapshot", Name: "wal-snap"}},
+					},
+				},
+			},
+		}
+	})
+
+	It("converts from the hub", func() {
+		rd := &ReplicationDestination{}
+		Expect(rd.ConvertFrom(hub)).To(Succeed())
+		Expect(rd.Spec.Trigger.Schedule).To(BeEmpty())
+		Expect(rd.Spec.Rclone.DestinationPVC).To(Equal("dest"))
+		Expect(rd.Spec.Rclone.RcloneConfigSection).To(Equal("section"))
+		Expect(rd.Spec.Restic.RestoreAsOf.Time).To(BeTemporally("==",
+			time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)))
+		Expect(rd.Status.LatestImage.Name).To(Equal("dest"))
+		Expect(*rd.Spec.ImageRetain.Last).To(Equal(int32(3)))
+		Expect(rd.Status.Images).To(HaveLen(1))
+		Expect(rd.Status.Images[0].Image.Name).To(Equal("snap"))
+		Expect(rd.Spec.VolumeGroup.PVCNames).To(Equal([]string{"data", "wal"}))
+		Expect(rd.Status.VolumeGroup.Members).To(HaveLen(2))
+		Expect(rd.Spec.MoverPodTemplate.Image).To(Equal("mirror.example.com/volsync-mover-restic:canary"))
+		Expect(rd.Spec.MoverPodTemplate.CustomCA.ConfigMapName).To(Equal("minio-ca"))
+		Expect(rd.Status.Restic.Snapshots[0].ID).To(Equal("4ac1b9e2"))
+	})
+
+	It("round-trips through this version", func() {
+		rd := &ReplicationDestination{}
+		Expect(rd.ConvertFrom(hub)).To(Succeed())
+		result := &v1alpha1.ReplicationDestination{}
+		Expect(rd.ConvertTo(result)).To(Succeed())
+		Expect(result).To(Equal(hub))
+	})
+
+	It("rejects an invalid restoreAsOf", func() {
+		bad := "yesterday"
+		hub.Spec.Restic.RestoreAsOf = &bad
+		rd := &ReplicationDestination{}
+		Expect(rd.ConvertFrom(hub)).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,303 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This file may be used, at your option, according to either the GNU AGPL 3.0 or
+the Apache V2 license.
+
+---
+This program is free software: you can redistribute it and/or modify it under
+the terms of the GNU Affero General Public License as published by the Free
+Software Foundation, either version 3 of the License, or (at your option) any
+later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE.  See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along
+with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+---
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/backube/volsync/api/v1alpha1"
+)
+
+var _ conversion.Convertible = &ReplicationDestination{}
+
+// ConvertTo converts this ReplicationDestination to the Hub version (v1alpha1)
+func (src *ReplicationDestination) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.ReplicationDestination)
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.Trigger = nil
+	if src.Spec.Trigger != nil {
+		dst.Spec.Trigger = &v1alpha1.ReplicationDestinationTriggerSpec{
+			Schedule: toStringPtr(src.Spec.Trigger.Schedule),
+			Manual:   src.Spec.Trigger.Manual,
+		}
+	}
+	dst.Spec.Rsync = nil
+	if r := src.Spec.Rsync; r != nil {
+		dst.Spec.Rsync = &v1alpha1.ReplicationDestinationRsyncSpec{
+			ReplicationDestinationVolumeOptions: r.ReplicationDestinationVolumeOptions.convertTo(),
+			SSHKeys:                             toStringPtr(r.SSHKeys),
+			ServiceType:                         toServiceTypePtr(r.ServiceType),
+			Address:                             toStringPtr(r.Address),
+			Port:                                r.Port,
+			Path:                                toStringPtr(r.Path),
+			SSHUser:                             toStringPtr(r.SSHUser),
+		}
+	}
+	dst.Spec.Rclone = nil
+	if r := src.Spec.Rclone; r != nil {
+		dst.Spec.Rclone = &v1alpha1.ReplicationDestinationRcloneSpec{
+			ReplicationDestinationVolumeOptions: r.ReplicationDestinationVolumeOptions.convertTo(),
+			RcloneConfigSection:                 toStringPtr(r.RcloneConfigSection),
+			RcloneDestPath:                      toStringPtr(r.RcloneDestPath),
+			RcloneConfig:                        toStringPtr(r.RcloneConfig),
+		}
+	}
+	dst.Spec.Restic = nil
+	if r := src.Spec.Restic; r != nil {
+		dst.Spec.Restic = &v1alpha1.ReplicationDestinationResticSpec{
+			ReplicationDestinationVolumeOptions: r.ReplicationDestinationVolumeOptions.convertTo(),
+			Repository:                          r.Repository,
+			SecretKeys:                          (*v1alpha1.ResticSecretKeysSpec)(r.SecretKeys),
+			Identity:                            (*v1alpha1.ResticIdentitySpec)(r.Identity),
+			CacheCapacity:                       r.CacheCapacity,
+			CacheStorageClassName:               r.CacheStorageClassName,
+			CacheAccessModes:                    r.CacheAccessModes,
+			Previous:                            r.Previous,
+			UnlockStaleLocksAfter:               r.UnlockStaleLocksAfter,
+			ListSnapshots:                       r.ListSnapshots,
+			Include:                             r.Include,
+			Hostname:                            r.Hostname,
+			Tags:                                r.Tags,
+		}
+		if r.RestoreAsOf != nil {
+			asOf := r.RestoreAsOf.UTC().Format(time.RFC3339)
+			dst.Spec.Restic.RestoreAsOf = &asOf
+		}
+	}
+	dst.Spec.External = nil
+	if e := src.Spec.External; e != nil {
+		dst.Spec.External = &v1alpha1.ReplicationDestinationExternalSpec{
+			Provider:   e.Provider,
+			Parameters: e.Parameters,
+		}
+	}
+	dst.Spec.MoverPodTemplate = src.Spec.MoverPodTemplate.convertTo()
+	dst.Spec.VolumeGroup = (*v1alpha1.VolumeGroupSpec)(src.Spec.VolumeGroup)
+	dst.Spec.ImageRetain = nil
+	if r := src.Spec.ImageRetain; r != nil {
+		ir := v1alpha1.ImageRetainPolicy(*r)
+		dst.Spec.ImageRetain = &ir
+	}
+	dst.Spec.Paused = src.Spec.Paused
+	dst.Spec.RetryPolicy = src.Spec.RetryPolicy.convertTo()
+	dst.Spec.DeletionPolicy = v1alpha1.DeletionPolicyType(src.Spec.DeletionPolicy)
+
+	// Status
+	dst.Status = nil
+	if !equality.Semantic.DeepEqual(src.Status, ReplicationDestinationStatus{}) {
+		dst.Status = src.Status.convertTo()
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha1) to this version
+func (dst *ReplicationDestination) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.ReplicationDestination)
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.Trigger = nil
+	if src.Spec.Trigger != nil {
+		dst.Spec.Trigger = &ReplicationDestinationTriggerSpec{
+			Schedule: fromStringPtr(src.Spec.Trigger.Schedule),
+			Manual:   src.Spec.Trigger.Manual,
+		}
+	}
+	dst.Spec.Rsync = nil
+	if r := src.Spec.Rsync; r != nil {
+		dst.Spec.Rsync = &ReplicationDestinationRsyncSpec{
+			ReplicationDestinationVolumeOptions: destinationVolumeOptionsFrom(&r.ReplicationDestinationVolumeOptions),
+			SSHKeys:                             fromStringPtr(r.SSHKeys),
+			ServiceType:                         fromServiceTypePtr(r.ServiceType),
+			Address:                             fromStringPtr(r.Address),
+			Port:                                r.Port,
+			Path:                                fromStringPtr(r.Path),
+			SSHUser:                             fromStringPtr(r.SSHUser),
+		}
+	}
+	dst.Spec.Rclone = nil
+	if r := src.Spec.Rclone; r != nil {
+		dst.Spec.Rclone = &ReplicationDestinationRcloneSpec{
+			ReplicationDestinationVolumeOptions: destinationVolumeOptionsFrom(&r.ReplicationDestinationVolumeOptions),
+			RcloneConfigSection:                 fromStringPtr(r.RcloneConfigSection),
+			RcloneDestPath:                      fromStringPtr(r.RcloneDestPath),
+			RcloneConfig:                        fromStringPtr(r.RcloneConfig),
+		}
+	}
+	dst.Spec.Restic = nil
+	if r := src.Spec.Restic; r != nil {
+		dst.Spec.Restic = &ReplicationDestinationResticSpec{
+			ReplicationDestinationVolumeOptions: destinationVolumeOptionsFrom(&r.ReplicationDestinationVolumeOptions),
+			Repository:                          r.Repository,
+			SecretKeys:                          (*ResticSecretKeysSpec)(r.SecretKeys),
+			Identity:                            (*ResticIdentitySpec)(r.Identity),
+			CacheCapacity:                       r.CacheCapacity,
+			CacheStorageClassName:               r.CacheStorageClassName,
+			CacheAccessModes:                    r.CacheAccessModes,
+			Previous:                            r.Previous,
+			UnlockStaleLocksAfter:               r.UnlockStaleLocksAfter,
+			ListSnapshots:                       r.ListSnapshots,
+			Include:                             r.Include,
+			Hostname:                            r.Hostname,
+			Tags:                                r.Tags,
+		}
+		if r.RestoreAsOf != nil {
+			asOf, err := time.Parse(time.RFC3339, *r.RestoreAsOf)
+			if err != nil {
+				return err
+			}
+			dst.Spec.Restic.RestoreAsOf = &metav1.Time{Time: asOf}
+		}
+	}
+	dst.Spec.External = nil
+	if e := src.Spec.External; e != nil {
+		dst.Spec.External = &ReplicationDestinationExternalSpec{
+			Provider:   e.Provider,
+			Parameters: e.Parameters,
+		}
+	}
+	dst.Spec.MoverPodTemplate = moverPodTemplateFrom(src.Spec.MoverPodTemplate)
+	dst.Spec.VolumeGroup = (*VolumeGroupSpec)(src.Spec.VolumeGroup)
+	dst.Spec.ImageRetain = nil
+	if r := src.Spec.ImageRetain; r != nil {
+		ir := ImageRetainPolicy(*r)
+		dst.Spec.ImageRetain = &ir
+	}
+	dst.Spec.Paused = src.Spec.Paused
+	dst.Spec.RetryPolicy = retryPolicyFrom(src.Spec.RetryPolicy)
+	dst.Spec.DeletionPolicy = DeletionPolicyType(src.Spec.DeletionPolicy)
+
+	// Status
+	dst.Status = ReplicationDestinationStatus{}
+	if src.Status != nil {
+		dst.Status = destinationStatusFrom(src.Status)
+	}
+	return nil
+}
+
+func (o *ReplicationDestinationVolumeOptions) convertTo() v1alpha1.ReplicationDestinationVolumeOptions {
+	return v1alpha1.ReplicationDestinationVolumeOptions{
+		CopyMethod:              toCopyMethod(o.CopyMethod),
+		Capacity:                o.Capacity,
+		StorageClassName:        o.StorageClassName,
+		AccessModes:             o.AccessModes,
+		VolumeMode:              o.VolumeMode,
+		VolumeSnapshotClassName: o.VolumeSnapshotClassName,
+		DestinationPVC:          toStringPtr(o.DestinationPVC),
+	}
+}
+
+func destinationVolumeOptionsFrom(o *v1alpha1.ReplicationDestinationVolumeOptions) ReplicationDestinationVolumeOptions {
+	return ReplicationDestinationVolumeOptions{
+		CopyMethod:              fromCopyMethod(o.CopyMethod),
+		Capacity:                o.Capacity,
+		StorageClassName:        o.StorageClassName,
+		AccessModes:             o.AccessModes,
+		VolumeMode:              o.VolumeMode,
+		VolumeSnapshotClassName: o.VolumeSnapshotClassName,
+		DestinationPVC:          fromStringPtr(o.DestinationPVC),
+	}
+}
+
+func (s *ReplicationDestinationStatus) convertTo() *v1alpha1.ReplicationDestinationStatus {
+	dst := &v1alpha1.ReplicationDestinationStatus{
+		LastSyncTime:      s.LastSyncTime,
+		LastSyncStartTime: s.LastSyncStartTime,
+		LastSyncDuration:  s.LastSyncDuration,
+		NextSyncTime:      s.NextSyncTime,
+		LastManualSync:    s.LastManualSync,
+		LastSyncResult:    s.LastSyncResult.convertTo(),
+		MoverVersion:      s.MoverVersion,
+		VolumeGroup:       s.VolumeGroup.convertTo(),
+		LatestImage:       s.LatestImage,
+		External:          s.External,
+		Conditions:        s.Conditions,
+	}
+	for _, i := range s.Images {
+		dst.Images = append(dst.Images, v1alpha1.RetainedImage(i))
+	}
+	if r := s.Rsync; r != nil {
+		dst.Rsync = &v1alpha1.ReplicationDestinationRsyncStatus{
+			SSHKeys: toStringPtr(r.SSHKeys),
+			Address: toStringPtr(r.Address),
+			Port:    r.Port,
+		}
+	}
+	if r := s.Restic; r != nil {
+		dst.Restic = &v1alpha1.ReplicationDestinationResticStatus{
+			Snapshots:  resticSnapshotsTo(r.Snapshots),
+			Repository: (*v1alpha1.ResticRepositoryStats)(r.Repository),
+		}
+	}
+	return dst
+}
+
+func destinationStatusFrom(s *v1alpha1.ReplicationDestinationStatus) ReplicationDestinationStatus {
+	dst := ReplicationDestinationStatus{
+		LastSyncTime:      s.LastSyncTime,
+		LastSyncStartTime: s.LastSyncStartTime,
+		LastSyncDuration:  s.LastSyncDuration,
+		NextSyncTime:      s.NextSyncTime,
+		LastManualSync:    s.LastManualSync,
+		LastSyncResult:    syncResultFrom(s.LastSyncResult),
+		MoverVersion:      s.MoverVersion,
+		VolumeGroup:       volumeGroupStatusFrom(s.VolumeGroup),
+		LatestImage:       s.LatestImage,
+		External:          s.External,
+		Conditions:        s.Conditions,
+	}
+	for _, i := range s.Images {
+		dst.Images = append(dst.Images, RetainedImage(i))
+	}
+	if r := s.Rsync; r != nil {
+		dst.Rsync = &ReplicationDestinationRsyncStatus{
+			SSHKeys: fromStringPtr(r.SSHKeys),
+			Address: fromStringPtr(r.Address),
+			Port:    r.Port,
+		}
+	}
+	if r := s.Restic; r != nil {
+		dst.Restic = &ReplicationDestinationResticStatus{
+			Snapshots:  resticSnapshotsFrom(r.Snapshots),
+			Repository: (*ResticRepositoryStats)(r.Repository),
+		}
+	}
+	return dst
+}
@@ -0,0 +1,402 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This file may be used, at your option, according to either the GNU AGPL 3.0 or
+the Apache V2 license.
+
+---
+This program is free software: you can redistribute it and/or modify it under
+the terms of the GNU Affero General Public License as published by the Free
+Software Foundation, either version 3 of the License, or (at your option) any
+later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE.  See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along
+with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+---
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//+kubebuilder:validation:Required
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReplicationDestinationTriggerSpec defines when a volume will be synchronized
+// with the source.
+type ReplicationDestinationTriggerSpec struct {
+	// schedule is a cronspec (https://en.wikipedia.org/wiki/Cron#Overview) that
+	// can be used to schedule replication to occur at regular, time-based
+	// intervals.
+	//+kubebuilder:validation:Pattern=`^(\d+|\*)(/\d+)?(\s+(\d+|\*)(/\d+)?){4}$`
+	//+optional
+	Schedule string `json:"schedule,omitempty"`
+	// manual is a string value that schedules a manual trigger.
+	// Once a sync completes then status.lastManualSync is set to the same string value.
+	// A consumer of a manual trigger should set spec.trigger.manual to a known value
+	// and then wait for lastManualSync to be updated by the operator to the same value,
+	// which means that the manual trigger will then pause and wait for further
+	// updates to the trigger.
+	//+optional
+	Manual string `json:"manual,omitempty"`
+}
+
+type ReplicationDestinationVolumeOptions struct {
+	// copyMethod describes how a point-in-time (PiT) image of the destination
+	// volume should be created.
+	CopyMethod CopyMethodType `json:"copyMethod,omitempty"`
+	// capacity is the size of the destination volume to create.
+	//+optional
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+	// storageClassName can be used to specify the StorageClass of the
+	// destination volume. If not set, the default StorageClass will be used.
+	//+optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// accessModes specifies the access modes for the destination volume.
+	//+kubebuilder:validation:MinItems=1
+	//+optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// volumeMode is the volumeMode of the destination volume that is created.
+	// Use Block to replicate raw block volumes. Defaults to Filesystem.
+	//+kubebuilder:validation:Enum=Filesystem;Block
+	//+optional
+	VolumeMode *corev1.PersistentVolumeMode `json:"volumeMode,omitempty"`
+	// volumeSnapshotClassName can be used to specify the VSC to be used if
+	// copyMethod is Snapshot. If not set, the default VSC is used.
+	//+optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+	// destinationPVC is a PVC to use as the transfer destination instead of
+	// automatically provisioning one. Either this field or both capacity and
+	// accessModes must be specified.
+	//+optional
+	DestinationPVC string `json:"destinationPVC,omitempty"`
+}
+
+type ReplicationDestinationRsyncSpec struct {
+	ReplicationDestinationVolumeOptions `json:",inline"`
+	// sshKeys is the name of a Secret that contains the SSH keys to be used for
+	// authentication. If not provided, the keys will be generated.
+	//+optional
+	SSHKeys string `json:"sshKeys,omitempty"`
+	// serviceType determines the Service type that will be created for incoming
+	// SSH connections.
+	//+optional
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+	// address is the remote address to connect to for replication.
+	//+optional
+	Address string `json:"address,omitempty"`
+	// port is the SSH port to connect to for replication. Defaults to 22.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=65535
+	//+optional
+	Port *int32 `json:"port,omitempty"`
+	// path is the remote path to rsync from. Defaults to "/"
+	//+optional
+	Path string `json:"path,omitempty"`
+	// sshUser is the username for outgoing SSH connections. Defaults to "root".
+	//+optional
+	SSHUser string `json:"sshUser,omitempty"`
+}
+
+// ReplicationDestinationRcloneSpec defines the field for rclone in replicationDestination.
+type ReplicationDestinationRcloneSpec struct {
+	ReplicationDestinationVolumeOptions `json:",inline"`
+	// RcloneConfigSection is the section in rclone_config file to use for the current job.
+	RcloneConfigSection string `json:"rcloneConfigSection,omitempty"`
+	// RcloneDestPath is the remote path to sync to.
+	RcloneDestPath string `json:"rcloneDestPath,omitempty"`
+	// RcloneConfig is the rclone secret name
+	RcloneConfig string `json:"rcloneConfig,omitempty"`
+}
+
+// ReplicationDestinationExternalSpec defines the configuration when using an
+// external replication provider.
+type ReplicationDestinationExternalSpec struct {
+	// provider is the name of the external replication provider. The name
+	// should be of the form: domain.com/provider.
+	Provider string `json:"provider,omitempty"`
+	// parameters are provider-specific key/value configuration parameters. For
+	// more information, please see the documentation of the specific
+	// replication provider being used.
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// ImageRetainPolicy defines which of the point-in-time images produced by a
+// ReplicationDestination are preserved. An image is kept if it is selected by
+// any of the rules. The most recent image is always kept.
+type ImageRetainPolicy struct {
+	// last defines the number of most recent images to be kept
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	Last *int32 `json:"last,omitempty"`
+	// hourly defines the number of images to be kept hourly
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Hourly *int32 `json:"hourly,omitempty"`
+	// daily defines the number of images to be kept daily
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Daily *int32 `json:"daily,omitempty"`
+	// weekly defines the number of images to be kept weekly
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Weekly *int32 `json:"weekly,omitempty"`
+	// monthly defines the number of images to be kept monthly
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Monthly *int32 `json:"monthly,omitempty"`
+}
+
+// ReplicationDestinationSpec defines the desired state of
+// ReplicationDestination
+type ReplicationDestinationSpec struct {
+	// trigger determines if/when the destination should attempt to synchronize
+	// data with the source.
+	//+optional
+	Trigger *ReplicationDestinationTriggerSpec `json:"trigger,omitempty"`
+	// rsync defines the configuration when using Rsync-based replication.
+	//+optional
+	Rsync *ReplicationDestinationRsyncSpec `json:"rsync,omitempty"`
+	// rclone defines the configuration when using Rclone-based replication.
+	//+optional
+	Rclone *ReplicationDestinationRcloneSpec `json:"rclone,omitempty"`
+	// restic defines the configuration when using Restic-based replication.
+	//+optional
+	Restic *ReplicationDestinationResticSpec `json:"restic,omitempty"`
+	// external defines the configuration when using an external replication
+	// provider.
+	//+optional
+	External *ReplicationDestinationExternalSpec `json:"external,omitempty"`
+	// moverPodTemplate can be used to customize the Pods that are created to
+	// perform data movement.
+	//+optional
+	MoverPodTemplate *MoverPodTemplateSpec `json:"moverPodTemplate,omitempty"`
+	// volumeGroup lists the PVCs that receive the data of a ReplicationSource's
+	// sourceVolumeGroup. Each PVC is used if it exists, otherwise it is
+	// created based on the method's volume options.
+	//+optional
+	VolumeGroup *VolumeGroupSpec `json:"volumeGroup,omitempty"`
+	// imageRetain determines how many of the point-in-time images (snapshots)
+	// created by previous synchronizations are preserved. If not provided,
+	// only the latest image is kept.
+	//+optional
+	ImageRetain *ImageRetainPolicy `json:"imageRetain,omitempty"`
+	// paused can be used to temporarily stop replication. Defaults to "false".
+	//+optional
+	Paused bool `json:"paused,omitempty"`
+	// retryPolicy determines how the synchronization is retried when the data
+	// mover fails. By default, it is retried until it succeeds.
+	//+optional
+	RetryPolicy *RetryPolicySpec `json:"retryPolicy,omitempty"`
+	// deletionPolicy determines whether the replicated data is removed when
+	// this object is deleted. With Delete, the retained images are removed
+	// before the object is. Defaults to Retain.
+	//+optional
+	DeletionPolicy DeletionPolicyType `json:"deletionPolicy,omitempty"`
+}
+
+// ReplicationDestinationResticStatus defines the status of Restic-based
+// replication on the ReplicationDestination
+type ReplicationDestinationResticStatus struct {
+	// snapshots lists the most recent snapshots in the repository, newest
+	// first
+	//+optional
+	Snapshots []ResticSnapshot `json:"snapshots,omitempty"`
+	// repository summarizes the contents of the repository
+	//+optional
+	Repository *ResticRepositoryStats `json:"repository,omitempty"`
+}
+
+type ReplicationDestinationRsyncStatus struct {
+	// sshKeys is the name of a Secret that contains the SSH keys to be used for
+	// authentication. If not provided in .spec.rsync.sshKeys, SSH keys will be
+	// generated and the appropriate keys for the remote side will be placed
+	// here.
+	//+optional
+	SSHKeys string `json:"sshKeys,omitempty"`
+	// address is the address to connect to for incoming SSH replication
+	// connections.
+	//+optional
+	Address string `json:"address,omitempty"`
+	// port is the SSH port to connect to for incoming SSH replication
+	// connections.
+	//+optional
+	Port *int32 `json:"port,omitempty"`
+}
+
+// ReplicationDestinationResticSpec defines the field for restic in replicationDestination.
+type ReplicationDestinationResticSpec struct {
+	ReplicationDestinationVolumeOptions `json:",inline"`
+	// Repository is the secret name containing repository info
+	Repository string `json:"repository,omitempty"`
+	// secretKeys selects the keys of the repository Secret that are passed to
+	// restic as environment variables. All of the Secret's keys are also
+	// available as files in /credentials.
+	//+optional
+	SecretKeys *ResticSecretKeysSpec `json:"secretKeys,omitempty"`
+	// identity configures the mover to authenticate to the repository's
+	// storage with its ServiceAccount
+	//+optional
+	Identity *ResticIdentitySpec `json:"identity,omitempty"`
+	// cacheCapacity can be used to set the size of the restic metadata cache volume
+	//+optional
+	CacheCapacity *resource.Quantity `json:"cacheCapacity,omitempty"`
+	// cacheStorageClassName can be used to set the StorageClass of the restic
+	// metadata cache volume
+	//+optional
+	CacheStorageClassName *string `json:"cacheStorageClassName,omitempty"`
+	// accessModes can be used to set the accessModes of restic metadata cache volume
+	//+optional
+	CacheAccessModes []corev1.PersistentVolumeAccessMode `json:"cacheAccessModes,omitempty"`
+	// Previous specifies the number of image to skip before selecting one to restore from
+	//+optional
+	Previous *int32 `json:"previous,omitempty"`
+	// RestoreAsOf refers to the backup that is most recent as of that time.
+	//+optional
+	RestoreAsOf *metav1.Time `json:"restoreAsOf,omitempty"`
+	// unlockStaleLocksAfter causes locks in the repository that are older
+	// than this duration to be removed before the mover runs. Such locks are
+	// typically left behind when a mover is interrupted. Locks are not removed
+	// if it is unset. It must be at least 30m: restic only refreshes the locks
+	// of running processes every 5 minutes, so a shorter age could remove a
+	// lock that is still held by another mover using the same repository.
+	//+kubebuilder:validation:Type=string
+	//+kubebuilder:validation:Format=duration
+	//+optional
+	UnlockStaleLocksAfter *metav1.Duration `json:"unlockStaleLocksAfter,omitempty"`
+	// listSnapshots is the number of the repository's most recent snapshots
+	// to list in the status. It defaults to 10, and 0 disables the list.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=20
+	//+optional
+	ListSnapshots *int32 `json:"listSnapshots,omitempty"`
+	// include limits the restore to the files and directories that match one
+	// of these patterns. Patterns use restic's --include syntax, and those that
+	// begin with "/" are relative to the root of the volume. Everything is
+	// restored if it is empty.
+	//+optional
+	Include []string `json:"include,omitempty"`
+	// hostname selects the snapshots to restore from. It must match the
+	// hostname used by the ReplicationSource that created them (normally
+	// "<namespace>/<name>" of the ReplicationSource). Defaults to "volsync",
+	// the hostname of backups made by prior versions.
+	//+optional
+	Hostname string `json:"hostname,omitempty"`
+	// tags, when set, limit the restore to the snapshots that have all of
+	// these tags
+	//+optional
+	Tags []string `json:"tags,omitempty"`
+}
+
+// RetainedImage is a point-in-time image that has been preserved by a
+// ReplicationDestination.
+type RetainedImage struct {
+	// image is the object holding the replicated data
+	Image corev1.TypedLocalObjectReference `json:"image"`
+	// creationTime is the time the image was created
+	CreationTime metav1.Time `json:"creationTime"`
+}
+
+// ReplicationDestinationStatus defines the observed state of ReplicationDestination
+type ReplicationDestinationStatus struct {
+	// lastSyncTime is the time of the most recent successful synchronization.
+	//+optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// lastSyncStartTime is the time the most recent synchronization started.
+	//+optional
+	LastSyncStartTime *metav1.Time `json:"lastSyncStartTime,omitempty"`
+	// lastSyncDuration is the amount of time required to send the most recent
+	// update.
+	//+optional
+	LastSyncDuration *metav1.Duration `json:"lastSyncDuration,omitempty"`
+	// nextSyncTime is the time when the next volume synchronization is
+	// scheduled to start (for schedule-based synchronization).
+	//+optional
+	NextSyncTime *metav1.Time `json:"nextSyncTime,omitempty"`
+	// lastManualSync is set to the last spec.trigger.manual when the manual sync is done.
+	//+optional
+	LastManualSync string `json:"lastManualSync,omitempty"`
+	// lastSyncResult is the outcome of the most recent synchronization
+	// attempt.
+	//+optional
+	LastSyncResult *SyncResult `json:"lastSyncResult,omitempty"`
+	// moverVersion describes the version of the data mover that is used for
+	// this object. In most cases, this is the container image.
+	//+optional
+	MoverVersion string `json:"moverVersion,omitempty"`
+	// latestImage in the object holding the most recent consistent replicated
+	// image.
+	//+optional
+	LatestImage *corev1.TypedLocalObjectReference `json:"latestImage,omitempty"`
+	// images is the list of point-in-time images that are currently being
+	// retained, ordered from newest to oldest.
+	//+optional
+	Images []RetainedImage `json:"images,omitempty"`
+	// volumeGroup contains the images of each of the volumes of the
+	// volumeGroup from the most recent synchronization.
+	//+optional
+	VolumeGroup *VolumeGroupStatus `json:"volumeGroup,omitempty"`
+	// rsync contains status information for Rsync-based replication.
+	Rsync *ReplicationDestinationRsyncStatus `json:"rsync,omitempty"`
+	// restic contains status information for Restic-based replication.
+	//+optional
+	Restic *ReplicationDestinationResticStatus `json:"restic,omitempty"`
+	// external contains provider-specific status information. For more details,
+	// please see the documentation of the specific replication provider being
+	// used.
+	//+optional
+	External map[string]string `json:"external,omitempty"`
+	// conditions represent the latest available observations of the
+	// destination's state.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ReplicationDestination defines the destination for a replicated volume
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Last sync",type="string",format="date-time",JSONPath=`.status.lastSyncTime`
+//+kubebuilder:printcolumn:name="Duration",type="string",JSONPath=`.status.lastSyncDuration`
+//+kubebuilder:printcolumn:name="Next sync",type="string",format="date-time",JSONPath=`.status.nextSyncTime`
+type ReplicationDestination struct {
+	metav1.TypeMeta `json:",inline"`
+	//+optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// spec is the desired state of the ReplicationDestination, including the
+	// replication method to use and its configuration.
+	Spec ReplicationDestinationSpec `json:"spec,omitempty"`
+	// status is the observed state of the ReplicationDestination as determined
+	// by the controller.
+	//+optional
+	Status ReplicationDestinationStatus `json:"status,omitempty"`
+}
+
+// ReplicationDestinationList contains a list of ReplicationDestination
+//+kubebuilder:object:root=true
+type ReplicationDestinationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReplicationDestination `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReplicationDestination{}, &ReplicationDestinationList{})
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This file may be used, at your option, according to either the GNU AGPL 3.0 or
+the Apache V2 license.
+
+---
+This program is free software: you can redistribute it and/or modify it under
+the terms of the GNU Affero General Public License as published by the Free
+Software Foundation, either version 3 of the License, or (at your option) any
+later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE.  See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along
+with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+---
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/backube/volsync/api/v1alpha1"
+)
+
+var _ conversion.Convertible = &ReplicationSource{}
+
+// ConvertTo converts this ReplicationSource to the Hub version (v1alpha1)
+func (src *ReplicationSource) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.ReplicationSource)
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.SourcePVC = src.Spec.SourcePVC
+	dst.Spec.SourceVolumeGroup = (*v1alpha1.VolumeGroupSpec)(src.Spec.SourceVolumeGroup)
+	dst.Spec.Trigger = nil
+	if src.Spec.Trigger != nil {
+		dst.Spec.Trigger = &v1alpha1.ReplicationSourceTriggerSpec{
+			Schedule: toStringPtr(src.Spec.Trigger.Schedule),
+			Manual:   src.Spec.Trigger.Manual,
+		}
+	}
+	dst.Spec.Rsync = nil
+	if r := src.Spec.Rsync; r != nil {
+		dst.Spec.Rsync = &v1alpha1.ReplicationSourceRsyncSpec{
+			ReplicationSourceVolumeOptions: r.ReplicationSourceVolumeOptions.convertTo(),
+			SSHKeys:                        toStringPtr(r.SSHKeys),
+			ServiceType:                    toServiceTypePtr(r.ServiceType),
+			Address:                        toStringPtr(r.Address),
+			Port:                           r.Port,
+			Path:                           toStringPtr(r.Path),
+			SSHUser:                        toStringPtr(r.SSHUser),
+		}
+	}
+	dst.Spec.Rclone = nil
+	if r := src.Spec.Rclone; r != nil {
+		dst.Spec.Rclone = &v1alpha1.ReplicationSourceRcloneSpec{
+			ReplicationSourceVolumeOptions: r.ReplicationSourceVolumeOptions.convertTo(),
+			RcloneConfigSection:            toStringPtr(r.RcloneConfigSection),
+			RcloneDestPath:                 toStringPtr(r.RcloneDestPath),
+			RcloneConfig:                   toStringPtr(r.RcloneConfig),
+		}
+	}
+	dst.Spec.Restic = nil
+	if r := src.Spec.Restic; r != nil {
+		dst.Spec.Restic = &v1alpha1.ReplicationSourceResticSpec{
+			ReplicationSourceVolumeOptions: r.ReplicationSourceVolumeOptions.convertTo(),
+			PruneIntervalDays:              r.PruneIntervalDays,
+			CheckIntervalDays:              r.CheckIntervalDays,
+			CheckReadDataSubset:            r.CheckReadDataSubset,
+			UnlockStaleLocksAfter:          r.UnlockStaleLocksAfter,
+			ListSnapshots:                  r.ListSnapshots,
+			Exclude:                        r.Exclude,
+			ExcludeIfPresent:               r.ExcludeIfPresent,
+			Hostname:                       r.Hostname,
+			Tags:                           r.Tags,
+			Repository:                     r.Repository,
+			SecretKeys:                     (*v1alpha1.ResticSecretKeysSpec)(r.SecretKeys),
+			Identity:                       (*v1alpha1.ResticIdentitySpec)(r.Identity),
+			CacheCapacity:                  r.CacheCapacity,
+			CacheStorageClassName:          r.CacheStorageClassName,
+			CacheAccessModes:               r.CacheAccessModes,
+		}
+		if p := r.Retain; p != nil {
+			dst.Spec.Restic.Retain = &v1alpha1.ResticRetainPolicy{
+				Hourly:  p.Hourly,
+				Daily:   p.Daily,
+				Weekly:  p.Weekly,
+				Monthly: p.Monthly,
+				Yearly:  p.Yearly,
+				Within:  toStringPtr(p.Within),
+			}
+		}
+	}
+	dst.Spec.Syncthing = nil
+	if s := src.Spec.Syncthing; s != nil {
+		dst.Spec.Syncthing = &v1alpha1.ReplicationSourceSyncthingSpec{
+			ServiceType: toServiceTypePtr(s.ServiceType),
+		}
+		for _, p := range s.Peers {
+			dst.Spec.Syncthing.Peers = append(dst.Spec.Syncthing.Peers, v1alpha1.SyncthingPeer(p))
+		}
+	}
+	dst.Spec.External = nil
+	if e := src.Spec.External; e != nil {
+		dst.Spec.External = &v1alpha1.ReplicationSourceExternalSpec{
+			Provider:   e.Provider,
+			Parameters: e.Parameters,
+		}
+	}
+	dst.Spec.MoverPodTemplate = src.Spec.MoverPodTemplate.convertTo()
+	dst.Spec.Hooks = src.Spec.Hooks.convertTo()
+	dst.Spec.Paused = src.Spec.Paused
+	dst.Spec.RetryPolicy = src.Spec.RetryPolicy.convertTo()
+	dst.Spec.SyncTimeout = src.Spec.SyncTimeout
+	dst.Spec.DeletionPolicy = v1alpha1.DeletionPolicyType(src.Spec.DeletionPolicy)
+
+	// Status
+	dst.Status = nil
+	if !equality.Semantic.DeepEqual(src.Status, ReplicationSourceStatus{}) {
+		dst.Status = src.Status.convertTo()
+	}
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha1) to this version
+func (dst *ReplicationSource) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.ReplicationSource)
+	dst.ObjectMeta = src.ObjectMeta
+
+	// Spec
+	dst.Spec.SourcePVC = src.Spec.SourcePVC
+	dst.Spec.SourceVolumeGroup = (*VolumeGroupSpec)(src.Spec.SourceVolumeGroup)
+	dst.Spec.Trigger = nil
+	if src.Spec.Trigger != nil {
+		dst.Spec.Trigger = &ReplicationSourceTriggerSpec{
+			Schedule: fromStringPtr(src.Spec.Trigger.Schedule),
+			Manual:   src.Spec.Trigger.Manual,
+		}
+	}
+	dst.Spec.Rsync = nil
+	if r := src.Spec.Rsync; r != nil {
+		dst.Spec.Rsync = &ReplicationSourceRsyncSpec{
+			ReplicationSourceVolumeOptions: sourceVolumeOptionsFrom(&r.ReplicationSourceVolumeOptions),
+			SSHKeys:                        fromStringPtr(r.SSHKeys),
+			ServiceType:                    fromServiceTypePtr(r.ServiceType),
+			Address:                        fromStringPtr(r.Address),
+			Port:                           r.Port,
+			Path:                           fromStringPtr(r.Path),
+			SSHUser:                        fromStringPtr(r.SSHUser),
+		}
+	}
+	dst.Spec.Rclone = nil
+	if r := src.Spec.Rclone; r != nil {
+		dst.Spec.Rclone = &ReplicationSourceRcloneSpec{
+			ReplicationSourceVolumeOptions: sourceVolumeOptionsFrom(&r.ReplicationSourceVolumeOptions),
+			RcloneConfigSection:            fromStringPtr(r.RcloneConfigSection),
+			RcloneDestPath:                 fromStringPtr(r.RcloneDestPath),
+			RcloneConfig:                   fromStringPtr(r.RcloneConfig),
+		}
+	}
+	dst.Spec.Restic = nil
+	if r := src.Spec.Restic; r != nil {
+		dst.Spec.Restic = &ReplicationSourceResticSpec{
+			ReplicationSourceVolumeOptions: sourceVolumeOptionsFrom(&r.ReplicationSourceVolumeOptions),
+			PruneIntervalDays:              r.PruneIntervalDays,
+			CheckIntervalDays:              r.CheckIntervalDays,
+			CheckReadDataSubset:            r.CheckReadDataSubset,
+			UnlockStaleLocksAfter:          r.UnlockStaleLocksAfter,
+			ListSnapshots:                  r.ListSnapshots,
+			Exclude:                        r.Exclude,
+			ExcludeIfPresent:               r.ExcludeIfPresent,
+			Hostname:                       r.Hostname,
+			Tags:                           r.Tags,
+			Repository:                     r.Repository,
+			SecretKeys:                     (*ResticSecretKeysSpec)(r.SecretKeys),
+			Identity:                       (*ResticIdentitySpec)(r.Identity),
+			CacheCapacity:                  r.CacheCapacity,
+			CacheStorageClassName:          r.CacheStorageClassName,
+			CacheAccessModes:               r.CacheAccessModes,
+		}
+		if p := r.Retain; p != nil {
+			dst.Spec.Restic.Retain = &ResticRetainPolicy{
+				Hourly:  p.Hourly,
+				Daily:   p.Daily,
+				Weekly:  p.Weekly,
+				Monthly: p.Monthly,
+				Yearly:  p.Yearly,
+				Within:  fromStringPtr(p.Within),
+			}
+		}
+	}
+	dst.Spec.Syncthing = nil
+	if s := src.Spec.Syncthing; s != nil {
+		dst.Spec.Syncthing = &ReplicationSourceSyncthingSpec{
+			ServiceType: fromServiceTypePtr(s.ServiceType),
+		}
+		for _, p := range s.Peers {
+			dst.Spec.Syncthing.Peers = append(dst.Spec.Syncthing.Peers, SyncthingPeer(p))
+		}
+	}
+	dst.Spec.External = nil
+	if e := src.Spec.External; e != nil {
+		dst.Spec.External = &ReplicationSourceExternalSpec{
+			Provider:   e.Provider,
+			Parameters: e.Parameters,
+		}
+	}
+	dst.Spec.MoverPodTemplate = moverPodTemplateFrom(src.Spec.MoverPodTemplate)
+	dst.Spec.Hooks = syncHooksFrom(src.Spec.Hooks)
+	dst.Spec.Paused = src.Spec.Paused
+	dst.Spec.RetryPolicy = retryPolicyFrom(src.Spec.RetryPolicy)
+	dst.Spec.SyncTimeout = src.Spec.SyncTimeout
+	dst.Spec.DeletionPolicy = DeletionPolicyType(src.Spec.DeletionPolicy)
+
+	// Status
+	dst.Status = ReplicationSourceStatus{}
+	if src.Status != nil {
+		dst.Status = sourceStatusFrom(src.Status)
+	}
+	return nil
+}
+
+func (o *ReplicationSourceVolumeOptions) convertTo() v1alpha1.ReplicationSourceVolumeOptions {
+	return v1alpha1.ReplicationSourceVolumeOptions{
+		CopyMethod:              toCopyMethod(o.CopyMethod),
+		Capacity:                o.Capacity,
+		StorageClassName:        o.StorageClassName,
+		AccessModes:             o.AccessModes,
+		VolumeSnapshotClassName: o.VolumeSnapshotClassName,
+	}
+}
+
+func sourceVolumeOptionsFrom(o *v1alpha1.ReplicationSourceVolumeOptions) ReplicationSourceVolumeOptions {
+	return ReplicationSourceVolumeOptions{
+		CopyMethod:              fromCopyMethod(o.CopyMethod),
+		Capacity:                o.Capacity,
+		StorageClassName:        o.StorageClassName,
+		AccessModes:             o.AccessModes,
+		VolumeSnapshotClassName: o.VolumeSnapshotClassName,
+	}
+}
+
+func (s *ReplicationSourceStatus) convertTo() *v1alpha1.ReplicationSourceStatus {
+	dst := &v1alpha1.ReplicationSourceStatus{
+		LastSyncTime:      s.LastSyncTime,
+		LastSyncStartTime: s.LastSyncStartTime,
+		LastSyncDuration:  s.LastSyncDuration,
+		NextSyncTime:      s.NextSyncTime,
+		LastManualSync:    s.LastManualSync,
+		LastSyncResult:    s.LastSyncResult.convertTo(),
+		MoverVersion:      s.MoverVersion,
+		VolumeGroup:       s.VolumeGroup.convertTo(),
+		External:          s.External,
+		Conditions:        s.Conditions,
+	}
+	if r := s.Rsync; r != nil {
+		dst.Rsync = &v1alpha1.ReplicationSourceRsyncStatus{
+			SSHKeys: toStringPtr(r.SSHKeys),
+			Address: toStringPtr(r.Address),
+			Port:    r.Port,
+		}
+	}
+	if r := s.Restic; r != nil {
+		dst.Restic = &v1alpha1.ReplicationSourceResticStatus{
+			LastPruned:       r.LastPruned,
+			LastChecked:      r.LastChecked,
+			LastCheckResult:  r.LastCheckResult,
+			LastCheckMessage: r.LastCheckMessage,
+			Snapshots:        resticSnapshotsTo(r.Snapshots),
+			Repository:       (*v1alpha1.ResticRepositoryStats)(r.Repository),
+		}
+	}
+	if st := s.Syncthing; st != nil {
+		dst.Syncthing = &v1alpha1.ReplicationSourceSyncthingStatus{
+			DeviceID: st.DeviceID,
+			Address:  st.Address,
+		}
+		for _, p := range st.Peers {
+			dst.Syncthing.Peers = append(dst.Syncthing.Peers, v1alpha1.SyncthingPeerStatus(p))
+		}
+	}
+	return dst
+}
+
+func sourceStatusFrom(s *v1alpha1.ReplicationSourceStatus) ReplicationSourceStatus {
+	dst := ReplicationSourceStatus{
+		LastSyncTime:      s.LastSyncTime,
+		LastSyncStartTime: s.LastSyncStartTime,
+		LastSyncDuration:  s.LastSyncDuration,
+		NextSyncTime:      s.NextSyncTime,
+		LastManualSync:    s.LastManualSync,
+		LastSyncResult:    syncResultFrom(s.LastSyncResult),
+		MoverVersion:      s.MoverVersion,
+		VolumeGroup:       volumeGroupStatusFrom(s.VolumeGroup),
+		External:          s.External,
+		Conditions:        s.Conditions,
+	}
+	if r := s.Rsync; r != nil {
+		dst.Rsync = &ReplicationSourceRsyncStatus{
+			SSHKeys: fromStringPtr(r.SSHKeys),
+			Address: fromStringPtr(r.Address),
+			Port:    r.Port,
+		}
+	}
+	if r := s.Restic; r != nil {
+		dst.Restic = &ReplicationSourceResticStatus{
+			LastPruned:       r.LastPruned,
+			LastChecked:      r.LastChecked,
+			LastCheckResult:  r.LastCheckResult,
+			LastCheckMessage: r.LastCheckMessage,
+			Snapshots:        resticSnapshotsFrom(r.Snapshots),
+			Repository:       (*ResticRepositoryStats)(r.Repository),
+		}
+	}
+	if st := s.Syncthing; st != nil {
+		dst.Syncthing = &ReplicationSourceSyncthingStatus{
+			DeviceID: st.DeviceID,
+			Address:  st.Address,
+		}
+		for _, p := range st.Peers {
+			dst.Syncthing.Peers = append(dst.Syncthing.Peers, SyncthingPeerStatus(p))
+		}
+	}
+	return dst
+}
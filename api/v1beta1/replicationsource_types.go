@@ -0,0 +1,492 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This file may be used, at your option, according to either the GNU AGPL 3.0 or
+the Apache V2 license.
+
+---
+This program is free software: you can redistribute it and/or modify it under
+the terms of the GNU Affero General Public License as published by the Free
+Software Foundation, either version 3 of the License, or (at your option) any
+later version.
+
+This program is distributed in the hope that it will be useful, but WITHOUT ANY
+WARRANTY; without even the implied warranty of MERCHANTABILITY or FITNESS FOR A
+PARTICULAR PURPOSE.  See the GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License along
+with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+---
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//+kubebuilder:validation:Required
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReplicationSourceTriggerSpec defines when a volume will be synchronized with
+// the destination.
+type ReplicationSourceTriggerSpec struct {
+	// schedule is a cronspec (https://en.wikipedia.org/wiki/Cron#Overview) that
+	// can be used to schedule replication to occur at regular, time-based
+	// intervals.
+	//+kubebuilder:validation:Pattern=`^(\d+|\*)(/\d+)?(\s+(\d+|\*)(/\d+)?){4}$`
+	//+optional
+	Schedule string `json:"schedule,omitempty"`
+	// manual is a string value that schedules a manual trigger.
+	// Once a sync completes then status.lastManualSync is set to the same string value.
+	// A consumer of a manual trigger should set spec.trigger.manual to a known value
+	// and then wait for lastManualSync to be updated by the operator to the same value,
+	// which means that the manual trigger will then pause and wait for further
+	// updates to the trigger.
+	//+optional
+	Manual string `json:"manual,omitempty"`
+}
+
+// ReplicationSourceExternalSpec defines the configuration when using an
+// external replication provider.
+type ReplicationSourceExternalSpec struct {
+	// provider is the name of the external replication provider. The name
+	// should be of the form: domain.com/provider.
+	Provider string `json:"provider,omitempty"`
+	// parameters are provider-specific key/value configuration parameters. For
+	// more information, please see the documentation of the specific
+	// replication provider being used.
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+type ReplicationSourceVolumeOptions struct {
+	// copyMethod describes how a point-in-time (PiT) image of the source volume
+	// should be created.
+	CopyMethod CopyMethodType `json:"copyMethod,omitempty"`
+	// capacity can be used to override the capacity of the PiT image.
+	//+optional
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+	// storageClassName can be used to override the StorageClass of the PiT
+	// image.
+	//+optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+	// accessModes can be used to override the accessModes of the PiT image.
+	//+kubebuilder:validation:MinItems=1
+	//+optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	// volumeSnapshotClassName can be used to specify the VSC to be used if
+	// copyMethod is Snapshot. If not set, the default VSC is used.
+	//+optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+}
+
+type ReplicationSourceRsyncSpec struct {
+	ReplicationSourceVolumeOptions `json:",inline"`
+	// sshKeys is the name of a Secret that contains the SSH keys to be used for
+	// authentication. If not provided, the keys will be generated.
+	//+optional
+	SSHKeys string `json:"sshKeys,omitempty"`
+	// serviceType determines the Service type that will be created for incoming
+	// SSH connections.
+	//+optional
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+	// address is the remote address to connect to for replication.
+	//+optional
+	Address string `json:"address,omitempty"`
+	// port is the SSH port to connect to for replication. Defaults to 22.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=65535
+	//+optional
+	Port *int32 `json:"port,omitempty"`
+	// path is the remote path to rsync to. Defaults to "/"
+	//+optional
+	Path string `json:"path,omitempty"`
+	// sshUser is the username for outgoing SSH connections. Defaults to "root".
+	//+optional
+	SSHUser string `json:"sshUser,omitempty"`
+}
+
+// ReplicationSourceRcloneSpec defines the field for rclone in replicationSource.
+type ReplicationSourceRcloneSpec struct {
+	ReplicationSourceVolumeOptions `json:",inline"`
+	// RcloneConfigSection is the section in rclone_config file to use for the current job.
+	RcloneConfigSection string `json:"rcloneConfigSection,omitempty"`
+	// RcloneDestPath is the remote path to sync to.
+	RcloneDestPath string `json:"rcloneDestPath,omitempty"`
+	// RcloneConfig is the rclone secret name
+	RcloneConfig string `json:"rcloneConfig,omitempty"`
+}
+
+// ResticRetainPolicy defines the fields for Restic backup
+type ResticRetainPolicy struct {
+	// Hourly defines the number of snapshots to be kept hourly
+	//+optional
+	Hourly *int32 `json:"hourly,omitempty"`
+	// Daily defines the number of snapshots to be kept daily
+	//+optional
+	Daily *int32 `json:"daily,omitempty"`
+	// Weekly defines the number of snapshots to be kept weekly
+	//+optional
+	Weekly *int32 `json:"weekly,omitempty"`
+	// Monthly defines the number of snapshots to be kept monthly
+	//+optional
+	Monthly *int32 `json:"monthly,omitempty"`
+	// Yearly defines the number of snapshots to be kept yearly
+	//+optional
+	Yearly *int32 `json:"yearly,omitempty"`
+	// Within defines the number of snapshots to be kept Within the given time period
+	//+optional
+	Within string `json:"within,omitempty"`
+}
+
+// ReplicationSourceResticSpec defines the field for restic in replicationSource.
+type ReplicationSourceResticSpec struct {
+	ReplicationSourceVolumeOptions `json:",inline"`
+	// PruneIntervalDays define how often to prune the repository
+	PruneIntervalDays *int32 `json:"pruneIntervalDays,omitempty"`
+	// checkIntervalDays defines how often to verify the integrity of the
+	// repository with "restic check". The repository is not checked if it is
+	// unset.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	CheckIntervalDays *int32 `json:"checkIntervalDays,omitempty"`
+	// checkReadDataSubset additionally reads and verifies a subset of the
+	// repository's data during the check (e.g., "10%" or "1/5"). It is passed
+	// to restic's --read-data-subset option.
+	//+optional
+	CheckReadDataSubset string `json:"checkReadDataSubset,omitempty"`
+	// unlockStaleLocksAfter causes locks in the repository that are older
+	// than this duration to be removed before the mover runs. Such locks are
+	// typically left behind when a mover is interrupted. Locks are not removed
+	// if it is unset. It must be at least 30m: restic only refreshes the locks
+	// of running processes every 5 minutes, so a shorter age could remove a
+	// lock that is still held by another mover using the same repository.
+	//+kubebuilder:validation:Type=string
+	//+kubebuilder:validation:Format=duration
+	//+optional
+	UnlockStaleLocksAfter *metav1.Duration `json:"unlockStaleLocksAfter,omitempty"`
+	// listSnapshots is the number of the repository's most recent snapshots
+	// to list in the status. It defaults to 10, and 0 disables the list.
+	//+kubebuilder:validation:Minimum=0
+	//+kubebuilder:validation:Maximum=20
+	//+optional
+	ListSnapshots *int32 `json:"listSnapshots,omitempty"`
+	// exclude is a list of patterns for files and directories that should not
+	// be backed up. Patterns use restic's --exclude syntax, and those that
+	// begin with "/" are relative to the root of the volume.
+	//+optional
+	Exclude []string `json:"exclude,omitempty"`
+	// excludeIfPresent excludes the directories that contain a file with one
+	// of these names (e.g., "CACHEDIR.TAG"), like restic's --exclude-if-present
+	//+optional
+	ExcludeIfPresent []string `json:"excludeIfPresent,omitempty"`
+	// hostname identifies this volume's snapshots in the repository. It is
+	// recorded in each backup, and only the snapshots with this hostname are
+	// removed by the retention policy. It is set to "<namespace>/<name>" when
+	// the ReplicationSource is created. If it is unset, as for objects created
+	// by prior versions, "volsync" is used.
+	//+optional
+	Hostname string `json:"hostname,omitempty"`
+	// tags are added to each backup. When set, only the snapshots that have
+	// all of these tags are removed by the retention policy.
+	//+optional
+	Tags []string `json:"tags,omitempty"`
+	// Repository is the secret name containing repository info
+	Repository string `json:"repository,omitempty"`
+	// secretKeys selects the keys of the repository Secret that are passed to
+	// restic as environment variables. All of the Secret's keys are also
+	// available as files in /credentials.
+	//+optional
+	SecretKeys *ResticSecretKeysSpec `json:"secretKeys,omitempty"`
+	// identity configures the mover to authenticate to the repository's
+	// storage with its ServiceAccount
+	//+optional
+	Identity *ResticIdentitySpec `json:"identity,omitempty"`
+	// ResticRetainPolicy define the retain policy
+	//+optional
+	Retain *ResticRetainPolicy `json:"retain,omitempty"`
+	// cacheCapacity can be used to set the size of the restic metadata cache volume
+	//+optional
+	CacheCapacity *resource.Quantity `json:"cacheCapacity,omitempty"`
+	// cacheStorageClassName can be used to set the StorageClass of the restic
+	// metadata cache volume
+	//+optional
+	CacheStorageClassName *string `json:"cacheStorageClassName,omitempty"`
+	// accessModes can be used to set the accessModes of restic metadata cache volume
+	//+optional
+	CacheAccessModes []corev1.PersistentVolumeAccessMode `json:"cacheAccessModes,omitempty"`
+}
+
+// ReplicationSourceResticStatus defines the field for restic in ReplicationSourceStatus
+type ReplicationSourceResticStatus struct {
+	// lastPruned in the object holding the time of last pruned
+	//+optional
+	LastPruned *metav1.Time `json:"lastPruned,omitempty"`
+	// lastChecked is the time of the last repository integrity check
+	//+optional
+	LastChecked *metav1.Time `json:"lastChecked,omitempty"`
+	// lastCheckResult is the outcome of the last repository integrity check,
+	// either Succeeded or Failed
+	//+kubebuilder:validation:Enum=Succeeded;Failed
+	//+optional
+	LastCheckResult string `json:"lastCheckResult,omitempty"`
+	// lastCheckMessage describes the errors found by the last repository
+	// integrity check
+	//+optional
+	LastCheckMessage string `json:"lastCheckMessage,omitempty"`
+	// snapshots lists the most recent snapshots in the repository, newest
+	// first
+	//+optional
+	Snapshots []ResticSnapshot `json:"snapshots,omitempty"`
+	// repository summarizes the contents of the repository
+	//+optional
+	Repository *ResticRepositoryStats `json:"repository,omitempty"`
+}
+
+// define the Syncthing field
+type ReplicationSourceSyncthingSpec struct {
+	// List of Syncthing peers to be connected for syncing
+	Peers []SyncthingPeer `json:"peers,omitempty"`
+	// Type of service to be used when exposing the Syncthing peer
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+}
+
+// HookErrorPolicy determines what happens when a hook fails
+//+kubebuilder:validation:Enum=Fail;Continue
+type HookErrorPolicy string
+
+const (
+	// HookErrorPolicyFail causes the synchronization to be retried
+	HookErrorPolicyFail HookErrorPolicy = "Fail"
+	// HookErrorPolicyContinue ignores the error and proceeds with the
+	// synchronization
+	HookErrorPolicyContinue HookErrorPolicy = "Continue"
+)
+
+// DefaultHookTimeoutSeconds is the timeout used for hooks that don't specify
+// one
+const DefaultHookTimeoutSeconds int32 = 30
+
+// HookSpec defines a command that is executed inside a running Pod
+type HookSpec struct {
+	// podSelector selects the Pod(s) in the ReplicationSource's namespace in
+	// which the command is run. The command is run in each matching Pod that
+	// is Running.
+	PodSelector metav1.LabelSelector `json:"podSelector"`
+	// container is the name of the container in which the command is run.
+	// Defaults to the first container in the Pod.
+	//+optional
+	Container string `json:"container,omitempty"`
+	// command is the command (and arguments) to execute. It is not run in a
+	// shell.
+	//+kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+	// timeoutSeconds is the amount of time the command is permitted to run
+	// before it is considered to have failed. Defaults to 30.
+	//+kubebuilder:validation:Minimum=1
+	//+optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// onError determines whether a failure of the command should cause the
+	// synchronization to be retried ("Fail") or be ignored ("Continue").
+	// Defaults to "Fail".
+	//+optional
+	OnError HookErrorPolicy `json:"onError,omitempty"`
+}
+
+// SyncHooksSpec defines the hooks that are run around the creation of the
+// point-in-time copy (Snapshot or Clone) of the source volume.
+type SyncHooksSpec struct {
+	// pre hooks are run, in order, immediately before the point-in-time copy
+	// is created. They can be used to quiesce an application.
+	//+optional
+	Pre []HookSpec `json:"pre,omitempty"`
+	// post hooks are run, in order, once the point-in-time copy has been
+	// taken, or if taking it fails. They are also run if one of the pre hooks
+	// fails. They can be used to resume an application.
+	//+optional
+	Post []HookSpec `json:"post,omitempty"`
+	// serviceAccountName is the ServiceAccount, in the ReplicationSource's
+	// namespace, whose permissions are used to run the hooks. It must be
+	// allowed to create "pods/exec" for each of the selected Pods. Defaults to
+	// "default".
+	//+optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// ReplicationSourceSpec defines the desired state of ReplicationSource
+type ReplicationSourceSpec struct {
+	// sourcePVC is the name of the PersistentVolumeClaim (PVC) to replicate.
+	SourcePVC string `json:"sourcePVC,omitempty"`
+	// sourceVolumeGroup selects a group of PVCs to replicate together, instead
+	// of a single sourcePVC. The point-in-time copies of all the volumes are
+	// created together before any data is moved.
+	//+optional
+	SourceVolumeGroup *VolumeGroupSpec `json:"sourceVolumeGroup,omitempty"`
+	// trigger determines when the latest state of the volume will be captured
+	// (and potentially replicated to the destination).
+	//+optional
+	Trigger *ReplicationSourceTriggerSpec `json:"trigger,omitempty"`
+	// rsync defines the configuration when using Rsync-based replication.
+	//+optional
+	Rsync *ReplicationSourceRsyncSpec `json:"rsync,omitempty"`
+	// rclone defines the configuration when using Rclone-based replication.
+	//+optional
+	Rclone *ReplicationSourceRcloneSpec `json:"rclone,omitempty"`
+	// restic defines the configuration when using Restic-based replication.
+	//+optional
+	Restic *ReplicationSourceResticSpec `json:"restic,omitempty"`
+	// syncthing defines the configuration when using Syncthing-based replication.
+	//+optional
+	Syncthing *ReplicationSourceSyncthingSpec `json:"syncthing,omitempty"`
+	// external defines the configuration when using an external replication
+	// provider.
+	//+optional
+	External *ReplicationSourceExternalSpec `json:"external,omitempty"`
+	// moverPodTemplate can be used to customize the Pods that are created to
+	// perform data movement.
+	//+optional
+	MoverPodTemplate *MoverPodTemplateSpec `json:"moverPodTemplate,omitempty"`
+	// hooks are commands that are run in application Pods to make the
+	// point-in-time copy of the source volume application-consistent. They
+	// are only used with the Snapshot and Clone copyMethods.
+	//+optional
+	Hooks *SyncHooksSpec `json:"hooks,omitempty"`
+	// paused can be used to temporarily stop replication. Defaults to "false".
+	//+optional
+	Paused bool `json:"paused,omitempty"`
+	// syncTimeout is the maximum amount of time a synchronization may run. It
+	// is applied as the activeDeadlineSeconds of the mover's Job. A
+	// synchronization that exceeds it is stopped, and the next one begins at
+	// the following scheduled time. The default is no limit.
+	//+optional
+	SyncTimeout *metav1.Duration `json:"syncTimeout,omitempty"`
+	// retryPolicy determines how the synchronization is retried when the data
+	// mover fails. By default, it is retried until it succeeds.
+	//+optional
+	RetryPolicy *RetryPolicySpec `json:"retryPolicy,omitempty"`
+	// deletionPolicy determines whether the replicated data is removed when
+	// this object is deleted. With Delete, the mover removes the data it
+	// stored remotely (e.g., the object's backups in a restic repository or the
+	// rclone destination path). Defaults to Retain.
+	//+optional
+	DeletionPolicy DeletionPolicyType `json:"deletionPolicy,omitempty"`
+}
+
+type ReplicationSourceRsyncStatus struct {
+	// sshKeys is the name of a Secret that contains the SSH keys to be used for
+	// authentication. If not provided in .spec.rsync.sshKeys, SSH keys will be
+	// generated and the appropriate keys for the remote side will be placed
+	// here.
+	//+optional
+	SSHKeys string `json:"sshKeys,omitempty"`
+	// address is the address to connect to for incoming SSH replication
+	// connections.
+	//+optional
+	Address string `json:"address,omitempty"`
+	// port is the SSH port to connect to for incoming SSH replication
+	// connections.
+	//+optional
+	Port *int32 `json:"port,omitempty"`
+}
+
+type ReplicationSourceSyncthingStatus struct {
+	// peers is the list of the Syncthing peers and their connection status.
+	Peers []SyncthingPeerStatus `json:"peers,omitempty"`
+	// Device ID of the current syncthing device
+	DeviceID string `json:"deviceID,omitempty"`
+	// Service address where Syncthing is exposed to the rest of the world
+	Address string `json:"address,omitempty"`
+}
+
+// ReplicationSourceStatus defines the observed state of ReplicationSource
+type ReplicationSourceStatus struct {
+	// lastSyncTime is the time of the most recent successful synchronization.
+	//+optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+	// lastSyncStartTime is the time the most recent synchronization started.
+	//+optional
+	LastSyncStartTime *metav1.Time `json:"lastSyncStartTime,omitempty"`
+	// lastSyncDuration is the amount of time required to send the most recent
+	// update.
+	//+optional
+	LastSyncDuration *metav1.Duration `json:"lastSyncDuration,omitempty"`
+	// nextSyncTime is the time when the next volume synchronization is
+	// scheduled to start (for schedule-based synchronization).
+	//+optional
+	NextSyncTime *metav1.Time `json:"nextSyncTime,omitempty"`
+	// lastManualSync is set to the last spec.trigger.manual when the manual sync is done.
+	//+optional
+	LastManualSync string `json:"lastManualSync,omitempty"`
+	// lastSyncResult is the outcome of the most recent synchronization
+	// attempt.
+	//+optional
+	LastSyncResult *SyncResult `json:"lastSyncResult,omitempty"`
+	// moverVersion describes the version of the data mover that is used for
+	// this object. In most cases, this is the container image.
+	//+optional
+	MoverVersion string `json:"moverVersion,omitempty"`
+	// volumeGroup describes the most recent synchronization of the
+	// sourceVolumeGroup.
+	//+optional
+	VolumeGroup *VolumeGroupStatus `json:"volumeGroup,omitempty"`
+	// rsync contains status information for Rsync-based replication.
+	Rsync *ReplicationSourceRsyncStatus `json:"rsync,omitempty"`
+	// external contains provider-specific status information. For more details,
+	// please see the documentation of the specific replication provider being
+	// used.
+	//+optional
+	External map[string]string `json:"external,omitempty"`
+	// conditions represent the latest available observations of the
+	// source's state.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// restic contains status information for Restic-based replication.
+	Restic *ReplicationSourceResticStatus `json:"restic,omitempty"`
+	// syncthing contains status information for Syncthing-based replication.
+	//+optional
+	Syncthing *ReplicationSourceSyncthingStatus `json:"syncthing,omitempty"`
+}
+
+// ReplicationSource defines the source for a replicated volume
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Namespaced
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Source",type="string",JSONPath=`.spec.sourcePVC`
+//+kubebuilder:printcolumn:name="Last sync",type="string",format="date-time",JSONPath=`.status.lastSyncTime`
+//+kubebuilder:printcolumn:name="Duration",type="string",JSONPath=`.status.lastSyncDuration`
+//+kubebuilder:printcolumn:name="Next sync",type="string",format="date-time",JSONPath=`.status.nextSyncTime`
+type ReplicationSource struct {
+	metav1.TypeMeta `json:",inline"`
+	//+optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// spec is the desired state of the ReplicationSource, including the
+	// replication method to use and its configuration.
+	Spec ReplicationSourceSpec `json:"spec,omitempty"`
+	// status is the observed state of the ReplicationSource as determined by
+	// the controller.
+	//+optional
+	Status ReplicationSourceStatus `json:"status,omitempty"`
+}
+
+// ReplicationSourceList contains a list of Source
+//+kubebuilder:object:root=true
+type ReplicationSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ReplicationSource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ReplicationSource{}, &ReplicationSourceList{})
+}
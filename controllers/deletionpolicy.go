@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/mover"
+)
+
+// deletionFinalizer is added to ReplicationSources and ReplicationDestinations
+// with a deletionPolicy of Delete so that their data can be removed before the
+// object is.
+const deletionFinalizer = "volsync.backube/deletion-policy"
+
+// hooksFinalizer is added to ReplicationSources with hooks so that the post
+// hooks of any copies that are still pending can be run before the object is
+// deleted.
+const hooksFinalizer = "volsync.backube/post-hooks"
+
+// updateDeletionFinalizer adds or removes the deletionFinalizer to match the
+// object's deletionPolicy
+func updateDeletionFinalizer(ctx context.Context, c client.Client, obj client.Object,
+	policy volsyncv1alpha1.DeletionPolicyType) error {
+	return updateFinalizer(ctx, c, obj, deletionFinalizer, policy == volsyncv1alpha1.DeletionPolicyDelete)
+}
+
+// updateFinalizer adds or removes the finalizer depending on whether it's
+// wanted
+func updateFinalizer(ctx context.Context, c client.Client, obj client.Object, finalizer string, wanted bool) error {
+	if wanted == ctrlutil.ContainsFinalizer(obj, finalizer) {
+		return nil
+	}
+	if wanted {
+		ctrlutil.AddFinalizer(obj, finalizer)
+	} else {
+		ctrlutil.RemoveFinalizer(obj, finalizer)
+	}
+	return c.Update(ctx, obj)
+}
+
+// deleteMoverData removes the data that dataMover has stored outside of the
+// cluster. It returns true once the removal is complete. If the namespace is
+// being deleted, the mover can't run, so the data is left behind.
+func deleteMoverData(ctx context.Context, logger logr.Logger,
+	dataMover mover.Mover) (bool, ctrl.Result, error) {
+	if dataMover == nil { // Not an internal mover
+		return true, ctrl.Result{}, nil
+	}
+	mResult, err := dataMover.Delete(ctx)
+	if kerrors.HasStatusCause(err, corev1.NamespaceTerminatingCause) {
+		logger.Info("namespace is being deleted, replicated data will not be removed")
+		return true, ctrl.Result{}, nil
+	}
+	if !mResult.Completed || err != nil {
+		return false, mResult.ReconcileResult(), err
+	}
+	return true, ctrl.Result{}, nil
+}
@@ -18,7 +18,10 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package mover
 
 import (
+	"context"
+
 	"github.com/go-logr/logr"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
@@ -39,17 +42,20 @@ func Register(builder Builder) {
 type Builder interface {
 	// FromSource attempts to construct a Mover from the provided
 	// ReplicationSource. If the RS does not reference the Builder's mover type,
-	// this function should return (nil, nil).
-	FromSource(client client.Client, logger logr.Logger,
-		source *volsyncv1alpha1.ReplicationSource) (Mover, error)
+	// this function should return (nil, nil). The eventRecorder should be used
+	// to record Events against the ReplicationSource.
+	FromSource(ctx context.Context, client client.Client, logger logr.Logger,
+		eventRecorder record.EventRecorder, source *volsyncv1alpha1.ReplicationSource) (Mover, error)
 
 	// FromDestination attempts to construct a Mover from the provided
 	// ReplicationDestination. If the RS does not reference the Builder's mover
-	// type, this function should return (nil, nil).
-	FromDestination(client client.Client, logger logr.Logger,
-		destination *volsyncv1alpha1.ReplicationDestination) (Mover, error)
-
-	// VersionInfo returns a string describing the version of this mover. In
-	// most cases, this is the container image/tag that will be used.
+	// type, this function should return (nil, nil). The eventRecorder should be
+	// used to record Events against the ReplicationDestination.
+	FromDestination(ctx context.Context, client client.Client, logger logr.Logger,
+		eventRecorder record.EventRecorder, destination *volsyncv1alpha1.ReplicationDestination) (Mover, error)
+
+	// VersionInfo returns a string describing the default version of this
+	// mover. In most cases, this is the container image/tag that will be used
+	// unless it is overridden for a particular object.
 	VersionInfo() string
 }
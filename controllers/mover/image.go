@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mover
+
+import (
+	"context"
+	"errors"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+)
+
+// MoverImagesConfigMapName is the name of the ConfigMap that overrides the
+// mover container images for the objects in its namespace. Its keys are the
+// names of the movers (e.g., "restic"), and the values are the images to use.
+const MoverImagesConfigMapName = "volsync-mover-images"
+
+// AllowCustomImages permits the objects to override the mover image in their
+// moverPodTemplate. Since the mover runs with access to the replicated data and
+// its credentials, this is disabled unless the operator is configured for it.
+var AllowCustomImages bool
+
+// ErrCustomImageNotAllowed is returned when an object sets the mover image, but
+// the operator doesn't allow custom mover images.
+var ErrCustomImageNotAllowed = errors.New("moverPodTemplate.image is not permitted by the operator " +
+	"(see --allow-custom-mover-images)")
+
+// ContainerImage returns the container image that the mover, moverName, uses
+// for owner. An image in the owner's moverPodTemplate takes precedence over one
+// in the namespace's MoverImagesConfigMapName ConfigMap. If neither is set,
+// defaultImage is used.
+func ContainerImage(ctx context.Context, c client.Client, owner client.Object,
+	podTemplate *volsyncv1alpha1.MoverPodTemplateSpec, moverName string, defaultImage string) (string, error) {
+	if podTemplate != nil && podTemplate.Image != "" {
+		if !AllowCustomImages {
+			return "", ErrCustomImageNotAllowed
+		}
+		return podTemplate.Image, nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	err := c.Get(ctx, types.NamespacedName{Name: MoverImagesConfigMapName, Namespace: owner.GetNamespace()}, cm)
+	if kerrors.IsNotFound(err) {
+		return defaultImage, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if image := cm.Data[moverName]; image != "" {
+		return image, nil
+	}
+	return defaultImage, nil
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mover
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Failure describes why a synchronization attempt was unsuccessful
+type Failure struct {
+	// ExitCode is the exit code of the failed data mover container, if known
+	ExitCode *int32
+	// Message is a description of the failure
+	Message string
+	// Reason is the reason to use for the Degraded condition. If empty, the
+	// generic SyncFailed reason is used.
+	Reason string
+}
+
+// TransferStats summarizes the data transferred during a synchronization.
+// Movers report it by writing it as JSON to their termination message upon
+// successful completion.
+type TransferStats struct {
+	// BytesTransferred is the amount of data that was transferred
+	BytesTransferred int64 `json:"bytesTransferred"`
+	// FilesTransferred is the number of files that were transferred
+	FilesTransferred int64 `json:"filesTransferred"`
+}
+
+// FailureFromJob examines the Pods of a failed Job to determine why it failed.
+// The details are taken from the most recently terminated container with a
+// non-zero exit code. Mover containers should use a TerminationMessagePolicy
+// of FallbackToLogsOnError so that the message will contain the final lines of
+// the log if the mover doesn't write a termination message.
+func FailureFromJob(ctx context.Context, c client.Client, logger logr.Logger, job *batchv1.Job) *Failure {
+	failure := &Failure{
+		Message: fmt.Sprintf("Job %s reached its backoff limit", job.Name),
+	}
+
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(job.Namespace),
+		client.MatchingLabels{"controller-uid": string(job.UID)}); err != nil {
+		logger.Error(err, "unable to list Pods of failed Job")
+		return failure
+	}
+
+	var last *corev1.ContainerStateTerminated
+	for _, pod := range pods.Items {
+		for _, cs := range pod.Status.ContainerStatuses {
+			t := cs.State.Terminated
+			if t == nil || t.ExitCode == 0 {
+				continue
+			}
+			if last == nil || last.FinishedAt.Before(&t.FinishedAt) {
+				last = t
+			}
+		}
+	}
+	if last != nil {
+		exitCode := last.ExitCode
+		failure.ExitCode = &exitCode
+		if last.Message != "" {
+			failure.Message = last.Message
+		}
+	}
+	return failure
+}
+
+// StatsFromJob retrieves the TransferStats reported by the mover of a
+// successfully completed Job. If the mover did not report any statistics, nil
+// is returned.
+func StatsFromJob(ctx context.Context, c client.Client, logger logr.Logger, job *batchv1.Job) *TransferStats {
+	stats := &TransferStats{}
+	if !ReportFromJob(ctx, c, logger, job, stats) {
+		return nil
+	}
+	return stats
+}
+
+// ReportFromJob decodes the JSON termination message written by the mover of a
+// successfully completed Job into report. It returns false if no message could
+// be decoded.
+func ReportFromJob(ctx context.Context, c client.Client, logger logr.Logger, job *batchv1.Job,
+	report interface{}) bool {
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(job.Namespace),
+		client.MatchingLabels{"controller-uid": string(job.UID)}); err != nil {
+		logger.Error(err, "unable to list Pods of completed Job")
+		return false
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			t := cs.State.Terminated
+			if t == nil || t.ExitCode != 0 || t.Message == "" {
+				continue
+			}
+			if err := json.Unmarshal([]byte(t.Message), report); err != nil {
+				logger.V(1).Info("unable to parse mover report", "message", t.Message, "error", err.Error())
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
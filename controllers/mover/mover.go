@@ -23,6 +23,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
 )
 
 // Mover is a common interface that all data movers implement
@@ -30,6 +32,10 @@ type Mover interface {
 	// The name of this data mover
 	Name() string
 
+	// VersionInfo returns a string describing the version of the mover that is
+	// used for this object, taking any image overrides into account.
+	VersionInfo() string
+
 	// Synchronize begins or continues a synchronization attempt. Attempts will
 	// continue at least until the Result indicates that the synchronization is
 	// complete. Must be idempotent.
@@ -38,6 +44,12 @@ type Mover interface {
 	// Cleanup begins or continues the post-synchronization cleanup of temporary
 	// resources. Must be idempotent.
 	Cleanup(ctx context.Context) (Result, error)
+
+	// Delete begins or continues the removal of the data that the mover has
+	// stored outside of the cluster. It is called when the object is deleted
+	// with a deletionPolicy of Delete, and the object is kept until the Result
+	// indicates that the removal is complete. Must be idempotent.
+	Delete(ctx context.Context) (Result, error)
 }
 
 // Result indicates the outcome of a synchronization attempt
@@ -50,12 +62,25 @@ type Result struct {
 	// by the Synchronize() operation.
 	Image *corev1.TypedLocalObjectReference
 
+	// VolumeGroup describes the point-in-time copies of a volume group that
+	// have been synchronized. It is set instead of Image when replicating a
+	// group.
+	VolumeGroup *volsyncv1alpha1.VolumeGroupStatus
+
 	// RetryAfter is used to indicate whether synchronization should be
 	// explicitly retried, and when. Setting to nil (default) does not cause an
 	// explicit retry, but Synchronize() will be retried when a watched object
 	// is modified. Setting to 0 indicates an immediate retry. Other values
 	// provide a delay.
 	RetryAfter *time.Duration
+
+	// Failure, when set, indicates the most recent attempt failed. The
+	// synchronization is not complete and will be retried.
+	Failure *Failure
+
+	// Stats are the transfer statistics of a completed synchronization, if
+	// they were reported by the mover.
+	Stats *TransferStats
 }
 
 // ReconcileResult converts a Result into controllerruntime's reconcile result
@@ -78,6 +103,10 @@ func InProgress() Result { return Result{} }
 // requeueing after the provided duration.
 func RetryAfter(s time.Duration) Result { return Result{RetryAfter: &s} }
 
+// Failed indicates that the most recent attempt failed for the provided
+// reason. The operation is ongoing and will be retried.
+func Failed(f *Failure) Result { return Result{Failure: f} }
+
 // Complete indicates that the operation has completed.
 func Complete() Result {
 	return Result{
@@ -93,3 +122,12 @@ func CompleteWithImage(image *corev1.TypedLocalObjectReference) Result {
 		Image:     image,
 	}
 }
+
+// CompleteWithVolumeGroup indicates that the operation has completed, and it
+// provides the synchronized volume group to the controller.
+func CompleteWithVolumeGroup(group *volsyncv1alpha1.VolumeGroupStatus) Result {
+	return Result{
+		Completed:   true,
+		VolumeGroup: group,
+	}
+}
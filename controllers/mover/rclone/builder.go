@@ -18,11 +18,13 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package rclone
 
 import (
+	"context"
 	"flag"
 	"fmt"
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/viper"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
@@ -85,7 +87,8 @@ func (rb *Builder) getRcloneContainerImage() string {
 	return rb.viper.GetString(rcloneContainerImageFlag)
 }
 
-func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
+func (rb *Builder) FromSource(ctx context.Context, client client.Client, logger logr.Logger,
+	eventRecorder record.EventRecorder,
 	source *volsyncv1alpha1.ReplicationSource) (mover.Mover, error) {
 	// Only build if the CR belongs to us
 	if source.Spec.Rclone == nil {
@@ -94,29 +97,43 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 
 	vh, err := volumehandler.NewVolumeHandler(
 		volumehandler.WithClient(client),
+		volumehandler.WithRecorder(eventRecorder),
 		volumehandler.WithOwner(source),
 		volumehandler.FromSource(&source.Spec.Rclone.ReplicationSourceVolumeOptions),
+		volumehandler.WithHooks(source.Spec.Hooks),
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	containerImage, err := mover.ContainerImage(ctx, client, source, source.Spec.MoverPodTemplate,
+		"rclone", rb.getRcloneContainerImage())
+	if err != nil {
+		return nil, err
+	}
+
 	return &Mover{
 		client:              client,
+		eventRecorder:       eventRecorder,
 		logger:              logger.WithValues("method", "Rclone"),
 		owner:               source,
+		podTemplate:         source.Spec.MoverPodTemplate,
 		vh:                  vh,
-		containerImage:      rb.getRcloneContainerImage(),
+		containerImage:      containerImage,
 		rcloneConfigSection: source.Spec.Rclone.RcloneConfigSection,
 		rcloneDestPath:      source.Spec.Rclone.RcloneDestPath,
 		rcloneConfig:        source.Spec.Rclone.RcloneConfig,
 		isSource:            true,
 		paused:              source.Spec.Paused,
+		retryPolicy:         source.Spec.RetryPolicy,
+		syncTimeout:         source.Spec.SyncTimeout,
 		mainPVCName:         &source.Spec.SourcePVC,
+		volumeGroup:         source.Spec.SourceVolumeGroup,
 	}, nil
 }
 
-func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
+func (rb *Builder) FromDestination(ctx context.Context, client client.Client, logger logr.Logger,
+	eventRecorder record.EventRecorder,
 	destination *volsyncv1alpha1.ReplicationDestination) (mover.Mover, error) {
 	// Only build if the CR belongs to us
 	if destination.Spec.Rclone == nil {
@@ -125,6 +142,7 @@ func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
 
 	vh, err := volumehandler.NewVolumeHandler(
 		volumehandler.WithClient(client),
+		volumehandler.WithRecorder(eventRecorder),
 		volumehandler.WithOwner(destination),
 		volumehandler.FromDestination(&destination.Spec.Rclone.ReplicationDestinationVolumeOptions),
 	)
@@ -132,17 +150,27 @@ func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
 		return nil, err
 	}
 
+	containerImage, err := mover.ContainerImage(ctx, client, destination, destination.Spec.MoverPodTemplate,
+		"rclone", rb.getRcloneContainerImage())
+	if err != nil {
+		return nil, err
+	}
+
 	return &Mover{
 		client:              client,
+		eventRecorder:       eventRecorder,
 		logger:              logger.WithValues("method", "Rclone"),
 		owner:               destination,
+		podTemplate:         destination.Spec.MoverPodTemplate,
 		vh:                  vh,
-		containerImage:      rb.getRcloneContainerImage(),
+		containerImage:      containerImage,
 		rcloneConfigSection: destination.Spec.Rclone.RcloneConfigSection,
 		rcloneDestPath:      destination.Spec.Rclone.RcloneDestPath,
 		rcloneConfig:        destination.Spec.Rclone.RcloneConfig,
 		isSource:            false,
 		paused:              destination.Spec.Paused,
+		retryPolicy:         destination.Spec.RetryPolicy,
 		mainPVCName:         destination.Spec.Rclone.DestinationPVC,
+		volumeGroup:         destination.Spec.VolumeGroup,
 	}, nil
 }
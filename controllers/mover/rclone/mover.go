@@ -20,16 +20,21 @@ package rclone
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strconv"
 
 	"github.com/go-logr/logr"
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
 	"github.com/backube/volsync/controllers/mover"
 	"github.com/backube/volsync/controllers/utils"
 	"github.com/backube/volsync/controllers/volumehandler"
@@ -45,7 +50,8 @@ const (
 type Mover struct {
 	client              client.Client
 	logger              logr.Logger
-	owner               metav1.Object
+	eventRecorder       record.EventRecorder
+	owner               client.Object
 	vh                  *volumehandler.VolumeHandler
 	containerImage      string
 	rcloneConfigSection *string
@@ -53,7 +59,13 @@ type Mover struct {
 	rcloneConfig        *string
 	isSource            bool
 	paused              bool
+	syncTimeout         *metav1.Duration
+	retryPolicy         *volsyncv1alpha1.RetryPolicySpec
 	mainPVCName         *string
+	volumeGroup         *volsyncv1alpha1.VolumeGroupSpec
+	podTemplate         *volsyncv1alpha1.MoverPodTemplateSpec
+	// failure is set when the mover Job fails during this reconcile
+	failure *mover.Failure
 }
 
 var _ mover.Mover = &Mover{}
@@ -68,6 +80,10 @@ var cleanupTypes = []client.Object{
 
 func (m *Mover) Name() string { return "rclone" }
 
+func (m *Mover) VersionInfo() string {
+	return fmt.Sprintf("Rclone container: %s", m.containerImage)
+}
+
 func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 	var err error
 
@@ -82,16 +98,22 @@ func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 		return mover.InProgress(), err
 	}
 
-	// Allocate temporary data PVC
-	var dataPVC *corev1.PersistentVolumeClaim
+	// Allocate temporary data PVC(s)
+	var members []string
+	var dataPVCs []*corev1.PersistentVolumeClaim
 	if m.isSource {
-		dataPVC, err = m.ensureSourcePVC(ctx)
+		members, dataPVCs, err = m.ensureSourcePVCs(ctx)
 	} else {
-		dataPVC, err = m.ensureDestinationPVC(ctx)
+		members, dataPVCs, err = m.ensureDestinationPVCs(ctx)
 	}
-	if dataPVC == nil || err != nil {
+	if dataPVCs == nil || err != nil {
 		return mover.InProgress(), err
 	}
+	for _, dataPVC := range dataPVCs {
+		if mover.IsBlockVolume(dataPVC) {
+			return mover.InProgress(), fmt.Errorf("rclone does not support Block volumes: %s", dataPVC.Name)
+		}
+	}
 
 	// Prepare ServiceAccount, role, rolebinding
 	sa, err := m.ensureSA(ctx)
@@ -100,22 +122,38 @@ func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 	}
 
 	// Start mover Job
-	job, err := m.ensureJob(ctx, dataPVC, sa, rcloneConfigSecret)
+	job, err := m.ensureJob(ctx, members, dataPVCs, sa, rcloneConfigSecret)
+	if m.failure != nil {
+		return mover.Failed(m.failure), err
+	}
 	if job == nil || err != nil {
 		return mover.InProgress(), err
 	}
 
 	// On the destination, preserve the image and return it
+	var result mover.Result
 	if !m.isSource {
-		image, err := m.vh.EnsureImage(ctx, m.logger, dataPVC)
-		if image == nil || err != nil {
-			return mover.InProgress(), err
+		images := []*corev1.TypedLocalObjectReference{}
+		for _, dataPVC := range dataPVCs {
+			image, err := m.vh.EnsureImage(ctx, m.logger, dataPVC)
+			if image == nil || err != nil {
+				return mover.InProgress(), err
+			}
+			images = append(images, image)
 		}
-		return mover.CompleteWithImage(image), nil
+		if m.volumeGroup != nil {
+			result = mover.CompleteWithVolumeGroup(mover.DestinationVolumeGroupStatus(members, images))
+		} else {
+			result = mover.CompleteWithImage(images[0])
+		}
+	} else if m.volumeGroup != nil {
+		result = mover.CompleteWithVolumeGroup(mover.SourceVolumeGroupStatus(members, dataPVCs))
+	} else {
+		// On the source, just signal completion
+		result = mover.Complete()
 	}
-
-	// On the source, just signal completion
-	return mover.Complete(), nil
+	result.Stats = mover.StatsFromJob(ctx, m.client, m.logger, job)
+	return result, nil
 }
 
 func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
@@ -125,9 +163,15 @@ func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
 		// Cleanup the snapshot annotation on pvc for replicationDestination scenario so that
 		// on the next sync (if snapshot CopyMethod is being used) a new snapshot will be created rather than re-using
 		_, destPVCName := m.getDestinationPVCName()
-		err := m.vh.RemoveSnapshotAnnotationFromPVC(ctx, m.logger, destPVCName)
-		if err != nil {
-			return mover.InProgress(), err
+		destPVCNames := []string{destPVCName}
+		if m.volumeGroup != nil {
+			destPVCNames = m.volumeGroup.PVCNames
+		}
+		for _, name := range destPVCNames {
+			err := m.vh.RemoveSnapshotAnnotationFromPVC(ctx, m.logger, name)
+			if err != nil {
+				return mover.InProgress(), err
+			}
 		}
 	}
 
@@ -154,6 +198,37 @@ func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeCl
 	return m.vh.EnsurePVCFromSrc(ctx, m.logger, srcPVC, dataName, true)
 }
 
+// ensureSourcePVCs returns the PVCs to be synchronized. For a volume group, it
+// also returns the names of the members that the PVCs correspond to.
+func (m *Mover) ensureSourcePVCs(ctx context.Context) ([]string, []*corev1.PersistentVolumeClaim, error) {
+	if m.volumeGroup != nil {
+		dataPrefix := "volsync-" + m.owner.GetName() + "-src"
+		return m.vh.EnsureVolumeGroupFromSrc(ctx, m.logger, m.volumeGroup, dataPrefix, true)
+	}
+	dataPVC, err := m.ensureSourcePVC(ctx)
+	if dataPVC == nil || err != nil {
+		return nil, nil, err
+	}
+	return nil, []*corev1.PersistentVolumeClaim{dataPVC}, nil
+}
+
+// ensureDestinationPVCs returns the PVCs to synchronize into. For a volume
+// group, it also returns the names of the members that the PVCs correspond to.
+func (m *Mover) ensureDestinationPVCs(ctx context.Context) ([]string, []*corev1.PersistentVolumeClaim, error) {
+	if m.volumeGroup != nil {
+		dataPVCs, err := m.vh.EnsureVolumeGroupPVCs(ctx, m.logger, m.volumeGroup)
+		if err != nil {
+			return nil, nil, err
+		}
+		return m.volumeGroup.PVCNames, dataPVCs, nil
+	}
+	dataPVC, err := m.ensureDestinationPVC(ctx)
+	if dataPVC == nil || err != nil {
+		return nil, nil, err
+	}
+	return nil, []*corev1.PersistentVolumeClaim{dataPVC}, nil
+}
+
 // this is so far is common to rclone & restic
 func (m *Mover) ensureDestinationPVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
 	isProvidedPVC, dataPVCName := m.getDestinationPVCName()
@@ -173,6 +248,46 @@ func (m *Mover) getDestinationPVCName() (bool, string) {
 }
 
 // this is so far is common to rclone & restic
+func (m *Mover) Delete(ctx context.Context) (mover.Result, error) {
+	// The data at the destination path is written by the source
+	if !m.isSource {
+		return mover.Complete(), nil
+	}
+
+	if err := m.validateSpec(); err != nil {
+		return mover.InProgress(), err
+	}
+
+	rcloneConfigSecret, err := m.validateRcloneConfig(ctx)
+	if kerrors.IsNotFound(err) {
+		// Without the rclone config, there's no way to reach the destination
+		m.logger.Info("rcloneConfig Secret not found, destination path will not be removed")
+		return mover.Complete(), nil
+	}
+	if rcloneConfigSecret == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	// Stop any transfer that is in progress
+	if err := utils.CleanupObjects(ctx, m.client, m.logger, m.owner, cleanupTypes); err != nil {
+		return mover.InProgress(), err
+	}
+
+	sa, err := m.ensureSA(ctx)
+	if sa == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	job, err := m.ensureDeleteJob(ctx, sa, rcloneConfigSecret)
+	if m.failure != nil {
+		return mover.Failed(m.failure), err
+	}
+	if job == nil || err != nil {
+		return mover.InProgress(), err
+	}
+	return mover.Complete(), nil
+}
+
 func (m *Mover) ensureSA(ctx context.Context) (*corev1.ServiceAccount, error) {
 	dir := "src"
 	if !m.isSource {
@@ -193,7 +308,7 @@ func (m *Mover) ensureSA(ctx context.Context) (*corev1.ServiceAccount, error) {
 }
 
 //nolint:funlen
-func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeClaim,
+func (m *Mover) ensureJob(ctx context.Context, members []string, dataPVCs []*corev1.PersistentVolumeClaim,
 	sa *corev1.ServiceAccount, rcloneConfigSecret *corev1.Secret) (*batchv1.Job, error) {
 	dir := "src"
 	direction := "source"
@@ -216,59 +331,79 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 		}
 		utils.MarkForCleanup(m.owner, job)
 		job.Spec.Template.ObjectMeta.Name = job.Name
-		backoffLimit := int32(2) //TODO: backofflimit was 8 for restic
-		job.Spec.BackoffLimit = &backoffLimit
+		job.Spec.BackoffLimit = utils.JobBackoffLimit(2, m.retryPolicy)
 
 		parallelism := int32(1)
 		if m.paused {
 			parallelism = int32(0)
 		}
 		job.Spec.Parallelism = &parallelism
+		job.Spec.ActiveDeadlineSeconds = utils.ActiveDeadlineSeconds(m.syncTimeout, m.paused)
 
 		runAsUser := int64(0)
+		dataVolumes, dataMounts, _ := mover.DataVolumes(dataVolumeName, mountPath, "", members, dataPVCs)
 
 		job.Spec.Template.Spec.Containers = []corev1.Container{{
-			Name: "rclone",
+			Name:                     "rclone",
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 			Env: []corev1.EnvVar{
 				{Name: "RCLONE_CONFIG", Value: "/rclone-config/rclone.conf"},
 				{Name: "RCLONE_DEST_PATH", Value: *m.rcloneDestPath},
 				{Name: "DIRECTION", Value: direction},
 				{Name: "MOUNT_PATH", Value: mountPath},
 				{Name: "RCLONE_CONFIG_SECTION", Value: *m.rcloneConfigSection},
+				{Name: "VOLUME_GROUP", Value: strconv.FormatBool(m.volumeGroup != nil)},
 			},
 			Command: []string{"/bin/bash", "-c", "./active.sh"},
 			Image:   m.containerImage,
 			SecurityContext: &corev1.SecurityContext{
 				RunAsUser: &runAsUser,
 			},
-			VolumeMounts: []corev1.VolumeMount{
-				{Name: dataVolumeName, MountPath: mountPath},
-				{Name: rcloneSecret, MountPath: "/rclone-config/"},
-			},
+			VolumeMounts: append(dataMounts,
+				corev1.VolumeMount{Name: rcloneSecret, MountPath: "/rclone-config/"},
+			),
 		}}
 		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
 		job.Spec.Template.Spec.ServiceAccountName = sa.Name
 		secretMode := int32(0600)
-		job.Spec.Template.Spec.Volumes = []corev1.Volume{
-			{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
-				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: dataPVC.Name,
-					ReadOnly:  false,
-				}},
-			},
-			{Name: rcloneSecret, VolumeSource: corev1.VolumeSource{
+		job.Spec.Template.Spec.Volumes = append(dataVolumes,
+			corev1.Volume{Name: rcloneSecret, VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
 					SecretName:  rcloneConfigSecret.Name,
 					DefaultMode: &secretMode,
 				}},
 			},
+		)
+		for _, dataPVC := range dataPVCs {
+			logger.V(1).Info("Job has PVC", "PVC", dataPVC, "DS", dataPVC.Spec.DataSource)
+		}
+		utils.ApplyMoverPodTemplate(&job.Spec.Template.Spec, m.podTemplate)
+		utils.ApplyMoverNetwork(&job.Spec.Template.Spec, m.podTemplate)
+		if job.CreationTimestamp.IsZero() {
+			// Ensure the mover lands on the same node as any Pod already using
+			// the volume. This is only done at creation since the Pod template
+			// must remain stable for the life of the job.
+			for _, dataPVC := range dataPVCs {
+				nodeName, err := utils.NodeForVolume(ctx, m.client, logger, dataPVC)
+				if err != nil {
+					return err
+				}
+				if nodeName != "" {
+					utils.AddNodeAffinity(&job.Spec.Template.Spec, nodeName)
+					break
+				}
+			}
 		}
-		logger.V(1).Info("Job has PVC", "PVC", dataPVC, "DS", dataPVC.Spec.DataSource)
 		return nil
 	})
 	// If Job had failed, delete it so it can be recreated
 	if job.Status.Failed >= *job.Spec.BackoffLimit {
 		logger.Info("deleting job -- backoff limit reached")
+		if job.DeletionTimestamp.IsZero() {
+			m.eventRecorder.Eventf(m.owner, corev1.EventTypeWarning, utils.EvRJobFailed,
+				"mover Job %s reached its backoff limit and will be restarted", job.Name)
+			m.failure = mover.FailureFromJob(ctx, m.client, logger, job)
+		}
 		err = m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
 		return nil, err
 	}
@@ -285,6 +420,81 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 	return job, nil
 }
 
+// ensureDeleteJob runs a Job that removes the rcloneDestPath. It returns the Job
+// once it has completed.
+func (m *Mover) ensureDeleteJob(ctx context.Context, sa *corev1.ServiceAccount,
+	rcloneConfigSecret *corev1.Secret) (*batchv1.Job, error) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "volsync-rclone-delete-" + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	logger := m.logger.WithValues("job", client.ObjectKeyFromObject(job))
+	_, err := ctrlutil.CreateOrUpdate(ctx, m.client, job, func() error {
+		if err := ctrl.SetControllerReference(m.owner, job, m.client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		job.Spec.Template.ObjectMeta.Name = job.Name
+		backoffLimit := int32(2)
+		job.Spec.BackoffLimit = &backoffLimit
+		runAsUser := int64(0)
+		job.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name:                     "rclone",
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			Env: []corev1.EnvVar{
+				{Name: "RCLONE_CONFIG", Value: "/rclone-config/rclone.conf"},
+				{Name: "RCLONE_DEST_PATH", Value: *m.rcloneDestPath},
+				{Name: "DIRECTION", Value: "delete"},
+				{Name: "RCLONE_CONFIG_SECTION", Value: *m.rcloneConfigSection},
+			},
+			Command: []string{"/bin/bash", "-c", "./active.sh"},
+			Image:   m.containerImage,
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser: &runAsUser,
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: rcloneSecret, MountPath: "/rclone-config/"},
+			},
+		}}
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+		job.Spec.Template.Spec.ServiceAccountName = sa.Name
+		secretMode := int32(0600)
+		job.Spec.Template.Spec.Volumes = []corev1.Volume{
+			{Name: rcloneSecret, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  rcloneConfigSecret.Name,
+					DefaultMode: &secretMode,
+				}},
+			},
+		}
+		utils.ApplyMoverPodTemplate(&job.Spec.Template.Spec, m.podTemplate)
+		utils.ApplyMoverNetwork(&job.Spec.Template.Spec, m.podTemplate)
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "reconcile failed")
+		return nil, err
+	}
+	// If Job had failed, delete it so it can be recreated
+	if job.Status.Failed >= *job.Spec.BackoffLimit {
+		logger.Info("deleting job -- backoff limit reached")
+		if job.DeletionTimestamp.IsZero() {
+			m.eventRecorder.Eventf(m.owner, corev1.EventTypeWarning, utils.EvRJobFailed,
+				"mover Job %s reached its backoff limit and will be restarted", job.Name)
+			m.failure = mover.FailureFromJob(ctx, m.client, logger, job)
+		}
+		err = m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		return nil, err
+	}
+	if job.Status.Succeeded == 0 {
+		return nil, nil
+	}
+	logger.Info("destination path removed")
+	return job, nil
+}
+
 func (m *Mover) validateSpec() error {
 	m.logger.V(1).Info("Initiate Rclone Spec validation")
 	if m.rcloneConfig == nil || len(*m.rcloneConfig) == 0 {
@@ -318,6 +528,8 @@ func (m *Mover) validateRcloneConfig(ctx context.Context) (*corev1.Secret, error
 
 	if err := utils.GetAndValidateSecret(ctx, m.client, logger, secret, "rclone.conf"); err != nil {
 		logger.Error(err, "Rclone config secret does not contain the proper fields")
+		m.eventRecorder.Eventf(m.owner, corev1.EventTypeWarning, utils.EvRSecretInvalid,
+			"rcloneConfig Secret %s is invalid: %v", secret.Name, err)
 		return nil, err
 	}
 	m.logger.Info("RcloneConfig reconciled")
@@ -33,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -116,7 +117,7 @@ var _ = Describe("Rclone init flags and env vars", func() {
 				},
 				Status: &volsyncv1alpha1.ReplicationSourceStatus{}, // Controller sets status to non-nil
 			}
-			sourceMover, err := builderForInitTests.FromSource(k8sClient, logger, rs)
+			sourceMover, err := builderForInitTests.FromSource(ctx, k8sClient, logger, &record.FakeRecorder{}, rs)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(sourceMover).NotTo(BeNil())
 			sourceRcloneMover, _ := sourceMover.(*Mover)
@@ -133,7 +134,7 @@ var _ = Describe("Rclone init flags and env vars", func() {
 				},
 				Status: &volsyncv1alpha1.ReplicationDestinationStatus{}, // Controller sets status to non-nil
 			}
-			destMover, err := builderForInitTests.FromDestination(k8sClient, logger, rd)
+			destMover, err := builderForInitTests.FromDestination(ctx, k8sClient, logger, &record.FakeRecorder{}, rd)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(destMover).NotTo(BeNil())
 			destRcloneMover, _ := destMover.(*Mover)
@@ -192,7 +193,7 @@ var _ = Describe("Rclone ignores other movers", func() {
 					Rclone: nil,
 				},
 			}
-			m, e := commonBuilderForTestSuite.FromSource(k8sClient, logger, rs)
+			m, e := commonBuilderForTestSuite.FromSource(ctx, k8sClient, logger, &record.FakeRecorder{}, rs)
 			Expect(m).To(BeNil())
 			Expect(e).NotTo(HaveOccurred())
 		})
@@ -208,7 +209,7 @@ var _ = Describe("Rclone ignores other movers", func() {
 					Rclone: nil,
 				},
 			}
-			m, e := commonBuilderForTestSuite.FromDestination(k8sClient, logger, rd)
+			m, e := commonBuilderForTestSuite.FromDestination(ctx, k8sClient, logger, &record.FakeRecorder{}, rd)
 			Expect(m).To(BeNil())
 			Expect(e).NotTo(HaveOccurred())
 		})
@@ -282,7 +283,7 @@ var _ = Describe("Rclone as a source", func() {
 			// Controller sets status to non-nil
 			rs.Status = &volsyncv1alpha1.ReplicationSourceStatus{}
 			// Instantiate a rclone mover for the tests
-			m, err := commonBuilderForTestSuite.FromSource(k8sClient, logger, rs)
+			m, err := commonBuilderForTestSuite.FromSource(ctx, k8sClient, logger, &record.FakeRecorder{}, rs)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(m).NotTo(BeNil())
 			mover, _ = m.(*Mover)
@@ -518,7 +519,7 @@ var _ = Describe("Rclone as a source", func() {
 			})
 			When("it's the initial sync", func() {
 				It("should have the command defined properly", func() {
-					j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -532,7 +533,7 @@ var _ = Describe("Rclone as a source", func() {
 				})
 
 				It("should use the specified container image", func() {
-					j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -546,7 +547,7 @@ var _ = Describe("Rclone as a source", func() {
 				})
 
 				It("should use the specified service account", func() {
-					j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -559,7 +560,7 @@ var _ = Describe("Rclone as a source", func() {
 				})
 
 				It("should have the correct env vars", func() {
-					j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -574,7 +575,7 @@ var _ = Describe("Rclone as a source", func() {
 				})
 
 				It("Should have correct volume mounts", func() {
-					j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -603,7 +604,7 @@ var _ = Describe("Rclone as a source", func() {
 				})
 
 				It("Should have correct volumes", func() {
-					j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -633,7 +634,7 @@ var _ = Describe("Rclone as a source", func() {
 				})
 
 				It("Should have correct labels", func() {
-					j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -648,7 +649,7 @@ var _ = Describe("Rclone as a source", func() {
 				})
 
 				It("should support pausing", func() {
-					j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -661,7 +662,7 @@ var _ = Describe("Rclone as a source", func() {
 
 					mover.paused = true
 					Eventually(func() int32 {
-						j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+						j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 						Expect(e).NotTo(HaveOccurred())
 						Expect(j).To(BeNil()) // hasn't completed
 						err := k8sClient.Get(ctx, nsn, job)
@@ -671,7 +672,7 @@ var _ = Describe("Rclone as a source", func() {
 
 					mover.paused = false
 					Eventually(func() int32 {
-						j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+						j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 						Expect(e).NotTo(HaveOccurred())
 						Expect(j).To(BeNil()) // hasn't completed
 						err := k8sClient.Get(ctx, nsn, job)
@@ -681,9 +682,106 @@ var _ = Describe("Rclone as a source", func() {
 				})
 			})
 
+			When("the source PVC is in use by a running Pod", func() {
+				var pod *corev1.Pod
+				JustBeforeEach(func() {
+					pod = &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "app",
+							Namespace: ns.Name,
+						},
+						Spec: corev1.PodSpec{
+							NodeName: "node-1",
+							Containers: []corev1.Container{{
+								Name:  "app",
+								Image: "app",
+							}},
+							Volumes: []corev1.Volume{{
+								Name: "data",
+								VolumeSource: corev1.VolumeSource{
+									PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+										ClaimName: sPVC.Name,
+									},
+								},
+							}},
+						},
+					}
+					Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+					pod.Status.Phase = corev1.PodRunning
+					Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+				})
+				It("should schedule the job onto the same node", func() {
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Eventually(func() error {
+						return k8sClient.Get(ctx, nsn, job)
+					}).Should(Succeed())
+					affinity := job.Spec.Template.Spec.Affinity
+					Expect(affinity).NotTo(BeNil())
+					Expect(affinity.NodeAffinity).NotTo(BeNil())
+					terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+					Expect(terms).To(HaveLen(1))
+					Expect(terms[0].MatchFields).To(ConsistOf(corev1.NodeSelectorRequirement{
+						Key:      "metadata.name",
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{"node-1"},
+					}))
+
+					// Re-reconciling should not alter the pinned node
+					j, e = mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil())
+					Expect(k8sClient.Get(ctx, nsn, job)).To(Succeed())
+					terms = job.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+					Expect(terms).To(HaveLen(1))
+					Expect(terms[0].MatchFields).To(HaveLen(1))
+				})
+			})
+
+			When("a moverPodTemplate is specified", func() {
+				BeforeEach(func() {
+					rs.Spec.MoverPodTemplate = &volsyncv1alpha1.MoverPodTemplateSpec{
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU: resource.MustParse("100m"),
+							},
+						},
+						NodeSelector: map[string]string{"storage": "true"},
+						Tolerations: []corev1.Toleration{{
+							Key:      "storage",
+							Operator: corev1.TolerationOpExists,
+							Effect:   corev1.TaintEffectNoSchedule,
+						}},
+						PriorityClassName: "mover-priority",
+					}
+				})
+				It("should be merged into the job", func() {
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Eventually(func() error {
+						return k8sClient.Get(ctx, nsn, job)
+					}).Should(Succeed())
+					podSpec := job.Spec.Template.Spec
+					Expect(podSpec.Containers[0].Resources.Requests.Cpu().String()).To(Equal("100m"))
+					Expect(podSpec.NodeSelector).To(HaveKeyWithValue("storage", "true"))
+					Expect(podSpec.Tolerations).To(HaveLen(1))
+					Expect(podSpec.Tolerations[0].Key).To(Equal("storage"))
+					Expect(podSpec.PriorityClassName).To(Equal("mover-priority"))
+					Expect(podSpec.Affinity).To(BeNil())
+				})
+			})
+
 			When("the job has failed", func() {
 				It("should be restarted", func() {
-					j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+					recorder := record.NewFakeRecorder(100)
+					mover.eventRecorder = recorder
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -697,7 +795,7 @@ var _ = Describe("Rclone as a source", func() {
 						return err
 					}, timeout, interval).Should(Succeed())
 					Eventually(func() int32 {
-						j, e := mover.ensureJob(ctx, sPVC, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
+						j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, rcloneConfigSecret) // Using sPVC as dataPVC (i.e. direct)
 						Expect(e).NotTo(HaveOccurred())
 						Expect(j).To(BeNil())
 						e = k8sClient.Get(ctx, nsn, job)
@@ -706,6 +804,9 @@ var _ = Describe("Rclone as a source", func() {
 						}
 						return job.Status.Failed
 					}, timeout, interval).Should(Equal(int32(0)))
+					Expect(recorder.Events).To(Receive(ContainSubstring(utils.EvRJobFailed)))
+					Expect(mover.failure).NotTo(BeNil())
+					Expect(mover.failure.Message).NotTo(BeEmpty())
 				})
 			})
 		})
@@ -751,7 +852,7 @@ var _ = Describe("Rclone as a destination", func() {
 			// Controller sets status to non-nil
 			rd.Status = &volsyncv1alpha1.ReplicationDestinationStatus{}
 			// Instantiate a restic mover for the tests
-			m, err := commonBuilderForTestSuite.FromDestination(k8sClient, logger, rd)
+			m, err := commonBuilderForTestSuite.FromDestination(ctx, k8sClient, logger, &record.FakeRecorder{}, rd)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(m).NotTo(BeNil())
 			mover, _ = m.(*Mover)
@@ -884,7 +985,7 @@ var _ = Describe("Rclone as a destination", func() {
 			})
 			When("it's the initial sync", func() {
 				It("should have the correct env vars", func() {
-					j, e := mover.ensureJob(ctx, dPVC, sa, rcloneConfigSecret)
+					j, e := mover.ensureJob(ctx, nil, []*corev1.PersistentVolumeClaim{dPVC}, sa, rcloneConfigSecret)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -18,11 +18,13 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package restic
 
 import (
+	"context"
 	"flag"
 	"fmt"
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/viper"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
@@ -85,7 +87,8 @@ func (rb *Builder) getResticContainerImage() string {
 	return rb.viper.GetString(resticContainerImageFlag)
 }
 
-func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
+func (rb *Builder) FromSource(ctx context.Context, client client.Client, logger logr.Logger,
+	eventRecorder record.EventRecorder,
 	source *volsyncv1alpha1.ReplicationSource) (mover.Mover, error) {
 	// Only build if the CR belongs to us
 	if source.Spec.Restic == nil {
@@ -99,41 +102,71 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 
 	vh, err := volumehandler.NewVolumeHandler(
 		volumehandler.WithClient(client),
+		volumehandler.WithRecorder(eventRecorder),
 		volumehandler.WithOwner(source),
 		volumehandler.FromSource(&source.Spec.Restic.ReplicationSourceVolumeOptions),
+		volumehandler.WithHooks(source.Spec.Hooks),
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	containerImage, err := mover.ContainerImage(ctx, client, source, source.Spec.MoverPodTemplate,
+		"restic", rb.getResticContainerImage())
+	if err != nil {
+		return nil, err
+	}
+
 	return &Mover{
 		client:                client,
+		eventRecorder:         eventRecorder,
 		logger:                logger.WithValues("method", "Restic"),
 		owner:                 source,
+		podTemplate:           source.Spec.MoverPodTemplate,
 		vh:                    vh,
-		containerImage:        rb.getResticContainerImage(),
+		containerImage:        containerImage,
 		cacheAccessModes:      source.Spec.Restic.CacheAccessModes,
 		cacheCapacity:         source.Spec.Restic.CacheCapacity,
 		cacheStorageClassName: source.Spec.Restic.CacheStorageClassName,
 		repositoryName:        source.Spec.Restic.Repository,
+		secretKeys:            source.Spec.Restic.SecretKeys,
+		identity:              source.Spec.Restic.Identity,
 		isSource:              true,
 		paused:                source.Spec.Paused,
+		retryPolicy:           source.Spec.RetryPolicy,
+		syncTimeout:           source.Spec.SyncTimeout,
+		unlockStaleAfter:      source.Spec.Restic.UnlockStaleLocksAfter,
+		listSnapshots:         source.Spec.Restic.ListSnapshots,
+		hostname:              source.Spec.Restic.Hostname,
+		tags:                  source.Spec.Restic.Tags,
 		mainPVCName:           &source.Spec.SourcePVC,
+		volumeGroup:           source.Spec.SourceVolumeGroup,
 		pruneInterval:         source.Spec.Restic.PruneIntervalDays,
+		checkInterval:         source.Spec.Restic.CheckIntervalDays,
+		checkReadDataSubset:   source.Spec.Restic.CheckReadDataSubset,
 		retainPolicy:          source.Spec.Restic.Retain,
+		exclude:               source.Spec.Restic.Exclude,
+		excludeIfPresent:      source.Spec.Restic.ExcludeIfPresent,
 		sourceStatus:          source.Status.Restic,
 	}, nil
 }
 
-func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
+func (rb *Builder) FromDestination(ctx context.Context, client client.Client, logger logr.Logger,
+	eventRecorder record.EventRecorder,
 	destination *volsyncv1alpha1.ReplicationDestination) (mover.Mover, error) {
 	// Only build if the CR belongs to us
 	if destination.Spec.Restic == nil {
 		return nil, nil
 	}
 
+	// Create ReplicationDestinationResticStatus to write restic status
+	if destination.Status.Restic == nil {
+		destination.Status.Restic = &volsyncv1alpha1.ReplicationDestinationResticStatus{}
+	}
+
 	vh, err := volumehandler.NewVolumeHandler(
 		volumehandler.WithClient(client),
+		volumehandler.WithRecorder(eventRecorder),
 		volumehandler.WithOwner(destination),
 		volumehandler.FromDestination(&destination.Spec.Restic.ReplicationDestinationVolumeOptions),
 	)
@@ -141,20 +174,38 @@ func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
 		return nil, err
 	}
 
+	containerImage, err := mover.ContainerImage(ctx, client, destination, destination.Spec.MoverPodTemplate,
+		"restic", rb.getResticContainerImage())
+	if err != nil {
+		return nil, err
+	}
+
 	return &Mover{
 		client:                client,
+		eventRecorder:         eventRecorder,
 		logger:                logger.WithValues("method", "Restic"),
 		owner:                 destination,
+		podTemplate:           destination.Spec.MoverPodTemplate,
 		vh:                    vh,
-		containerImage:        rb.getResticContainerImage(),
+		containerImage:        containerImage,
 		cacheAccessModes:      destination.Spec.Restic.CacheAccessModes,
 		cacheCapacity:         destination.Spec.Restic.CacheCapacity,
 		cacheStorageClassName: destination.Spec.Restic.CacheStorageClassName,
 		repositoryName:        destination.Spec.Restic.Repository,
+		secretKeys:            destination.Spec.Restic.SecretKeys,
+		identity:              destination.Spec.Restic.Identity,
 		isSource:              false,
 		paused:                destination.Spec.Paused,
+		retryPolicy:           destination.Spec.RetryPolicy,
 		mainPVCName:           destination.Spec.Restic.DestinationPVC,
+		volumeGroup:           destination.Spec.VolumeGroup,
 		restoreAsOf:           destination.Spec.Restic.RestoreAsOf,
 		previous:              destination.Spec.Restic.Previous,
+		include:               destination.Spec.Restic.Include,
+		unlockStaleAfter:      destination.Spec.Restic.UnlockStaleLocksAfter,
+		listSnapshots:         destination.Spec.Restic.ListSnapshots,
+		hostname:              destination.Spec.Restic.Hostname,
+		tags:                  destination.Spec.Restic.Tags,
+		destinationStatus:     destination.Status.Restic,
 	}, nil
 }
@@ -20,15 +20,21 @@ package restic
 import (
 	"context"
 	"fmt"
+	"path"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -42,35 +48,97 @@ import (
 const (
 	resticCacheMountPath = "/cache"
 	mountPath            = "/data"
+	devicePath           = "/dev/block"
 	dataVolumeName       = "data"
 	resticCache          = "cache"
+	checkResultFailed    = "Failed"
+	// defaultListSnapshots is the number of snapshots listed in the status if
+	// listSnapshots isn't specified
+	defaultListSnapshots = 10
+	// lockedExitCode is the mover's exit code when the repository is locked
+	lockedExitCode = 4
+	// legacyResticHost is the hostname of the snapshots of objects that don't
+	// specify one. It was used for all snapshots by prior versions.
+	legacyResticHost = "volsync"
+	// The repository Secret is mounted so its keys are also available as files
+	credentialsVolumeName = "credentials"
+	credentialsMountPath  = "/credentials"
+	// The projected ServiceAccount token for cloud identity
+	identityVolumeName            = "identity"
+	identityMountPath             = "/var/run/secrets/volsync/identity"
+	identityTokenFile             = "token"
+	defaultTokenExpirationSeconds = int64(3600)
 )
 
+// envKeyRegexp matches the repository Secret keys that can be passed to restic
+// as environment variables
+var envKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 // Mover is the reconciliation logic for the Restic-based data mover.
 type Mover struct {
 	client                client.Client
 	logger                logr.Logger
-	owner                 metav1.Object
+	eventRecorder         record.EventRecorder
+	owner                 client.Object
 	vh                    *volumehandler.VolumeHandler
 	containerImage        string
 	cacheAccessModes      []corev1.PersistentVolumeAccessMode
 	cacheCapacity         *resource.Quantity
 	cacheStorageClassName *string
 	repositoryName        string
+	secretKeys            *volsyncv1alpha1.ResticSecretKeysSpec
+	identity              *volsyncv1alpha1.ResticIdentitySpec
 	isSource              bool
 	paused                bool
+	syncTimeout           *metav1.Duration
+	retryPolicy           *volsyncv1alpha1.RetryPolicySpec
+	unlockStaleAfter      *metav1.Duration
+	listSnapshots         *int32
+	hostname              string
+	tags                  []string
 	mainPVCName           *string
+	volumeGroup           *volsyncv1alpha1.VolumeGroupSpec
+	podTemplate           *volsyncv1alpha1.MoverPodTemplateSpec
+	// failure is set when the mover Job fails during this reconcile
+	failure *mover.Failure
 	// Source-only fields
-	pruneInterval *int32
-	retainPolicy  *volsyncv1alpha1.ResticRetainPolicy
-	sourceStatus  *volsyncv1alpha1.ReplicationSourceResticStatus
+	pruneInterval       *int32
+	checkInterval       *int32
+	checkReadDataSubset string
+	retainPolicy        *volsyncv1alpha1.ResticRetainPolicy
+	exclude             []string
+	excludeIfPresent    []string
+	sourceStatus        *volsyncv1alpha1.ReplicationSourceResticStatus
 	// Destination-only fields
-	previous    *int32
-	restoreAsOf *string
+	previous          *int32
+	restoreAsOf       *string
+	include           []string
+	destinationStatus *volsyncv1alpha1.ReplicationDestinationResticStatus
 }
 
 var _ mover.Mover = &Mover{}
 
+// moverReport holds the results of the restic operations beyond the transfer
+// statistics, as reported by the mover container in its termination message
+type moverReport struct {
+	CheckResult  string            `json:"checkResult"`
+	CheckMessage string            `json:"checkMessage"`
+	Snapshots    []snapshotReport  `json:"snapshots"`
+	Repository   *repositoryReport `json:"repository"`
+}
+
+type snapshotReport struct {
+	ID    string    `json:"id"`
+	Time  time.Time `json:"time"`
+	Size  *int64    `json:"size"`
+	Paths []string  `json:"paths"`
+}
+
+type repositoryReport struct {
+	SnapshotCount int32  `json:"snapshotCount"`
+	TotalSize     *int64 `json:"totalSize"`
+}
+
 // All object types that are temporary/per-iteration should be listed here. The
 // individual objects to be cleaned up must also be marked.
 var cleanupTypes = []client.Object{
@@ -81,21 +149,33 @@ var cleanupTypes = []client.Object{
 
 func (m *Mover) Name() string { return "restic" }
 
+func (m *Mover) VersionInfo() string {
+	return fmt.Sprintf("Restic container: %s", m.containerImage)
+}
+
 func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 	var err error
-	// Allocate temporary data PVC
-	var dataPVC *corev1.PersistentVolumeClaim
+	// Allocate temporary data PVC(s)
+	var members []string
+	var dataPVCs []*corev1.PersistentVolumeClaim
 	if m.isSource {
-		dataPVC, err = m.ensureSourcePVC(ctx)
+		members, dataPVCs, err = m.ensureSourcePVCs(ctx)
 	} else {
-		dataPVC, err = m.ensureDestinationPVC(ctx)
+		members, dataPVCs, err = m.ensureDestinationPVCs(ctx)
 	}
-	if dataPVC == nil || err != nil {
+	if dataPVCs == nil || err != nil {
 		return mover.InProgress(), err
 	}
+	if m.volumeGroup != nil {
+		for _, dataPVC := range dataPVCs {
+			if mover.IsBlockVolume(dataPVC) {
+				return mover.InProgress(), fmt.Errorf("volume group member %s is a Block volume, which is not supported", dataPVC.Name)
+			}
+		}
+	}
 
 	// Allocate cache volume
-	cachePVC, err := m.ensureCache(ctx, dataPVC)
+	cachePVC, err := m.ensureCache(ctx, dataPVCs[0])
 	if cachePVC == nil || err != nil {
 		return mover.InProgress(), err
 	}
@@ -113,22 +193,41 @@ func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 	}
 
 	// Start mover Job
-	job, err := m.ensureJob(ctx, cachePVC, dataPVC, sa, repo)
+	job, err := m.ensureJob(ctx, cachePVC, members, dataPVCs, sa, repo)
+	if m.failure != nil {
+		return mover.Failed(m.failure), err
+	}
 	if job == nil || err != nil {
 		return mover.InProgress(), err
 	}
 
 	// On the destination, preserve the image and return it
+	var result mover.Result
 	if !m.isSource {
-		image, err := m.vh.EnsureImage(ctx, m.logger, dataPVC)
-		if image == nil || err != nil {
-			return mover.InProgress(), err
+		images := []*corev1.TypedLocalObjectReference{}
+		for _, dataPVC := range dataPVCs {
+			image, err := m.vh.EnsureImage(ctx, m.logger, dataPVC)
+			if image == nil || err != nil {
+				return mover.InProgress(), err
+			}
+			images = append(images, image)
 		}
-		return mover.CompleteWithImage(image), nil
+		if m.volumeGroup != nil {
+			result = mover.CompleteWithVolumeGroup(mover.DestinationVolumeGroupStatus(members, images))
+		} else {
+			result = mover.CompleteWithImage(images[0])
+		}
+	} else if m.volumeGroup != nil {
+		result = mover.CompleteWithVolumeGroup(mover.SourceVolumeGroupStatus(members, dataPVCs))
+	} else {
+		// On the source, just signal completion
+		result = mover.Complete()
 	}
-
-	// On the source, just signal completion
-	return mover.Complete(), nil
+	// Only backups transfer data that is worth reporting
+	if m.isSource {
+		result.Stats = mover.StatsFromJob(ctx, m.client, m.logger, job)
+	}
+	return result, nil
 }
 
 func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
@@ -137,9 +236,15 @@ func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
 		// Cleanup the snapshot annotation on pvc for replicationDestination scenario so that
 		// on the next sync (if snapshot CopyMethod is being used) a new snapshot will be created rather than re-using
 		_, destPVCName := m.getDestinationPVCName()
-		err := m.vh.RemoveSnapshotAnnotationFromPVC(ctx, m.logger, destPVCName)
-		if err != nil {
-			return mover.InProgress(), err
+		destPVCNames := []string{destPVCName}
+		if m.volumeGroup != nil {
+			destPVCNames = m.volumeGroup.PVCNames
+		}
+		for _, name := range destPVCNames {
+			err := m.vh.RemoveSnapshotAnnotationFromPVC(ctx, m.logger, name)
+			if err != nil {
+				return mover.InProgress(), err
+			}
 		}
 	}
 
@@ -150,6 +255,51 @@ func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
 	return mover.Complete(), nil
 }
 
+func (m *Mover) Delete(ctx context.Context) (mover.Result, error) {
+	// A restore doesn't store anything in the repository
+	if !m.isSource {
+		return mover.Complete(), nil
+	}
+	// Every object without a hostname backs up as the legacy host, so its
+	// snapshots can only be told apart from those of other objects by tags
+	if m.resticHost() == legacyResticHost && len(m.tags) == 0 {
+		m.logger.Info("backups use the shared legacy hostname without tags, they will not be removed")
+		m.eventRecorder.Eventf(m.owner, corev1.EventTypeWarning, utils.EvRDataRetained,
+			"backups were not removed since the hostname %s may be shared with other objects", legacyResticHost)
+		return mover.Complete(), nil
+	}
+
+	// Stop any backup that is in progress so that it doesn't hold a lock on
+	// the repository
+	if err := utils.CleanupObjects(ctx, m.client, m.logger, m.owner, cleanupTypes); err != nil {
+		return mover.InProgress(), err
+	}
+
+	repo, err := m.validateRepository(ctx)
+	if kerrors.IsNotFound(err) {
+		// Without the repository Secret, there's no way to reach the backups
+		m.logger.Info("repository Secret not found, backups will not be removed")
+		return mover.Complete(), nil
+	}
+	if repo == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	sa, err := m.ensureSA(ctx)
+	if sa == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	job, err := m.ensureDeleteJob(ctx, sa, repo)
+	if m.failure != nil {
+		return mover.Failed(m.failure), err
+	}
+	if job == nil || err != nil {
+		return mover.InProgress(), err
+	}
+	return mover.Complete(), nil
+}
+
 func (m *Mover) ensureCache(ctx context.Context,
 	dataPVC *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
 	// Create a separate vh for the Restic cache volume that's based on the main
@@ -157,6 +307,9 @@ func (m *Mover) ensureCache(ctx context.Context,
 	cacheConfig := []volumehandler.VHOption{
 		// build on the datavolume's configuration
 		volumehandler.From(m.vh),
+		// the cache is always a filesystem, even when the data is a Block
+		// volume
+		volumehandler.VolumeMode(nil),
 	}
 
 	// Cache capacity defaults to 1Gi but can be overridden
@@ -205,6 +358,37 @@ func (m *Mover) ensureSourcePVC(ctx context.Context) (*corev1.PersistentVolumeCl
 	return m.vh.EnsurePVCFromSrc(ctx, m.logger, srcPVC, dataName, true)
 }
 
+// ensureSourcePVCs returns the PVCs to be backed up. For a volume group, it also
+// returns the names of the members that the PVCs correspond to.
+func (m *Mover) ensureSourcePVCs(ctx context.Context) ([]string, []*corev1.PersistentVolumeClaim, error) {
+	if m.volumeGroup != nil {
+		dataPrefix := "volsync-" + m.owner.GetName() + "-src"
+		return m.vh.EnsureVolumeGroupFromSrc(ctx, m.logger, m.volumeGroup, dataPrefix, true)
+	}
+	dataPVC, err := m.ensureSourcePVC(ctx)
+	if dataPVC == nil || err != nil {
+		return nil, nil, err
+	}
+	return nil, []*corev1.PersistentVolumeClaim{dataPVC}, nil
+}
+
+// ensureDestinationPVCs returns the PVCs to restore into. For a volume group,
+// it also returns the names of the members that the PVCs correspond to.
+func (m *Mover) ensureDestinationPVCs(ctx context.Context) ([]string, []*corev1.PersistentVolumeClaim, error) {
+	if m.volumeGroup != nil {
+		dataPVCs, err := m.vh.EnsureVolumeGroupPVCs(ctx, m.logger, m.volumeGroup)
+		if err != nil {
+			return nil, nil, err
+		}
+		return m.volumeGroup.PVCNames, dataPVCs, nil
+	}
+	dataPVC, err := m.ensureDestinationPVC(ctx)
+	if dataPVC == nil || err != nil {
+		return nil, nil, err
+	}
+	return nil, []*corev1.PersistentVolumeClaim{dataPVC}, nil
+}
+
 func (m *Mover) ensureDestinationPVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
 	isProvidedPVC, dataPVCName := m.getDestinationPVCName()
 	if isProvidedPVC {
@@ -234,6 +418,9 @@ func (m *Mover) ensureSA(ctx context.Context) (*corev1.ServiceAccount, error) {
 		},
 	}
 	saDesc := utils.NewSAHandler(ctx, m.client, m.owner, sa)
+	if m.identity != nil {
+		saDesc.Annotations = m.identity.ServiceAccountAnnotations
+	}
 	cont, err := saDesc.Reconcile(m.logger)
 	if cont {
 		return sa, err
@@ -252,14 +439,16 @@ func (m *Mover) validateRepository(ctx context.Context) (*corev1.Secret, error)
 	if err := utils.GetAndValidateSecret(ctx, m.client, logger, secret,
 		"RESTIC_REPOSITORY", "RESTIC_PASSWORD"); err != nil {
 		logger.Error(err, "Restic config secret does not contain the proper fields")
+		m.eventRecorder.Eventf(m.owner, corev1.EventTypeWarning, utils.EvRSecretInvalid,
+			"repository Secret %s is invalid: %v", secret.Name, err)
 		return nil, err
 	}
 	return secret, nil
 }
 
 //nolint:funlen
-func (m *Mover) ensureJob(ctx context.Context, cachePVC *corev1.PersistentVolumeClaim,
-	dataPVC *corev1.PersistentVolumeClaim, sa *corev1.ServiceAccount, repo *corev1.Secret) (*batchv1.Job, error) {
+func (m *Mover) ensureJob(ctx context.Context, cachePVC *corev1.PersistentVolumeClaim, members []string,
+	dataPVCs []*corev1.PersistentVolumeClaim, sa *corev1.ServiceAccount, repo *corev1.Secret) (*batchv1.Job, error) {
 	dir := "src"
 	if !m.isSource {
 		dir = "dst"
@@ -279,13 +468,13 @@ func (m *Mover) ensureJob(ctx context.Context, cachePVC *corev1.PersistentVolume
 		}
 		utils.MarkForCleanup(m.owner, job)
 		job.Spec.Template.ObjectMeta.Name = job.Name
-		backoffLimit := int32(8)
-		job.Spec.BackoffLimit = &backoffLimit
+		job.Spec.BackoffLimit = utils.JobBackoffLimit(8, m.retryPolicy)
 		parallelism := int32(1)
 		if m.paused {
 			parallelism = int32(0)
 		}
 		job.Spec.Parallelism = &parallelism
+		job.Spec.ActiveDeadlineSeconds = utils.ActiveDeadlineSeconds(m.syncTimeout, m.paused)
 		forgetOptions := generateForgetOptions(m.retainPolicy)
 		runAsUser := int64(0)
 		// set default values
@@ -298,6 +487,9 @@ func (m *Mover) ensureJob(ctx context.Context, cachePVC *corev1.PersistentVolume
 			if m.shouldPrune(time.Now()) {
 				actions = append(actions, "prune")
 			}
+			if m.shouldCheck(time.Now()) {
+				actions = append(actions, "check")
+			}
 		} else {
 			actions = []string{"restore"}
 			// set the restore selection options when the mover has them
@@ -309,85 +501,81 @@ func (m *Mover) ensureJob(ctx context.Context, cachePVC *corev1.PersistentVolume
 			}
 		}
 		logger.Info("job actions", "actions", actions)
+		dataVolumes, dataMounts, dataDevices := mover.DataVolumes(dataVolumeName, mountPath, devicePath, members, dataPVCs)
+		blockDevice := ""
+		if len(dataDevices) > 0 {
+			blockDevice = devicePath
+		}
+		credentialKeys := m.credentialKeys(repo, job)
+		credentialVolumes, credentialMounts := m.credentialVolumes(repo.Name, credentialKeys)
 
 		job.Spec.Template.Spec.Containers = []corev1.Container{{
-			Name: "restic",
-			Env: []corev1.EnvVar{
+			Name:                     "restic",
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			Env: m.repositoryEnv(repo.Name, credentialKeys, []corev1.EnvVar{
 				{Name: "FORGET_OPTIONS", Value: forgetOptions},
 				{Name: "DATA_DIR", Value: mountPath},
+				{Name: "BLOCK_DEVICE", Value: blockDevice},
 				{Name: "RESTIC_CACHE_DIR", Value: resticCacheMountPath},
 				{Name: "RESTORE_AS_OF", Value: restoreAsOf},
 				{Name: "SELECT_PREVIOUS", Value: previous},
-				// We populate environment variables from the restic repo
-				// Secret. They are taken 1-for-1 from the Secret into env vars.
-				// The allowed variables are defined by restic.
-				// https://restic.readthedocs.io/en/stable/040_backup.html#environment-variables
-				// Mandatory variables are needed to define the repository
-				// location and its password.
-				utils.EnvFromSecret(repo.Name, "RESTIC_REPOSITORY", false),
-				utils.EnvFromSecret(repo.Name, "RESTIC_PASSWORD", false),
-				// Optional variables based on what backend is used for restic
-				utils.EnvFromSecret(repo.Name, "AWS_ACCESS_KEY_ID", true),
-				utils.EnvFromSecret(repo.Name, "AWS_SECRET_ACCESS_KEY", true),
-				utils.EnvFromSecret(repo.Name, "AWS_DEFAULT_REGION", true),
-				utils.EnvFromSecret(repo.Name, "ST_AUTH", true),
-				utils.EnvFromSecret(repo.Name, "ST_USER", true),
-				utils.EnvFromSecret(repo.Name, "ST_KEY", true),
-				utils.EnvFromSecret(repo.Name, "OS_AUTH_URL", true),
-				utils.EnvFromSecret(repo.Name, "OS_REGION_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_USERNAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_USER_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_PASSWORD", true),
-				utils.EnvFromSecret(repo.Name, "OS_TENANT_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_TENANT_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_USER_DOMAIN_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_USER_DOMAIN_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_PROJECT_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_PROJECT_DOMAIN_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_PROJECT_DOMAIN_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_TRUST_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_APPLICATION_CREDENTIAL_ID", true),
-				utils.EnvFromSecret(repo.Name, "OS_APPLICATION_CREDENTIAL_NAME", true),
-				utils.EnvFromSecret(repo.Name, "OS_APPLICATION_CREDENTIAL_SECRET", true),
-				utils.EnvFromSecret(repo.Name, "OS_STORAGE_URL", true),
-				utils.EnvFromSecret(repo.Name, "OS_AUTH_TOKEN", true),
-				utils.EnvFromSecret(repo.Name, "B2_ACCOUNT_ID", true),
-				utils.EnvFromSecret(repo.Name, "B2_ACCOUNT_KEY", true),
-				utils.EnvFromSecret(repo.Name, "AZURE_ACCOUNT_NAME", true),
-				utils.EnvFromSecret(repo.Name, "AZURE_ACCOUNT_KEY", true),
-				utils.EnvFromSecret(repo.Name, "GOOGLE_PROJECT_ID", true),
-				utils.EnvFromSecret(repo.Name, "GOOGLE_APPLICATION_CREDENTIALS", true),
-			},
+				{Name: "CHECK_READ_DATA_SUBSET", Value: m.checkReadDataSubset},
+				{Name: "UNLOCK_STALE_AFTER", Value: staleLockSeconds(m.unlockStaleAfter)},
+				{Name: "SNAPSHOT_LIMIT", Value: strconv.Itoa(int(m.snapshotLimit()))},
+				// Patterns are passed one per line
+				{Name: "EXCLUDE", Value: strings.Join(excludePatterns(m.exclude), "\n")},
+				{Name: "EXCLUDE_IF_PRESENT", Value: strings.Join(m.excludeIfPresent, "\n")},
+				{Name: "INCLUDE", Value: strings.Join(m.include, "\n")},
+				{Name: "RESTIC_HOST", Value: m.resticHost()},
+				{Name: "RESTIC_TAGS", Value: strings.Join(m.tags, ",")},
+			}),
 			Command: []string{"/entry.sh"},
 			Args:    actions,
 			Image:   m.containerImage,
 			SecurityContext: &corev1.SecurityContext{
 				RunAsUser: &runAsUser,
 			},
-			VolumeMounts: []corev1.VolumeMount{
-				{Name: dataVolumeName, MountPath: mountPath},
-				{Name: resticCache, MountPath: resticCacheMountPath},
-			},
+			VolumeMounts: append(append(dataMounts,
+				corev1.VolumeMount{Name: resticCache, MountPath: resticCacheMountPath}),
+				credentialMounts...),
+			VolumeDevices: dataDevices,
 		}}
 		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
 		job.Spec.Template.Spec.ServiceAccountName = sa.Name
-		job.Spec.Template.Spec.Volumes = []corev1.Volume{
-			{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
-				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: dataPVC.Name,
-				}},
-			},
-			{Name: resticCache, VolumeSource: corev1.VolumeSource{
+		job.Spec.Template.Spec.Volumes = append(append(dataVolumes,
+			corev1.Volume{Name: resticCache, VolumeSource: corev1.VolumeSource{
 				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
 					ClaimName: cachePVC.Name,
 				}},
-			},
+			}),
+			credentialVolumes...)
+		utils.ApplyMoverPodTemplate(&job.Spec.Template.Spec, m.podTemplate)
+		utils.ApplyMoverNetwork(&job.Spec.Template.Spec, m.podTemplate)
+		if job.CreationTimestamp.IsZero() {
+			// Ensure the mover lands on the same node as any Pod already using
+			// the volume. This is only done at creation since the Pod template
+			// must remain stable for the life of the job.
+			for _, dataPVC := range dataPVCs {
+				nodeName, err := utils.NodeForVolume(ctx, m.client, logger, dataPVC)
+				if err != nil {
+					return err
+				}
+				if nodeName != "" {
+					utils.AddNodeAffinity(&job.Spec.Template.Spec, nodeName)
+					break
+				}
+			}
 		}
 		return nil
 	})
 	// If Job had failed, delete it so it can be recreated
 	if job.Status.Failed >= *job.Spec.BackoffLimit {
 		logger.Info("deleting job -- backoff limit reached")
+		if job.DeletionTimestamp.IsZero() {
+			m.eventRecorder.Eventf(m.owner, corev1.EventTypeWarning, utils.EvRJobFailed,
+				"mover Job %s reached its backoff limit and will be restarted", job.Name)
+			m.failure = m.failureFromJob(ctx, logger, job)
+		}
 		err = m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
 		return nil, err
 	}
@@ -401,15 +589,276 @@ func (m *Mover) ensureJob(ctx context.Context, cachePVC *corev1.PersistentVolume
 	}
 
 	logger.Info("job completed")
+	report := moverReport{}
+	mover.ReportFromJob(ctx, m.client, logger, job, &report)
 	if m.isSource && m.shouldPrune(time.Now()) {
 		now := metav1.Now()
 		m.sourceStatus.LastPruned = &now
 		logger.Info("prune completed", ".Status.Restic.LastPruned", m.sourceStatus.LastPruned)
 	}
+	if m.isSource && m.shouldCheck(time.Now()) {
+		m.recordCheck(&report)
+	}
+	m.recordInventory(&report)
 	// We only continue reconciling if the restic job has completed
 	return job, nil
 }
 
+// credentialKeys returns the keys of the repository Secret that are passed to
+// the mover, as permitted by secretKeys. They are chosen when the Job is
+// created and then taken from its template, since the template of a Job can't
+// be changed if keys are added to or removed from the Secret later.
+func (m *Mover) credentialKeys(repo *corev1.Secret, job *batchv1.Job) []string {
+	if !job.CreationTimestamp.IsZero() {
+		keys := []string{}
+		found := false
+		for _, volume := range job.Spec.Template.Spec.Volumes {
+			if volume.Name == credentialsVolumeName && volume.Secret != nil {
+				found = true
+				for _, item := range volume.Secret.Items {
+					keys = append(keys, item.Key)
+				}
+			}
+		}
+		// A Job that mounts the whole Secret predates the projection of
+		// individual keys. Its template can't be kept, so it is recreated.
+		if !found || len(keys) > 0 {
+			return keys
+		}
+	}
+	keys := make([]string, 0, len(repo.Data))
+	for key := range repo.Data {
+		if m.secretKeyAllowed(key) {
+			keys = append(keys, key)
+		}
+	}
+	// Keep the Pod template stable
+	sort.Strings(keys)
+	return keys
+}
+
+// repositoryEnv returns the mover's environment: the variables set by VolSync
+// followed by those that configure restic's access to the repository. The
+// latter are taken 1-for-1 from the keys of the repository Secret, secretName.
+// Keys that aren't valid variable names or that would override the mover's
+// own variables are skipped.
+// https://restic.readthedocs.io/en/stable/040_backup.html#environment-variables
+func (m *Mover) repositoryEnv(secretName string, keys []string, env []corev1.EnvVar) []corev1.EnvVar {
+	if m.identity != nil && m.identity.TokenAudience != "" {
+		tokenPath := path.Join(identityMountPath, identityTokenFile)
+		env = append(env,
+			corev1.EnvVar{Name: "AWS_WEB_IDENTITY_TOKEN_FILE", Value: tokenPath},
+			corev1.EnvVar{Name: "AZURE_FEDERATED_TOKEN_FILE", Value: tokenPath},
+		)
+	}
+	reserved := map[string]bool{}
+	for _, e := range env {
+		reserved[e.Name] = true
+	}
+	// Mandatory variables are needed to define the repository location and
+	// its password
+	env = append(env,
+		utils.EnvFromSecret(secretName, "RESTIC_REPOSITORY", false),
+		utils.EnvFromSecret(secretName, "RESTIC_PASSWORD", false),
+	)
+	for _, key := range keys {
+		if key == "RESTIC_REPOSITORY" || key == "RESTIC_PASSWORD" || reserved[key] ||
+			!envKeyRegexp.MatchString(key) {
+			continue
+		}
+		env = append(env, utils.EnvFromSecret(secretName, key, true))
+	}
+	return env
+}
+
+// secretKeyAllowed determines whether the repository Secret's key may be
+// passed to restic
+func (m *Mover) secretKeyAllowed(key string) bool {
+	if m.secretKeys == nil {
+		return true
+	}
+	for _, pattern := range m.secretKeys.Deny {
+		if matched, _ := path.Match(pattern, key); matched {
+			return false
+		}
+	}
+	if len(m.secretKeys.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range m.secretKeys.Allow {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialVolumes returns the volumes that provide the mover's credentials:
+// the keys of the repository Secret, secretName, which allows file-type
+// credentials (e.g., a Google Cloud service account key) to be used, and the
+// projected ServiceAccount token when an identity is configured
+func (m *Mover) credentialVolumes(secretName string, keys []string) ([]corev1.Volume, []corev1.VolumeMount) {
+	volumes := []corev1.Volume{}
+	mounts := []corev1.VolumeMount{}
+	// A Secret volume without items would project all of the Secret's keys
+	if len(keys) > 0 {
+		items := make([]corev1.KeyToPath, 0, len(keys))
+		for _, key := range keys {
+			items = append(items, corev1.KeyToPath{Key: key, Path: key})
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: credentialsVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: secretName, Items: items},
+			},
+		})
+		mounts = append(mounts,
+			corev1.VolumeMount{Name: credentialsVolumeName, MountPath: credentialsMountPath, ReadOnly: true})
+	}
+	if m.identity != nil && m.identity.TokenAudience != "" {
+		expirationSeconds := defaultTokenExpirationSeconds
+		if m.identity.TokenExpirationSeconds != nil {
+			expirationSeconds = *m.identity.TokenExpirationSeconds
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: identityVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          m.identity.TokenAudience,
+							ExpirationSeconds: &expirationSeconds,
+							Path:              identityTokenFile,
+						},
+					}},
+				},
+			},
+		})
+		mounts = append(mounts,
+			corev1.VolumeMount{Name: identityVolumeName, MountPath: identityMountPath, ReadOnly: true})
+	}
+	return volumes, mounts
+}
+
+// ensureDeleteJob runs a Job that removes this object's backups from the
+// repository. It returns the Job once it has completed.
+func (m *Mover) ensureDeleteJob(ctx context.Context, sa *corev1.ServiceAccount,
+	repo *corev1.Secret) (*batchv1.Job, error) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "volsync-delete-" + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	logger := m.logger.WithValues("job", client.ObjectKeyFromObject(job))
+	_, err := ctrlutil.CreateOrUpdate(ctx, m.client, job, func() error {
+		if err := ctrl.SetControllerReference(m.owner, job, m.client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		job.Spec.Template.ObjectMeta.Name = job.Name
+		backoffLimit := int32(8)
+		job.Spec.BackoffLimit = &backoffLimit
+		runAsUser := int64(0)
+		credentialKeys := m.credentialKeys(repo, job)
+		credentialVolumes, credentialMounts := m.credentialVolumes(repo.Name, credentialKeys)
+		job.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name:                     "restic",
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			Env: m.repositoryEnv(repo.Name, credentialKeys, []corev1.EnvVar{
+				{Name: "DATA_DIR", Value: mountPath},
+				{Name: "RESTIC_CACHE_DIR", Value: resticCacheMountPath},
+				{Name: "UNLOCK_STALE_AFTER", Value: staleLockSeconds(m.unlockStaleAfter)},
+				{Name: "RESTIC_HOST", Value: m.resticHost()},
+				{Name: "RESTIC_TAGS", Value: strings.Join(m.tags, ",")},
+			}),
+			Command: []string{"/entry.sh"},
+			Args:    []string{"delete"},
+			Image:   m.containerImage,
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser: &runAsUser,
+			},
+			VolumeMounts: append([]corev1.VolumeMount{
+				{Name: resticCache, MountPath: resticCacheMountPath},
+			}, credentialMounts...),
+		}}
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+		job.Spec.Template.Spec.ServiceAccountName = sa.Name
+		// The persistent cache isn't needed to remove the backups
+		job.Spec.Template.Spec.Volumes = append([]corev1.Volume{
+			{Name: resticCache, VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			}},
+		}, credentialVolumes...)
+		utils.ApplyMoverPodTemplate(&job.Spec.Template.Spec, m.podTemplate)
+		utils.ApplyMoverNetwork(&job.Spec.Template.Spec, m.podTemplate)
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "reconcile failed")
+		return nil, err
+	}
+	// If Job had failed, delete it so it can be recreated
+	if job.Status.Failed >= *job.Spec.BackoffLimit {
+		logger.Info("deleting job -- backoff limit reached")
+		if job.DeletionTimestamp.IsZero() {
+			m.eventRecorder.Eventf(m.owner, corev1.EventTypeWarning, utils.EvRJobFailed,
+				"mover Job %s reached its backoff limit and will be restarted", job.Name)
+			m.failure = m.failureFromJob(ctx, logger, job)
+		}
+		err = m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		return nil, err
+	}
+	if job.Status.Succeeded == 0 {
+		return nil, nil
+	}
+	logger.Info("backups removed from the repository")
+	return job, nil
+}
+
+// failureFromJob determines why the Job failed, distinguishing failures due to
+// the repository being locked
+func (m *Mover) failureFromJob(ctx context.Context, logger logr.Logger, job *batchv1.Job) *mover.Failure {
+	failure := mover.FailureFromJob(ctx, m.client, logger, job)
+	if failure.ExitCode != nil && *failure.ExitCode == lockedExitCode {
+		failure.Reason = volsyncv1alpha1.DegradedReasonRepositoryLocked
+	}
+	return failure
+}
+
+// resticHost is the hostname that identifies the volume's snapshots in the
+// repository
+func (m *Mover) resticHost() string {
+	if m.hostname != "" {
+		return m.hostname
+	}
+	return legacyResticHost
+}
+
+// excludePatterns converts the exclude patterns, where those beginning with
+// "/" are relative to the root of the volume, into the absolute paths that
+// restic matches against during the backup
+func excludePatterns(patterns []string) []string {
+	var converted []string
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "/") {
+			p = mountPath + p
+		}
+		converted = append(converted, p)
+	}
+	return converted
+}
+
+// staleLockSeconds converts the age at which locks are considered stale into
+// the number of seconds expected by the mover. Locks are never removed if the
+// age is unset.
+func staleLockSeconds(age *metav1.Duration) string {
+	if age == nil {
+		return ""
+	}
+	return strconv.FormatInt(int64(age.Seconds()), 10)
+}
+
 func (m *Mover) shouldPrune(current time.Time) bool {
 	delta := time.Hour * 24 * 7 // default prune every 7 days
 	if m.pruneInterval != nil {
@@ -423,6 +872,97 @@ func (m *Mover) shouldPrune(current time.Time) bool {
 	return current.After(lastPruned.Add(delta))
 }
 
+func (m *Mover) shouldCheck(current time.Time) bool {
+	if m.checkInterval == nil {
+		return false
+	}
+	delta := time.Hour * 24 * time.Duration(*m.checkInterval)
+	// If we've never checked, the 1st one should be "delta" after creation.
+	lastChecked := m.owner.GetCreationTimestamp().Time
+	if !m.sourceStatus.LastChecked.IsZero() {
+		lastChecked = m.sourceStatus.LastChecked.Time
+	}
+	return current.After(lastChecked.Add(delta))
+}
+
+// recordCheck saves the result of the repository check performed by the
+// completed job. Nothing is recorded if the mover didn't report a result
+// (e.g., the backup was skipped), so the check will be attempted again next
+// time.
+func (m *Mover) recordCheck(report *moverReport) {
+	if report.CheckResult == "" {
+		return
+	}
+	now := metav1.Now()
+	m.sourceStatus.LastChecked = &now
+	m.sourceStatus.LastCheckResult = report.CheckResult
+	m.sourceStatus.LastCheckMessage = report.CheckMessage
+	m.logger.Info("check completed", ".Status.Restic.LastChecked", m.sourceStatus.LastChecked,
+		"result", report.CheckResult)
+	if report.CheckResult == checkResultFailed {
+		m.eventRecorder.Eventf(m.owner, corev1.EventTypeWarning, utils.EvRRepositoryCheckFailed,
+			"restic repository check failed: %s", report.CheckMessage)
+	}
+}
+
+// snapshotLimit is the number of snapshots the mover should list in the status
+func (m *Mover) snapshotLimit() int32 {
+	if m.listSnapshots == nil {
+		return defaultListSnapshots
+	}
+	return *m.listSnapshots
+}
+
+// recordInventory saves the repository's snapshots and totals reported by the
+// completed job. The previous inventory is kept if the mover didn't report
+// one, and the previous total size is kept unless the repository was checked.
+func (m *Mover) recordInventory(report *moverReport) {
+	if report.Repository == nil {
+		return
+	}
+	snapshots, stats := report.inventory()
+	var previous *volsyncv1alpha1.ResticRepositoryStats
+	if m.isSource {
+		previous = m.sourceStatus.Repository
+		m.sourceStatus.Snapshots = snapshots
+		m.sourceStatus.Repository = stats
+	} else {
+		previous = m.destinationStatus.Repository
+		m.destinationStatus.Snapshots = snapshots
+		m.destinationStatus.Repository = stats
+	}
+	if stats.TotalSize == nil && previous != nil {
+		stats.TotalSize = previous.TotalSize
+	}
+}
+
+// inventory converts the reported snapshots and repository totals into their
+// status representation
+func (r *moverReport) inventory() ([]volsyncv1alpha1.ResticSnapshot, *volsyncv1alpha1.ResticRepositoryStats) {
+	var snapshots []volsyncv1alpha1.ResticSnapshot
+	for _, s := range r.Snapshots {
+		snapshot := volsyncv1alpha1.ResticSnapshot{
+			ID:    s.ID,
+			Time:  metav1.NewTime(s.Time),
+			Paths: s.Paths,
+		}
+		if s.Size != nil {
+			snapshot.Size = resource.NewQuantity(*s.Size, resource.BinarySI)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	var stats *volsyncv1alpha1.ResticRepositoryStats
+	if r.Repository != nil {
+		stats = &volsyncv1alpha1.ResticRepositoryStats{
+			SnapshotCount: r.Repository.SnapshotCount,
+		}
+		if r.Repository.TotalSize != nil {
+			stats.TotalSize = resource.NewQuantity(*r.Repository.TotalSize, resource.BinarySI)
+		}
+	}
+	return snapshots, stats
+}
+
 func generateForgetOptions(policy *volsyncv1alpha1.ResticRetainPolicy) string {
 	const defaultForget = "--keep-last 1"
 
@@ -19,6 +19,7 @@ package restic
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"os"
 	"time"
@@ -32,11 +33,13 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
 	"github.com/backube/volsync/controllers/mover"
+	"github.com/backube/volsync/controllers/utils"
 )
 
 const (
@@ -159,6 +162,244 @@ var _ = Describe("Restic prune policy", func() {
 	})
 })
 
+var _ = Describe("Restic check policy", func() {
+	var m *Mover
+	var start metav1.Time
+	const day = 24 * time.Hour
+
+	BeforeEach(func() {
+		start = metav1.Now()
+		m = &Mover{
+			logger: zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)),
+			owner: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "name",
+					Namespace:         "ns",
+					CreationTimestamp: start,
+				},
+			},
+			sourceStatus: &volsyncv1alpha1.ReplicationSourceResticStatus{},
+		}
+	})
+	It("never checks if the interval is omitted", func() {
+		Expect(m.shouldCheck(start.Add(365 * day))).To(BeFalse())
+	})
+	It("waits from creation", func() {
+		interval := int32(30)
+		m.checkInterval = &interval
+		Expect(m.shouldCheck(start.Add(time.Minute))).To(BeFalse())
+		Expect(m.shouldCheck(start.Add(30*day + time.Minute))).To(BeTrue())
+	})
+	It("uses the last checked time", func() {
+		interval := int32(2)
+		m.checkInterval = &interval
+		lastChecked := start.Add(5 * day)
+		m.sourceStatus.LastChecked = &metav1.Time{Time: lastChecked}
+		Expect(m.shouldCheck(start.Add(3 * day))).To(BeFalse())
+		Expect(m.shouldCheck(lastChecked.Add(time.Minute))).To(BeFalse())
+		Expect(m.shouldCheck(lastChecked.Add(2*day + time.Minute))).To(BeTrue())
+	})
+})
+
+var _ = Describe("Restic stale locks", func() {
+	It("passes the age of stale locks to the mover in seconds", func() {
+		Expect(staleLockSeconds(nil)).To(BeEmpty())
+		Expect(staleLockSeconds(&metav1.Duration{Duration: 90 * time.Minute})).To(Equal("5400"))
+	})
+})
+
+var _ = Describe("Restic repository inventory", func() {
+	It("converts the mover's report into status", func() {
+		// As written by the mover's write_summary
+		message := `{"repository":{"snapshotCount":3,"totalSize":5555},"snapshots":[` +
+			`{"id":"bbbb2222","time":"2022-03-23T10:30:00.5+02:00","size":2048,"paths":["/data"]},` +
+			`{"id":"aaaa1111","time":"2022-03-22T10:30:00.123456789Z","size":1000,"paths":["/data"]}]}`
+		report := moverReport{}
+		Expect(json.Unmarshal([]byte(message), &report)).To(Succeed())
+		snapshots, stats := report.inventory()
+		Expect(snapshots).To(HaveLen(2))
+		Expect(snapshots[0].ID).To(Equal("bbbb2222"))
+		Expect(snapshots[0].Time.UTC()).To(Equal(time.Date(2022, 3, 23, 8, 30, 0, 500000000, time.UTC)))
+		Expect(snapshots[0].Size.Value()).To(Equal(int64(2048)))
+		Expect(snapshots[1].Paths).To(ConsistOf("/data"))
+		Expect(stats.SnapshotCount).To(Equal(int32(3)))
+		Expect(stats.TotalSize.Value()).To(Equal(int64(5555)))
+	})
+	It("omits the sizes that weren't reported", func() {
+		// Snapshots taken by restic versions that don't record a summary
+		message := `{"repository":{"snapshotCount":1},"snapshots":[` +
+			`{"id":"aaaa1111","time":"2022-03-22T10:30:00Z","paths":["/data"]}]}`
+		report := moverReport{}
+		Expect(json.Unmarshal([]byte(message), &report)).To(Succeed())
+		snapshots, stats := report.inventory()
+		Expect(snapshots).To(HaveLen(1))
+		Expect(snapshots[0].Size).To(BeNil())
+		Expect(stats.TotalSize).To(BeNil())
+	})
+	It("keeps the total size until the repository is checked again", func() {
+		m := &Mover{
+			isSource: true,
+			sourceStatus: &volsyncv1alpha1.ReplicationSourceResticStatus{
+				Repository: &volsyncv1alpha1.ResticRepositoryStats{
+					SnapshotCount: 2,
+					TotalSize:     resource.NewQuantity(5555, resource.BinarySI),
+				},
+			},
+		}
+		m.recordInventory(&moverReport{Repository: &repositoryReport{SnapshotCount: 3}})
+		Expect(m.sourceStatus.Repository.SnapshotCount).To(Equal(int32(3)))
+		Expect(m.sourceStatus.Repository.TotalSize.Value()).To(Equal(int64(5555)))
+	})
+	It("keeps the previous inventory if none was reported", func() {
+		previous := []volsyncv1alpha1.ResticSnapshot{{ID: "aaaa1111"}}
+		m := &Mover{
+			isSource:     true,
+			sourceStatus: &volsyncv1alpha1.ReplicationSourceResticStatus{Snapshots: previous},
+		}
+		m.recordInventory(&moverReport{CheckResult: "Succeeded"})
+		Expect(m.sourceStatus.Snapshots).To(Equal(previous))
+	})
+	It("limits the number of snapshots listed", func() {
+		m := &Mover{}
+		Expect(m.snapshotLimit()).To(Equal(int32(defaultListSnapshots)))
+		none := int32(0)
+		m.listSnapshots = &none
+		Expect(m.snapshotLimit()).To(Equal(int32(0)))
+	})
+})
+
+var _ = Describe("Restic path filters", func() {
+	It("makes exclude patterns relative to the volume", func() {
+		Expect(excludePatterns(nil)).To(BeEmpty())
+		Expect(excludePatterns([]string{"/cache", "*.tmp", "logs/old"})).To(
+			Equal([]string{"/data/cache", "*.tmp", "logs/old"}))
+	})
+})
+
+var _ = Describe("Restic snapshot identity", func() {
+	It("uses the legacy hostname if none is specified", func() {
+		m := &Mover{owner: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "ns"},
+		}}
+		Expect(m.resticHost()).To(Equal("volsync"))
+		m.hostname = "ns/name"
+		Expect(m.resticHost()).To(Equal("ns/name"))
+	})
+	It("doesn't remove the backups of the legacy hostname without tags", func() {
+		recorder := record.NewFakeRecorder(1)
+		m := &Mover{
+			owner: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "ns"},
+			},
+			isSource:      true,
+			eventRecorder: recorder,
+			logger:        zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter)),
+		}
+		result, err := m.Delete(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Completed).To(BeTrue())
+		Expect(recorder.Events).To(Receive(ContainSubstring(utils.EvRDataRetained)))
+	})
+})
+
+var _ = Describe("Restic repository credentials", func() {
+	var m *Mover
+	var repo *corev1.Secret
+	envNames := func(env []corev1.EnvVar) []string {
+		names := []string{}
+		for _, e := range env {
+			names = append(names, e.Name)
+		}
+		return names
+	}
+	BeforeEach(func() {
+		m = &Mover{}
+		repo = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "restic-config"},
+			Data: map[string][]byte{
+				"RESTIC_REPOSITORY":    []byte("s3:http://minio/repo"),
+				"RESTIC_PASSWORD":      []byte("pass"),
+				"AWS_SESSION_TOKEN":    []byte("token"),
+				"AWS_ACCESS_KEY_ID":    []byte("id"),
+				"RESTIC_REST_USERNAME": []byte("user"),
+				"DATA_DIR":             []byte("/"),
+				"gcs-key.json":         []byte("{}"),
+			},
+		}
+	})
+	repositoryEnv := func(env []corev1.EnvVar) []corev1.EnvVar {
+		return m.repositoryEnv(repo.Name, m.credentialKeys(repo, &batchv1.Job{}), env)
+	}
+	It("passes all of the Secret's keys that are valid variable names", func() {
+		env := repositoryEnv([]corev1.EnvVar{{Name: "DATA_DIR", Value: mountPath}})
+		Expect(envNames(env)).To(Equal([]string{"DATA_DIR", "RESTIC_REPOSITORY", "RESTIC_PASSWORD",
+			"AWS_ACCESS_KEY_ID", "AWS_SESSION_TOKEN", "RESTIC_REST_USERNAME"}))
+		Expect(env[0].Value).To(Equal(mountPath))
+		Expect(env[3]).To(Equal(utils.EnvFromSecret(repo.Name, "AWS_ACCESS_KEY_ID", true)))
+	})
+	It("applies the allow and deny patterns", func() {
+		m.secretKeys = &volsyncv1alpha1.ResticSecretKeysSpec{
+			Allow: []string{"AWS_*", "RESTIC_PASSWORD"},
+			Deny:  []string{"AWS_SESSION_TOKEN", "RESTIC_*"},
+		}
+		Expect(envNames(repositoryEnv(nil))).To(Equal([]string{"RESTIC_REPOSITORY", "RESTIC_PASSWORD",
+			"AWS_ACCESS_KEY_ID"}))
+		volumes, _ := m.credentialVolumes(repo.Name, m.credentialKeys(repo, &batchv1.Job{}))
+		Expect(volumes[0].Secret.Items).To(Equal([]corev1.KeyToPath{
+			{Key: "AWS_ACCESS_KEY_ID", Path: "AWS_ACCESS_KEY_ID"},
+		}))
+		// Nothing is mounted if no keys are allowed
+		m.secretKeys.Allow = []string{"RESTIC_PASSWORD"}
+		volumes, mounts := m.credentialVolumes(repo.Name, m.credentialKeys(repo, &batchv1.Job{}))
+		Expect(volumes).To(BeEmpty())
+		Expect(mounts).To(BeEmpty())
+	})
+	It("mounts the allowed keys so file-type credentials can be used", func() {
+		m.secretKeys = &volsyncv1alpha1.ResticSecretKeysSpec{Allow: []string{"gcs-*", "RESTIC_*"}}
+		volumes, mounts := m.credentialVolumes(repo.Name, m.credentialKeys(repo, &batchv1.Job{}))
+		Expect(volumes).To(ConsistOf(corev1.Volume{Name: credentialsVolumeName, VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: repo.Name, Items: []corev1.KeyToPath{
+				{Key: "RESTIC_PASSWORD", Path: "RESTIC_PASSWORD"},
+				{Key: "RESTIC_REPOSITORY", Path: "RESTIC_REPOSITORY"},
+				{Key: "RESTIC_REST_USERNAME", Path: "RESTIC_REST_USERNAME"},
+				{Key: "gcs-key.json", Path: "gcs-key.json"},
+			}},
+		}}))
+		Expect(mounts).To(ConsistOf(
+			corev1.VolumeMount{Name: credentialsVolumeName, MountPath: credentialsMountPath, ReadOnly: true}))
+	})
+	It("keeps the keys of an existing Job when the Secret changes", func() {
+		job := &batchv1.Job{}
+		job.Spec.Template.Spec.Volumes, _ = m.credentialVolumes(repo.Name, m.credentialKeys(repo, job))
+		job.CreationTimestamp = metav1.Now()
+		repo.Data["AWS_SECRET_ACCESS_KEY"] = []byte("secret")
+		delete(repo.Data, "AWS_SESSION_TOKEN")
+		keys := m.credentialKeys(repo, job)
+		Expect(keys).To(ContainElement("AWS_SESSION_TOKEN"))
+		Expect(keys).NotTo(ContainElement("AWS_SECRET_ACCESS_KEY"))
+		// A new Job uses the Secret's current keys
+		keys = m.credentialKeys(repo, &batchv1.Job{})
+		Expect(keys).NotTo(ContainElement("AWS_SESSION_TOKEN"))
+		Expect(keys).To(ContainElement("AWS_SECRET_ACCESS_KEY"))
+	})
+	It("projects a ServiceAccount token for the identity", func() {
+		m.identity = &volsyncv1alpha1.ResticIdentitySpec{TokenAudience: "sts.amazonaws.com"}
+		volumes, mounts := m.credentialVolumes(repo.Name, m.credentialKeys(repo, &batchv1.Job{}))
+		Expect(volumes).To(HaveLen(2))
+		token := volumes[1].Projected.Sources[0].ServiceAccountToken
+		Expect(token.Audience).To(Equal("sts.amazonaws.com"))
+		Expect(*token.ExpirationSeconds).To(Equal(defaultTokenExpirationSeconds))
+		Expect(mounts).To(ContainElement(
+			corev1.VolumeMount{Name: identityVolumeName, MountPath: identityMountPath, ReadOnly: true}))
+		env := repositoryEnv(nil)
+		Expect(env).To(ContainElement(corev1.EnvVar{Name: "AWS_WEB_IDENTITY_TOKEN_FILE",
+			Value: identityMountPath + "/" + identityTokenFile}))
+		// The Secret can't override the token's location
+		repo.Data["AWS_WEB_IDENTITY_TOKEN_FILE"] = []byte("/tmp/token")
+		Expect(repositoryEnv(nil)).To(HaveLen(len(env)))
+	})
+})
+
 var _ = Describe("Restic properly registers", func() {
 	When("Restic's registration function is called", func() {
 		BeforeEach(func() {
@@ -223,7 +464,7 @@ var _ = Describe("Restic inits flags and env vars", func() {
 				},
 				Status: &volsyncv1alpha1.ReplicationSourceStatus{}, // Controller sets status to non-nil
 			}
-			sourceMover, err := builderForInitTests.FromSource(k8sClient, logger, rs)
+			sourceMover, err := builderForInitTests.FromSource(context.TODO(), k8sClient, logger, &record.FakeRecorder{}, rs)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(sourceMover).NotTo(BeNil())
 			sourceResticMover, _ := sourceMover.(*Mover)
@@ -240,7 +481,7 @@ var _ = Describe("Restic inits flags and env vars", func() {
 				},
 				Status: &volsyncv1alpha1.ReplicationDestinationStatus{}, // Controller sets status to non-nil
 			}
-			destMover, err := builderForInitTests.FromDestination(k8sClient, logger, rd)
+			destMover, err := builderForInitTests.FromDestination(context.TODO(), k8sClient, logger, &record.FakeRecorder{}, rd)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(destMover).NotTo(BeNil())
 			destResticMover, _ := destMover.(*Mover)
@@ -299,7 +540,7 @@ var _ = Describe("Restic ignores other movers", func() {
 					Restic: nil,
 				},
 			}
-			m, e := commonBuilderForTestSuite.FromSource(k8sClient, logger, rs)
+			m, e := commonBuilderForTestSuite.FromSource(context.TODO(), k8sClient, logger, &record.FakeRecorder{}, rs)
 			Expect(m).To(BeNil())
 			Expect(e).NotTo(HaveOccurred())
 		})
@@ -315,13 +556,84 @@ var _ = Describe("Restic ignores other movers", func() {
 					Restic: nil,
 				},
 			}
-			m, e := commonBuilderForTestSuite.FromDestination(k8sClient, logger, rd)
+			m, e := commonBuilderForTestSuite.FromDestination(context.TODO(), k8sClient, logger, &record.FakeRecorder{}, rd)
 			Expect(m).To(BeNil())
 			Expect(e).NotTo(HaveOccurred())
 		})
 	})
 })
 
+var _ = Describe("Restic container image overrides", func() {
+	var ctx = context.TODO()
+	var ns *corev1.Namespace
+	logger := zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter))
+	var rs *volsyncv1alpha1.ReplicationSource
+	BeforeEach(func() {
+		ns = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "image-",
+			},
+		}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		rs = &volsyncv1alpha1.ReplicationSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rs",
+				Namespace: ns.Name,
+			},
+			Spec: volsyncv1alpha1.ReplicationSourceSpec{
+				Restic: &volsyncv1alpha1.ReplicationSourceResticSpec{},
+			},
+			Status: &volsyncv1alpha1.ReplicationSourceStatus{},
+		}
+	})
+	AfterEach(func() {
+		Expect(k8sClient.Delete(ctx, ns)).To(Succeed())
+	})
+	imageOf := func() string {
+		m, err := commonBuilderForTestSuite.FromSource(ctx, k8sClient, logger, &record.FakeRecorder{}, rs)
+		Expect(err).NotTo(HaveOccurred())
+		return m.(*Mover).containerImage
+	}
+	It("uses the default image when there are no overrides", func() {
+		Expect(imageOf()).To(Equal(commonBuilderForTestSuite.getResticContainerImage()))
+	})
+	When("the namespace has a mover images ConfigMap", func() {
+		BeforeEach(func() {
+			cm := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mover.MoverImagesConfigMapName,
+					Namespace: ns.Name,
+				},
+				Data: map[string]string{"restic": "mirror.example.com/restic:canary"},
+			}
+			Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+		})
+		It("uses the namespace's image", func() {
+			Expect(imageOf()).To(Equal("mirror.example.com/restic:canary"))
+		})
+		It("prefers the image in the moverPodTemplate", func() {
+			mover.AllowCustomImages = true
+			defer func() { mover.AllowCustomImages = false }()
+			rs.Spec.MoverPodTemplate = &volsyncv1alpha1.MoverPodTemplateSpec{
+				Image: "mirror.example.com/restic:mine",
+			}
+			Expect(imageOf()).To(Equal("mirror.example.com/restic:mine"))
+		})
+		It("refuses the image in the moverPodTemplate unless the operator allows it", func() {
+			rs.Spec.MoverPodTemplate = &volsyncv1alpha1.MoverPodTemplateSpec{
+				Image: "mirror.example.com/restic:mine",
+			}
+			_, err := commonBuilderForTestSuite.FromSource(ctx, k8sClient, logger, &record.FakeRecorder{}, rs)
+			Expect(err).To(MatchError(mover.ErrCustomImageNotAllowed))
+		})
+		It("reports the image in its version info", func() {
+			m, err := commonBuilderForTestSuite.FromSource(ctx, k8sClient, logger, &record.FakeRecorder{}, rs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.VersionInfo()).To(ContainSubstring("mirror.example.com/restic:canary"))
+		})
+	})
+})
+
 var _ = Describe("Restic as a source", func() {
 	var ctx = context.TODO()
 	var ns *corev1.Namespace
@@ -390,7 +702,7 @@ var _ = Describe("Restic as a source", func() {
 			// Controller sets status to non-nil
 			rs.Status = &volsyncv1alpha1.ReplicationSourceStatus{}
 			// Instantiate a restic mover for the tests
-			m, err := commonBuilderForTestSuite.FromSource(k8sClient, logger, rs)
+			m, err := commonBuilderForTestSuite.FromSource(context.TODO(), k8sClient, logger, &record.FakeRecorder{}, rs)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(m).NotTo(BeNil())
 			mover, _ = m.(*Mover)
@@ -581,6 +893,51 @@ var _ = Describe("Restic as a source", func() {
 					Expect(dataPVC.Labels).To(HaveKey("volsync.backube/cleanup"))
 				})
 			})
+			When("a volume group is used", func() {
+				BeforeEach(func() {
+					rs.Spec.SourcePVC = ""
+					rs.Spec.SourceVolumeGroup = &volsyncv1alpha1.VolumeGroupSpec{
+						PVCNames: []string{sPVC.Name},
+					}
+					rs.Spec.Restic.CopyMethod = volsyncv1alpha1.CopyMethodClone
+				})
+				It("each member is copied", func() {
+					members, dataPVCs, err := mover.ensureSourcePVCs(ctx)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(members).To(ConsistOf(sPVC.Name))
+					Expect(dataPVCs).To(HaveLen(1))
+					Expect(dataPVCs[0].Name).To(Equal("volsync-" + rs.Name + "-src-" + sPVC.Name))
+					Expect(dataPVCs[0].Spec.DataSource.Name).To(Equal(sPVC.Name))
+					// It will be cleaned up at the end of the transfer
+					Expect(dataPVCs[0].Labels).To(HaveKey("volsync.backube/cleanup"))
+				})
+				It("each member is mounted in its own directory", func() {
+					cache := &corev1.PersistentVolumeClaim{
+						ObjectMeta: metav1.ObjectMeta{Name: "thecache", Namespace: ns.Name},
+					}
+					sPVC.Spec.DeepCopyInto(&cache.Spec)
+					Expect(k8sClient.Create(ctx, cache)).To(Succeed())
+					sa := &corev1.ServiceAccount{
+						ObjectMeta: metav1.ObjectMeta{Name: "thesa", Namespace: ns.Name},
+					}
+					Expect(k8sClient.Create(ctx, sa)).To(Succeed())
+					repo := &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: "mysecret", Namespace: ns.Name},
+					}
+					Expect(k8sClient.Create(ctx, repo)).To(Succeed())
+
+					j, e := mover.ensureJob(ctx, cache, []string{sPVC.Name},
+						[]*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					job := &batchv1.Job{}
+					Eventually(func() error {
+						return k8sClient.Get(ctx, types.NamespacedName{Name: "volsync-src-" + rs.Name, Namespace: ns.Name}, job)
+					}).Should(Succeed())
+					Expect(job.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(
+						corev1.VolumeMount{Name: dataVolumeName + "-0", MountPath: mountPath + "/" + sPVC.Name}))
+				})
+			})
 		})
 
 		Context("mover Job is handled properly", func() {
@@ -620,7 +977,7 @@ var _ = Describe("Restic as a source", func() {
 			})
 			When("it's the initial sync", func() {
 				It("should have only the backup action", func() {
-					j, e := mover.ensureJob(ctx, cache, sPVC, sa, repo)
+					j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -634,7 +991,7 @@ var _ = Describe("Restic as a source", func() {
 					Expect(args).To(ConsistOf("backup"))
 				})
 				It("should use the specified container image", func() {
-					j, e := mover.ensureJob(ctx, cache, sPVC, sa, repo)
+					j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -648,7 +1005,7 @@ var _ = Describe("Restic as a source", func() {
 					Expect(args).To(Equal(defaultResticContainerImage))
 				})
 				It("should use the specified service account", func() {
-					j, e := mover.ensureJob(ctx, cache, sPVC, sa, repo)
+					j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -660,7 +1017,7 @@ var _ = Describe("Restic as a source", func() {
 					Expect(job.Spec.Template.Spec.ServiceAccountName).To(Equal(sa.Name))
 				})
 				It("should support pausing", func() {
-					j, e := mover.ensureJob(ctx, cache, sPVC, sa, repo)
+					j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -673,7 +1030,7 @@ var _ = Describe("Restic as a source", func() {
 
 					mover.paused = true
 					Eventually(func() int32 {
-						j, e = mover.ensureJob(ctx, cache, sPVC, sa, repo)
+						j, e = mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
 						Expect(e).NotTo(HaveOccurred())
 						Expect(j).To(BeNil()) // hasn't completed
 						err := k8sClient.Get(ctx, nsn, job)
@@ -683,7 +1040,7 @@ var _ = Describe("Restic as a source", func() {
 
 					mover.paused = false
 					Eventually(func() int32 {
-						j, e = mover.ensureJob(ctx, cache, sPVC, sa, repo)
+						j, e = mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
 						Expect(e).NotTo(HaveOccurred())
 						Expect(j).To(BeNil()) // hasn't completed
 						err := k8sClient.Get(ctx, nsn, job)
@@ -692,6 +1049,53 @@ var _ = Describe("Restic as a source", func() {
 					}).Should(Equal(int32(1)))
 
 				})
+				It("applies the syncTimeout as the Job's deadline", func() {
+					mover.syncTimeout = &metav1.Duration{Duration: 90 * time.Minute}
+					j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Eventually(func() error {
+						return k8sClient.Get(ctx, nsn, job)
+					}).Should(Succeed())
+					Expect(job.Spec.ActiveDeadlineSeconds).NotTo(BeNil())
+					Expect(*job.Spec.ActiveDeadlineSeconds).To(Equal(int64(5400)))
+				})
+				It("replaces the Job after one failure when the retryPolicy sets maxAttempts", func() {
+					maxAttempts := int32(3)
+					mover.retryPolicy = &volsyncv1alpha1.RetryPolicySpec{MaxAttempts: &maxAttempts}
+					j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Eventually(func() error {
+						return k8sClient.Get(ctx, nsn, job)
+					}).Should(Succeed())
+					Expect(*job.Spec.BackoffLimit).To(Equal(int32(1)))
+				})
+				It("attaches a Block volume as a device", func() {
+					block := corev1.PersistentVolumeBlock
+					blockPVC := sPVC.DeepCopy()
+					blockPVC.Spec.VolumeMode = &block
+					j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{blockPVC}, sa, repo)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Eventually(func() error {
+						return k8sClient.Get(ctx, nsn, job)
+					}).Should(Succeed())
+					c := job.Spec.Template.Spec.Containers[0]
+					Expect(c.VolumeDevices).To(ConsistOf(
+						corev1.VolumeDevice{Name: dataVolumeName, DevicePath: devicePath}))
+					Expect(c.Env).To(ContainElement(corev1.EnvVar{Name: "BLOCK_DEVICE", Value: devicePath}))
+					// The cache is still mounted as a filesystem
+					Expect(c.VolumeMounts).To(ConsistOf(
+						corev1.VolumeMount{Name: resticCache, MountPath: resticCacheMountPath},
+						corev1.VolumeMount{Name: credentialsVolumeName, MountPath: credentialsMountPath, ReadOnly: true}))
+				})
 			})
 			When("it's time to prune", func() {
 				var lastMonth metav1.Time
@@ -704,7 +1108,7 @@ var _ = Describe("Restic as a source", func() {
 					}
 				})
 				It("should have the backup and prune actions", func() {
-					j, e := mover.ensureJob(ctx, cache, sPVC, sa, repo)
+					j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -721,15 +1125,91 @@ var _ = Describe("Restic as a source", func() {
 					job.Status.Succeeded = int32(1)
 					Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
 					Eventually(func() bool {
-						j, e = mover.ensureJob(ctx, cache, sPVC, sa, repo)
+						j, e = mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
 						return j != nil && e == nil
 					}, timeout, interval).Should(BeTrue())
 					Expect(mover.sourceStatus.LastPruned.Time.After(lastMonth.Time))
 				})
 			})
+			When("it's time to check the repository", func() {
+				JustBeforeEach(func() {
+					lastMonth := metav1.NewTime(time.Now().Add(-28 * 24 * time.Hour))
+					interval := int32(7)
+					mover.checkInterval = &interval
+					mover.checkReadDataSubset = "5%"
+					mover.sourceStatus = &volsyncv1alpha1.ReplicationSourceResticStatus{
+						LastPruned:  &metav1.Time{Time: time.Now()},
+						LastChecked: &lastMonth,
+					}
+				})
+				It("should have the backup and check actions", func() {
+					j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
+					Expect(e).NotTo(HaveOccurred())
+					Expect(j).To(BeNil()) // hasn't completed
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Eventually(func() error {
+						return k8sClient.Get(ctx, nsn, job)
+					}, timeout, interval).Should(Succeed())
+					c := job.Spec.Template.Spec.Containers[0]
+					Expect(c.Args).To(ConsistOf("backup", "check"))
+					Expect(c.Env).To(ContainElement(corev1.EnvVar{Name: "CHECK_READ_DATA_SUBSET", Value: "5%"}))
+					Expect(c.Env).To(ContainElement(corev1.EnvVar{Name: "SNAPSHOT_LIMIT", Value: "10"}))
+				})
+			})
+			When("exclude patterns are specified", func() {
+				JustBeforeEach(func() {
+					mover.exclude = []string{"/cache", "*.tmp"}
+					mover.excludeIfPresent = []string{"CACHEDIR.TAG"}
+				})
+				It("passes them to the mover", func() {
+					_, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
+					Expect(e).NotTo(HaveOccurred())
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Eventually(func() error {
+						return k8sClient.Get(ctx, nsn, job)
+					}, timeout, interval).Should(Succeed())
+					c := job.Spec.Template.Spec.Containers[0]
+					Expect(c.Env).To(ContainElement(corev1.EnvVar{Name: "EXCLUDE", Value: "/data/cache\n*.tmp"}))
+					Expect(c.Env).To(ContainElement(corev1.EnvVar{Name: "EXCLUDE_IF_PRESENT", Value: "CACHEDIR.TAG"}))
+					Expect(c.Env).To(ContainElement(corev1.EnvVar{Name: "RESTIC_HOST",
+						Value: legacyResticHost}))
+				})
+			})
+			When("a custom CA and proxy are specified", func() {
+				JustBeforeEach(func() {
+					mover.podTemplate = &volsyncv1alpha1.MoverPodTemplateSpec{
+						CustomCA: &volsyncv1alpha1.CustomCASpec{SecretName: "minio-ca", Key: "ca.pem"},
+						Proxy:    &volsyncv1alpha1.ProxySpec{HTTPSProxy: "http://proxy:3128"},
+					}
+				})
+				It("mounts the CA bundle and sets the proxy", func() {
+					_, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
+					Expect(e).NotTo(HaveOccurred())
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Eventually(func() error {
+						return k8sClient.Get(ctx, nsn, job)
+					}, timeout, interval).Should(Succeed())
+					c := job.Spec.Template.Spec.Containers[0]
+					Expect(c.Env).To(ContainElement(corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy:3128"}))
+					Expect(c.Env).To(ContainElement(corev1.EnvVar{Name: "CUSTOM_CA", Value: "/etc/volsync/ca/ca.crt"}))
+					Expect(c.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "volsync-custom-ca",
+						MountPath: "/etc/volsync/ca", ReadOnly: true}))
+					found := false
+					for _, v := range job.Spec.Template.Spec.Volumes {
+						if v.Secret != nil && v.Secret.SecretName == "minio-ca" {
+							found = true
+							Expect(v.Secret.Items).To(ConsistOf(corev1.KeyToPath{Key: "ca.pem", Path: "ca.crt"}))
+						}
+					}
+					Expect(found).To(BeTrue())
+				})
+			})
 			When("the job has failed", func() {
 				It("should be restarted", func() {
-					j, e := mover.ensureJob(ctx, cache, sPVC, sa, repo)
+					j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -743,7 +1223,7 @@ var _ = Describe("Restic as a source", func() {
 						return err
 					}, timeout, interval).Should(Succeed())
 					Eventually(func() int32 {
-						j, e := mover.ensureJob(ctx, cache, sPVC, sa, repo)
+						j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{sPVC}, sa, repo)
 						Expect(e).NotTo(HaveOccurred())
 						Expect(j).To(BeNil())
 						e = k8sClient.Get(ctx, nsn, job)
@@ -798,7 +1278,7 @@ var _ = Describe("Restic as a destination", func() {
 			// Controller sets status to non-nil
 			rd.Status = &volsyncv1alpha1.ReplicationDestinationStatus{}
 			// Instantiate a restic mover for the tests
-			m, err := commonBuilderForTestSuite.FromDestination(k8sClient, logger, rd)
+			m, err := commonBuilderForTestSuite.FromDestination(context.TODO(), k8sClient, logger, &record.FakeRecorder{}, rd)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(m).NotTo(BeNil())
 			mover, _ = m.(*Mover)
@@ -938,7 +1418,7 @@ var _ = Describe("Restic as a destination", func() {
 			})
 			When("it's the initial sync", func() {
 				It("should have only the restore action", func() {
-					j, e := mover.ensureJob(ctx, cache, dPVC, sa, repo)
+					j, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{dPVC}, sa, repo)
 					Expect(e).NotTo(HaveOccurred())
 					Expect(j).To(BeNil()) // hasn't completed
 					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
@@ -952,6 +1432,22 @@ var _ = Describe("Restic as a destination", func() {
 					Expect(args).To(ConsistOf("restore"))
 				})
 			})
+			When("include patterns are specified", func() {
+				JustBeforeEach(func() {
+					mover.include = []string{"/reports", "*.csv"}
+				})
+				It("passes them to the mover", func() {
+					_, e := mover.ensureJob(ctx, cache, nil, []*corev1.PersistentVolumeClaim{dPVC}, sa, repo)
+					Expect(e).NotTo(HaveOccurred())
+					nsn := types.NamespacedName{Name: jobName, Namespace: ns.Name}
+					job = &batchv1.Job{}
+					Eventually(func() error {
+						return k8sClient.Get(ctx, nsn, job)
+					}).Should(Succeed())
+					c := job.Spec.Template.Spec.Containers[0]
+					Expect(c.Env).To(ContainElement(corev1.EnvVar{Name: "INCLUDE", Value: "/reports\n*.csv"}))
+				})
+			})
 		})
 	})
 })
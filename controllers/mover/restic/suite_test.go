@@ -45,9 +45,9 @@ import (
 // http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
 
 const (
-//duration = 10 * time.Second
-//maxWait  = 60 * time.Second
-//interval = 250 * time.Millisecond
+// duration = 10 * time.Second
+// maxWait  = 60 * time.Second
+// interval = 250 * time.Millisecond
 )
 
 var cfg *rest.Config
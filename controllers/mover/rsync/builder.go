@@ -18,11 +18,14 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package rsync
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 
 	"github.com/go-logr/logr"
 	"github.com/spf13/viper"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
@@ -85,13 +88,18 @@ func (rb *Builder) getRsyncContainerImage() string {
 	return rb.viper.GetString(rsyncContainerImageFlag)
 }
 
-func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
+func (rb *Builder) FromSource(ctx context.Context, client client.Client, logger logr.Logger,
+	eventRecorder record.EventRecorder,
 	source *volsyncv1alpha1.ReplicationSource) (mover.Mover, error) {
 	// Only build if the CR belongs to us
 	if source.Spec.Rsync == nil {
 		return nil, nil
 	}
 
+	if source.Spec.SourceVolumeGroup != nil {
+		return nil, errors.New("rsync replication does not support volume groups")
+	}
+
 	// Make sure there's a place to write status info
 	if source.Status.Rsync == nil {
 		source.Status.Rsync = &volsyncv1alpha1.ReplicationSourceRsyncStatus{}
@@ -99,37 +107,54 @@ func (rb *Builder) FromSource(client client.Client, logger logr.Logger,
 
 	vh, err := volumehandler.NewVolumeHandler(
 		volumehandler.WithClient(client),
+		volumehandler.WithRecorder(eventRecorder),
 		volumehandler.WithOwner(source),
 		volumehandler.FromSource(&source.Spec.Rsync.ReplicationSourceVolumeOptions),
+		volumehandler.WithHooks(source.Spec.Hooks),
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	containerImage, err := mover.ContainerImage(ctx, client, source, source.Spec.MoverPodTemplate,
+		"rsync", rb.getRsyncContainerImage())
+	if err != nil {
+		return nil, err
+	}
+
 	return &Mover{
 		client:         client,
+		eventRecorder:  eventRecorder,
 		logger:         logger.WithValues("method", "Rsync"),
 		owner:          source,
+		podTemplate:    source.Spec.MoverPodTemplate,
 		vh:             vh,
-		containerImage: rb.getRsyncContainerImage(),
+		containerImage: containerImage,
 		sshKeys:        source.Spec.Rsync.SSHKeys,
 		serviceType:    source.Spec.Rsync.ServiceType,
 		address:        source.Spec.Rsync.Address,
 		port:           source.Spec.Rsync.Port,
 		isSource:       true,
 		paused:         source.Spec.Paused,
+		retryPolicy:    source.Spec.RetryPolicy,
+		syncTimeout:    source.Spec.SyncTimeout,
 		mainPVCName:    &source.Spec.SourcePVC,
 		sourceStatus:   source.Status.Rsync,
 	}, nil
 }
 
-func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
+func (rb *Builder) FromDestination(ctx context.Context, client client.Client, logger logr.Logger,
+	eventRecorder record.EventRecorder,
 	destination *volsyncv1alpha1.ReplicationDestination) (mover.Mover, error) {
 	// Only build if the CR belongs to us
 	if destination.Spec.Rsync == nil {
 		return nil, nil
 	}
 
+	if destination.Spec.VolumeGroup != nil {
+		return nil, errors.New("rsync replication does not support volume groups")
+	}
+
 	// Make sure there's a place to write status info
 	if destination.Status.Rsync == nil {
 		destination.Status.Rsync = &volsyncv1alpha1.ReplicationDestinationRsyncStatus{}
@@ -137,6 +162,7 @@ func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
 
 	vh, err := volumehandler.NewVolumeHandler(
 		volumehandler.WithClient(client),
+		volumehandler.WithRecorder(eventRecorder),
 		volumehandler.WithOwner(destination),
 		volumehandler.FromDestination(&destination.Spec.Rsync.ReplicationDestinationVolumeOptions),
 	)
@@ -144,18 +170,27 @@ func (rb *Builder) FromDestination(client client.Client, logger logr.Logger,
 		return nil, err
 	}
 
+	containerImage, err := mover.ContainerImage(ctx, client, destination, destination.Spec.MoverPodTemplate,
+		"rsync", rb.getRsyncContainerImage())
+	if err != nil {
+		return nil, err
+	}
+
 	return &Mover{
 		client:         client,
+		eventRecorder:  eventRecorder,
 		logger:         logger.WithValues("method", "Rsync"),
 		owner:          destination,
+		podTemplate:    destination.Spec.MoverPodTemplate,
 		vh:             vh,
-		containerImage: rb.getRsyncContainerImage(),
+		containerImage: containerImage,
 		sshKeys:        destination.Spec.Rsync.SSHKeys,
 		serviceType:    destination.Spec.Rsync.ServiceType,
 		address:        destination.Spec.Rsync.Address,
 		port:           destination.Spec.Rsync.Port,
 		isSource:       false,
 		paused:         destination.Spec.Paused,
+		retryPolicy:    destination.Spec.RetryPolicy,
 		mainPVCName:    destination.Spec.Rsync.DestinationPVC,
 		destStatus:     destination.Status.Rsync,
 	}, nil
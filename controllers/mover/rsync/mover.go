@@ -19,6 +19,7 @@ package rsync
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 
 	"github.com/go-logr/logr"
@@ -26,6 +27,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -39,6 +41,7 @@ import (
 
 const (
 	mountPath      = "/data"
+	devicePath     = "/dev/block"
 	dataVolumeName = "data"
 )
 
@@ -46,7 +49,8 @@ const (
 type Mover struct {
 	client         client.Client
 	logger         logr.Logger
-	owner          metav1.Object
+	eventRecorder  record.EventRecorder
+	owner          client.Object
 	vh             *volumehandler.VolumeHandler
 	containerImage string
 	sshKeys        *string
@@ -55,9 +59,14 @@ type Mover struct {
 	port           *int32
 	isSource       bool
 	paused         bool
+	syncTimeout    *metav1.Duration
+	retryPolicy    *volsyncv1alpha1.RetryPolicySpec
 	mainPVCName    *string
 	sourceStatus   *volsyncv1alpha1.ReplicationSourceRsyncStatus
 	destStatus     *volsyncv1alpha1.ReplicationDestinationRsyncStatus
+	podTemplate    *volsyncv1alpha1.MoverPodTemplateSpec
+	// failure is set when the mover Job fails during this reconcile
+	failure *mover.Failure
 }
 
 var _ mover.Mover = &Mover{}
@@ -72,6 +81,10 @@ var cleanupTypes = []client.Object{
 
 func (m *Mover) Name() string { return "rsync" }
 
+func (m *Mover) VersionInfo() string {
+	return fmt.Sprintf("Rsync container: %s", m.containerImage)
+}
+
 func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 	var err error
 
@@ -106,21 +119,27 @@ func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
 
 	// Ensure mover Job
 	job, err := m.ensureJob(ctx, dataPVC, sa, *rsyncSecretName)
+	if m.failure != nil {
+		return mover.Failed(m.failure), err
+	}
 	if job == nil || err != nil {
 		return mover.InProgress(), err
 	}
 
 	// On the destination, preserve the image and return it
+	var result mover.Result
 	if !m.isSource {
 		image, err := m.vh.EnsureImage(ctx, m.logger, dataPVC)
 		if image == nil || err != nil {
 			return mover.InProgress(), err
 		}
-		return mover.CompleteWithImage(image), nil
+		result = mover.CompleteWithImage(image)
+	} else {
+		// On the source, just signal completion
+		result = mover.Complete()
 	}
-
-	// On the source, just signal completion
-	return mover.Complete(), nil
+	result.Stats = mover.StatsFromJob(ctx, m.client, m.logger, job)
+	return result, nil
 }
 
 func (m *Mover) ensureServiceAndPublishAddress(ctx context.Context) (bool, error) {
@@ -153,7 +172,7 @@ func (m *Mover) ensureServiceAndPublishAddress(ctx context.Context) (bool, error
 }
 
 func (m *Mover) publishSvcAddress(service *corev1.Service) (bool, error) {
-	address := getServiceAddress(service)
+	address := utils.GetServiceAddress(service)
 	if address == "" {
 		// We don't have an address yet, try again later
 		m.updateStatusAddress(nil)
@@ -200,6 +219,8 @@ func (m *Mover) ensureSecrets(ctx context.Context) (*string, error) {
 		}
 		if err := utils.GetAndValidateSecret(ctx, m.client, m.logger, rsyncSecret, fields...); err != nil {
 			m.logger.Error(err, "SSH keys secret does not contain the proper fields")
+			m.eventRecorder.Eventf(m.owner, corev1.EventTypeWarning, utils.EvRSecretInvalid,
+				"sshKeys Secret %s is invalid: %v", rsyncSecret.Name, err)
 			return nil, err
 		}
 		return m.sshKeys, nil
@@ -246,6 +267,12 @@ func (m *Mover) serviceSelector() map[string]string {
 	}
 }
 
+func (m *Mover) Delete(ctx context.Context) (mover.Result, error) {
+	// The replicated data is only stored in the destination's volume, which
+	// belongs to the ReplicationDestination
+	return mover.Complete(), nil
+}
+
 func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
 	m.logger.V(1).Info("Starting cleanup", "m.mainPVCName", m.mainPVCName, "m.isSource", m.isSource)
 	if !m.isSource {
@@ -340,16 +367,18 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 		for k, v := range m.serviceSelector() {
 			job.Spec.Template.ObjectMeta.Labels[k] = v
 		}
-		backoffLimit := int32(2)
-		job.Spec.BackoffLimit = &backoffLimit
+		job.Spec.BackoffLimit = utils.JobBackoffLimit(2, m.retryPolicy)
 
 		parallelism := int32(1)
 		if m.paused {
 			parallelism = int32(0)
 		}
 		job.Spec.Parallelism = &parallelism
+		job.Spec.ActiveDeadlineSeconds = utils.ActiveDeadlineSeconds(m.syncTimeout, m.paused)
 
 		runAsUser := int64(0)
+		dataVolumes, dataMounts, dataDevices := mover.DataVolumes(dataVolumeName, mountPath, devicePath,
+			nil, []*corev1.PersistentVolumeClaim{dataPVC})
 
 		containerEnv := []corev1.EnvVar{}
 		containerCmd := []string{"/bin/bash", "-c", "/destination.sh"} // cmd for replicationDestination job
@@ -362,14 +391,24 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 					containerEnv = append(containerEnv, corev1.EnvVar{Name: "DESTINATION_PORT", Value: connectPort})
 				}
 			}
+			// The controller retries failed transfers according to the
+			// retryPolicy
+			if m.retryPolicy != nil && m.retryPolicy.MaxAttempts != nil {
+				containerEnv = append(containerEnv, corev1.EnvVar{Name: "MAX_RETRIES", Value: "1"})
+			}
+			// Block volumes are streamed from the device
+			if len(dataDevices) > 0 {
+				containerEnv = append(containerEnv, corev1.EnvVar{Name: "BLOCK_DEVICE", Value: devicePath})
+			}
 			// Set container cmd for the replicationSource job
 			containerCmd = []string{"/bin/bash", "-c", "/source.sh"}
 		}
 		job.Spec.Template.Spec.Containers = []corev1.Container{{
-			Name:    "rsync",
-			Env:     containerEnv,
-			Command: containerCmd,
-			Image:   m.containerImage,
+			Name:                     "rsync",
+			TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+			Env:                      containerEnv,
+			Command:                  containerCmd,
+			Image:                    m.containerImage,
 			SecurityContext: &corev1.SecurityContext{
 				Capabilities: &corev1.Capabilities{
 					Add: []corev1.Capability{
@@ -379,34 +418,45 @@ func (m *Mover) ensureJob(ctx context.Context, dataPVC *corev1.PersistentVolumeC
 				},
 				RunAsUser: &runAsUser,
 			},
-			VolumeMounts: []corev1.VolumeMount{
-				{Name: dataVolumeName, MountPath: mountPath},
-				{Name: "keys", MountPath: "/keys"},
-			},
+			VolumeMounts: append(dataMounts,
+				corev1.VolumeMount{Name: "keys", MountPath: "/keys"},
+			),
+			VolumeDevices: dataDevices,
 		}}
 		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
 		job.Spec.Template.Spec.ServiceAccountName = sa.Name
 		secretMode := int32(0600)
-		job.Spec.Template.Spec.Volumes = []corev1.Volume{
-			{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
-				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: dataPVC.Name,
-					ReadOnly:  false,
-				}},
-			},
-			{Name: "keys", VolumeSource: corev1.VolumeSource{
+		job.Spec.Template.Spec.Volumes = append(dataVolumes,
+			corev1.Volume{Name: "keys", VolumeSource: corev1.VolumeSource{
 				Secret: &corev1.SecretVolumeSource{
 					SecretName:  rsyncSecretName,
 					DefaultMode: &secretMode,
 				}},
 			},
-		}
+		)
 		logger.V(1).Info("Job has PVC", "PVC", dataPVC, "DS", dataPVC.Spec.DataSource)
+		utils.ApplyMoverPodTemplate(&job.Spec.Template.Spec, m.podTemplate)
+		utils.ApplyMoverNetwork(&job.Spec.Template.Spec, m.podTemplate)
+		if job.CreationTimestamp.IsZero() {
+			// Ensure the mover lands on the same node as any Pod already using
+			// the volume. This is only done at creation since the Pod template
+			// must remain stable for the life of the job.
+			nodeName, err := utils.NodeForVolume(ctx, m.client, logger, dataPVC)
+			if err != nil {
+				return err
+			}
+			utils.AddNodeAffinity(&job.Spec.Template.Spec, nodeName)
+		}
 		return nil
 	})
 	// If Job had failed, delete it so it can be recreated
 	if job.Status.Failed >= *job.Spec.BackoffLimit {
 		logger.Info("deleting job -- backoff limit reached")
+		if job.DeletionTimestamp.IsZero() {
+			m.eventRecorder.Eventf(m.owner, corev1.EventTypeWarning, utils.EvRJobFailed,
+				"mover Job %s reached its backoff limit and will be restarted", job.Name)
+			m.failure = mover.FailureFromJob(ctx, m.client, logger, job)
+		}
 		err = m.client.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground))
 		return nil, err
 	}
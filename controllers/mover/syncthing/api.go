@@ -0,0 +1,306 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package syncthing
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// Header used to pass the API key to Syncthing
+	apiKeyHeader = "X-API-Key"
+	// Timeout for individual requests to the Syncthing API
+	apiTimeout = 10 * time.Second
+	// How long a connection to the Syncthing API is kept open between polls
+	apiIdleTimeout = 2 * pollInterval
+	// How long an API client is cached after the instance was last polled
+	apiClientExpiry = 5 * pollInterval
+)
+
+// device is the subset of a Syncthing device configuration that VolSync
+// manages. Syncthing fills in defaults for any fields that are omitted.
+type device struct {
+	DeviceID     string   `json:"deviceID"`
+	Name         string   `json:"name,omitempty"`
+	Addresses    []string `json:"addresses"`
+	Introducer   bool     `json:"introducer"`
+	IntroducedBy string   `json:"introducedBy,omitempty"`
+}
+
+// folderDevice is a device that a folder is shared with
+type folderDevice struct {
+	DeviceID     string `json:"deviceID"`
+	IntroducedBy string `json:"introducedBy,omitempty"`
+}
+
+// folder is the subset of a Syncthing folder configuration that VolSync
+// manages
+type folder struct {
+	ID      string         `json:"id"`
+	Devices []folderDevice `json:"devices"`
+}
+
+// systemStatus is the response to /rest/system/status
+type systemStatus struct {
+	MyID string `json:"myID"`
+}
+
+// connection describes the state of the connection to a single device
+type connection struct {
+	Connected bool   `json:"connected"`
+	Paused    bool   `json:"paused"`
+	Address   string `json:"address"`
+}
+
+// systemConnections is the response to /rest/system/connections
+type systemConnections struct {
+	Connections map[string]connection `json:"connections"`
+}
+
+// apiConfig holds the information necessary to connect to a Syncthing
+// instance's REST API
+type apiConfig struct {
+	// URL of the API endpoint (e.g., https://10.0.0.1:8384)
+	URL string
+	// Key used to authenticate to the API
+	APIKey string
+	// PEM-encoded certificate that the API endpoint will present
+	CertPEM []byte
+	// Name that the API endpoint's certificate was issued for
+	ServerName string
+}
+
+// syncthingAPI is the set of Syncthing REST operations used by the mover
+type syncthingAPI interface {
+	SystemStatus(ctx context.Context) (*systemStatus, error)
+	Connections(ctx context.Context) (*systemConnections, error)
+	Devices(ctx context.Context) ([]device, error)
+	SetDevices(ctx context.Context, devices []device) error
+	Folder(ctx context.Context, id string) (*folder, error)
+	SetFolderDevices(ctx context.Context, id string, devices []folderDevice) error
+}
+
+// apiClient implements syncthingAPI via HTTPS requests to Syncthing
+type apiClient struct {
+	config apiConfig
+	client *http.Client
+}
+
+var _ syncthingAPI = &apiClient{}
+
+// newAPIClient returns a syncthingAPI that communicates w/ the Syncthing
+// instance described by cfg. The connection only trusts the certificate
+// provided in cfg.
+func newAPIClient(cfg apiConfig) (syncthingAPI, error) {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(cfg.CertPEM) {
+		return nil, errors.New("unable to parse Syncthing API certificate")
+	}
+	return &apiClient{
+		config: cfg,
+		client: &http.Client{
+			Timeout: apiTimeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					MinVersion: tls.VersionTLS12,
+					RootCAs:    roots,
+					ServerName: cfg.ServerName,
+				},
+				IdleConnTimeout: apiIdleTimeout,
+			},
+		},
+	}, nil
+}
+
+// apiClientCache keeps a single API client for each Syncthing instance so
+// that its connections are reused each time the instance is polled, instead
+// of a new connection (and transport) being created for every poll. Clients
+// that haven't been used within the expiry are evicted, since the instance
+// may have been deleted or replaced without the mover being told.
+type apiClientCache struct {
+	mutex   sync.Mutex
+	clients map[string]*cachedAPIClient
+	expiry  time.Duration
+	// evictTimer is pending while the cache holds any clients
+	evictTimer *time.Timer
+}
+
+type cachedAPIClient struct {
+	config   apiConfig
+	api      syncthingAPI
+	lastUsed time.Time
+}
+
+func newAPIClientCache(expiry time.Duration) *apiClientCache {
+	return &apiClientCache{
+		clients: map[string]*cachedAPIClient{},
+		expiry:  expiry,
+	}
+}
+
+// get returns the API client for the Syncthing instance identified by key.
+// The client is replaced if the instance's configuration has changed.
+func (c *apiClientCache) get(key string, cfg apiConfig) (syncthingAPI, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if cached, ok := c.clients[key]; ok {
+		if cached.config.URL == cfg.URL && cached.config.APIKey == cfg.APIKey &&
+			cached.config.ServerName == cfg.ServerName && bytes.Equal(cached.config.CertPEM, cfg.CertPEM) {
+			cached.lastUsed = time.Now()
+			return cached.api, nil
+		}
+		closeIdleConnections(cached.api)
+		delete(c.clients, key)
+	}
+	api, err := newAPIClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c.clients[key] = &cachedAPIClient{config: cfg, api: api, lastUsed: time.Now()}
+	if c.evictTimer == nil {
+		c.evictTimer = time.AfterFunc(c.expiry, c.evictExpired)
+	}
+	return api, nil
+}
+
+// evictExpired discards the clients that haven't been used within the expiry
+// and reschedules itself while any clients remain
+func (c *apiClientCache) evictExpired() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.evictTimer = nil
+	for key, cached := range c.clients {
+		if time.Since(cached.lastUsed) >= c.expiry {
+			closeIdleConnections(cached.api)
+			delete(c.clients, key)
+		}
+	}
+	if len(c.clients) > 0 {
+		c.evictTimer = time.AfterFunc(c.expiry, c.evictExpired)
+	}
+}
+
+// remove discards the API client for the Syncthing instance identified by key
+func (c *apiClientCache) remove(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if cached, ok := c.clients[key]; ok {
+		closeIdleConnections(cached.api)
+		delete(c.clients, key)
+	}
+}
+
+func closeIdleConnections(api syncthingAPI) {
+	if client, ok := api.(*apiClient); ok {
+		client.client.CloseIdleConnections()
+	}
+}
+
+func (c *apiClient) SystemStatus(ctx context.Context) (*systemStatus, error) {
+	status := &systemStatus{}
+	if err := c.do(ctx, http.MethodGet, "/rest/system/status", nil, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (c *apiClient) Connections(ctx context.Context) (*systemConnections, error) {
+	conns := &systemConnections{}
+	if err := c.do(ctx, http.MethodGet, "/rest/system/connections", nil, conns); err != nil {
+		return nil, err
+	}
+	return conns, nil
+}
+
+func (c *apiClient) Devices(ctx context.Context) ([]device, error) {
+	devices := []device{}
+	if err := c.do(ctx, http.MethodGet, "/rest/config/devices", nil, &devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (c *apiClient) SetDevices(ctx context.Context, devices []device) error {
+	return c.do(ctx, http.MethodPut, "/rest/config/devices", devices, nil)
+}
+
+func (c *apiClient) Folder(ctx context.Context, id string) (*folder, error) {
+	f := &folder{}
+	if err := c.do(ctx, http.MethodGet, "/rest/config/folders/"+id, nil, f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (c *apiClient) SetFolderDevices(ctx context.Context, id string, devices []folderDevice) error {
+	patch := struct {
+		Devices []folderDevice `json:"devices"`
+	}{Devices: devices}
+	return c.do(ctx, http.MethodPatch, "/rest/config/folders/"+id, patch, nil)
+}
+
+// do performs a single API request. If in is non-nil, it is sent as the JSON
+// body of the request. If out is non-nil, the response body is decoded into
+// it.
+func (c *apiClient) do(ctx context.Context, method string, path string, in interface{}, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.config.URL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(apiKeyHeader, c.config.APIKey)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("syncthing API %s %s failed: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
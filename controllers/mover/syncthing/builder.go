@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package syncthing
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/spf13/viper"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/mover"
+)
+
+const (
+	// defaultSyncthingContainerImage is the default container image for the
+	// syncthing data mover
+	defaultSyncthingContainerImage = "quay.io/backube/volsync-mover-syncthing:latest"
+	// Command line flag will be checked first
+	// If command line flag not set, the RELATED_IMAGE_ env var will be used
+	syncthingContainerImageFlag   = "syncthing-container-image"
+	syncthingContainerImageEnvVar = "RELATED_IMAGE_SYNCTHING_CONTAINER"
+)
+
+type Builder struct {
+	viper *viper.Viper  // For unit tests to be able to override - global viper will be used by default in Register()
+	flags *flag.FlagSet // For unit tests to be able to override - global flags will be used by default in Register()
+	// apiClients holds the connections to the Syncthing instances, which are
+	// reused across reconciles until the instances stop being polled
+	apiClients *apiClientCache
+}
+
+var _ mover.Builder = &Builder{}
+
+func Register() error {
+	// Use global viper & command line flags
+	b, err := newBuilder(viper.GetViper(), flag.CommandLine)
+	if err != nil {
+		return err
+	}
+
+	mover.Register(b)
+	return nil
+}
+
+func newBuilder(viper *viper.Viper, flags *flag.FlagSet) (*Builder, error) {
+	b := &Builder{
+		viper:      viper,
+		flags:      flags,
+		apiClients: newAPIClientCache(apiClientExpiry),
+	}
+
+	// Set default syncthing container image - will be used if both command line flag and env var are not set
+	b.viper.SetDefault(syncthingContainerImageFlag, defaultSyncthingContainerImage)
+
+	// Setup command line flag for the syncthing container image
+	b.flags.String(syncthingContainerImageFlag, defaultSyncthingContainerImage,
+		"The container image for the syncthing data mover")
+	// Viper will check for command line flag first, then fallback to the env var
+	err := b.viper.BindEnv(syncthingContainerImageFlag, syncthingContainerImageEnvVar)
+
+	return b, err
+}
+
+func (rb *Builder) VersionInfo() string {
+	return fmt.Sprintf("Syncthing container: %s", rb.getSyncthingContainerImage())
+}
+
+// syncthingContainerImage is the container image name of the syncthing data mover
+func (rb *Builder) getSyncthingContainerImage() string {
+	return rb.viper.GetString(syncthingContainerImageFlag)
+}
+
+func (rb *Builder) FromSource(ctx context.Context, client client.Client, logger logr.Logger,
+	eventRecorder record.EventRecorder,
+	source *volsyncv1alpha1.ReplicationSource) (mover.Mover, error) {
+	// Only build if the CR belongs to us
+	if source.Spec.Syncthing == nil {
+		return nil, nil
+	}
+
+	// Syncthing runs continuously, so it has no notion of a sync iteration
+	if source.Spec.Trigger != nil {
+		return nil, errors.New("syncthing replication does not support triggers")
+	}
+	if source.Spec.SourceVolumeGroup != nil {
+		return nil, errors.New("syncthing replication does not support volume groups")
+	}
+
+	// Make sure there's a place to write status info
+	if source.Status.Syncthing == nil {
+		source.Status.Syncthing = &volsyncv1alpha1.ReplicationSourceSyncthingStatus{}
+	}
+
+	containerImage, err := mover.ContainerImage(ctx, client, source, source.Spec.MoverPodTemplate,
+		"syncthing", rb.getSyncthingContainerImage())
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := source.Namespace + "/" + source.Name
+	return &Mover{
+		client:         client,
+		eventRecorder:  eventRecorder,
+		logger:         logger.WithValues("method", "Syncthing"),
+		owner:          source,
+		podTemplate:    source.Spec.MoverPodTemplate,
+		containerImage: containerImage,
+		peers:          source.Spec.Syncthing.Peers,
+		serviceType:    source.Spec.Syncthing.ServiceType,
+		paused:         source.Spec.Paused,
+		dataPVCName:    source.Spec.SourcePVC,
+		status:         source.Status.Syncthing,
+		apiConnect: func(cfg apiConfig) (syncthingAPI, error) {
+			return rb.apiClients.get(apiKey, cfg)
+		},
+		apiDisconnect: func() { rb.apiClients.remove(apiKey) },
+	}, nil
+}
+
+func (rb *Builder) FromDestination(ctx context.Context, client client.Client, logger logr.Logger,
+	eventRecorder record.EventRecorder,
+	destination *volsyncv1alpha1.ReplicationDestination) (mover.Mover, error) {
+	// Syncthing is symmetric and is only configured via a ReplicationSource
+	return nil, nil
+}
@@ -0,0 +1,588 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package syncthing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/mover"
+	"github.com/backube/volsync/controllers/utils"
+	"github.com/backube/volsync/controllers/volumehandler"
+)
+
+const (
+	dataMountPath    = "/data"
+	dataVolumeName   = "data"
+	configMountPath  = "/config"
+	configVolumeName = "config"
+	certVolumeName   = "https-certs"
+	// Default size of the volume holding Syncthing's configuration & database
+	configCapacity = "1Gi"
+	// Port that the Syncthing REST API listens on
+	apiPort = 8384
+	// Port used for Syncthing data transfers
+	dataPort = 22000
+	// ID of the shared folder. Must match the folder defined in
+	// mover-syncthing/config.xml
+	folderID = "syncthing-folder-id"
+	// Fields of the Secret that holds the API credentials
+	apiKeyField   = "apikey"
+	httpsCertPEM  = "httpsCertPEM"
+	httpsKeyPEM   = "httpsKeyPEM"
+	apiKeyEnvName = "STGUIAPIKEY"
+	// How often the Syncthing instance is polled for status updates
+	pollInterval = 20 * time.Second
+)
+
+// Mover is the reconciliation logic for the Syncthing-based data mover.
+type Mover struct {
+	client         client.Client
+	logger         logr.Logger
+	eventRecorder  record.EventRecorder
+	owner          client.Object
+	containerImage string
+	peers          []volsyncv1alpha1.SyncthingPeer
+	serviceType    *corev1.ServiceType
+	paused         bool
+	dataPVCName    string
+	status         *volsyncv1alpha1.ReplicationSourceSyncthingStatus
+	podTemplate    *volsyncv1alpha1.MoverPodTemplateSpec
+	// apiConnect is used to create a connection to the Syncthing API. It can
+	// be overridden in unit tests.
+	apiConnect func(apiConfig) (syncthingAPI, error)
+	// apiDisconnect releases the connection to the Syncthing API once it is no
+	// longer needed
+	apiDisconnect func()
+}
+
+var _ mover.Mover = &Mover{}
+
+func (m *Mover) Name() string { return "syncthing" }
+
+func (m *Mover) VersionInfo() string {
+	return fmt.Sprintf("Syncthing container: %s", m.containerImage)
+}
+
+// Synchronize ensures the long-running Syncthing instance is present and
+// configured. Since Syncthing continuously replicates data, this never
+// reports completion. Instead, it requests periodic requeueing so that the
+// status of the peers can be updated.
+func (m *Mover) Synchronize(ctx context.Context) (mover.Result, error) {
+	dataPVC, err := m.ensureDataPVC(ctx)
+	if dataPVC == nil || err != nil {
+		return mover.InProgress(), err
+	}
+	if mover.IsBlockVolume(dataPVC) {
+		return mover.InProgress(), fmt.Errorf("syncthing does not support Block volumes: %s", dataPVC.Name)
+	}
+
+	configPVC, err := m.ensureConfigPVC(ctx, dataPVC)
+	if configPVC == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	secret, err := m.ensureSecret(ctx)
+	if secret == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	sa, err := m.ensureSA(ctx)
+	if sa == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	deployment, err := m.ensureDeployment(ctx, dataPVC, configPVC, sa, secret)
+	if deployment == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	apiSvc, err := m.ensureAPIService(ctx)
+	if apiSvc == nil || err != nil {
+		return mover.InProgress(), err
+	}
+
+	if err = m.ensureDataService(ctx); err != nil {
+		return mover.InProgress(), err
+	}
+
+	if m.paused {
+		m.setPeersDisconnected()
+		m.disconnectAPI()
+		return mover.RetryAfter(pollInterval), nil
+	}
+
+	// We can't configure Syncthing until it's running
+	if deployment.Status.ReadyReplicas == 0 {
+		m.logger.V(1).Info("waiting for Syncthing to become ready")
+		return mover.RetryAfter(pollInterval), nil
+	}
+
+	api, err := m.apiConnect(m.apiConfigFor(apiSvc, secret))
+	if err != nil {
+		return mover.InProgress(), err
+	}
+
+	devices, err := m.ensureConfigured(ctx, api)
+	if err != nil {
+		return mover.InProgress(), err
+	}
+
+	if err = m.updatePeerStatus(ctx, api, devices); err != nil {
+		return mover.InProgress(), err
+	}
+
+	return mover.RetryAfter(pollInterval), nil
+}
+
+// Delete releases the cached connection to the Syncthing API. The Syncthing
+// resources are owned by the ReplicationSource, so they are garbage collected
+// with it.
+func (m *Mover) Delete(ctx context.Context) (mover.Result, error) {
+	m.disconnectAPI()
+	// The replicated data is only stored in the volumes of the peers
+	return mover.Complete(), nil
+}
+
+func (m *Mover) disconnectAPI() {
+	if m.apiDisconnect != nil {
+		m.apiDisconnect()
+	}
+}
+
+// Cleanup is a no-op since Syncthing doesn't create any per-iteration
+// resources.
+func (m *Mover) Cleanup(ctx context.Context) (mover.Result, error) {
+	return mover.Complete(), nil
+}
+
+func (m *Mover) ensureDataPVC(ctx context.Context) (*corev1.PersistentVolumeClaim, error) {
+	dataPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.dataPVCName,
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	if err := m.client.Get(ctx, client.ObjectKeyFromObject(dataPVC), dataPVC); err != nil {
+		m.logger.Error(err, "unable to get source PVC", "PVC", client.ObjectKeyFromObject(dataPVC))
+		return nil, err
+	}
+	return dataPVC, nil
+}
+
+func (m *Mover) ensureConfigPVC(ctx context.Context,
+	dataPVC *corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
+	capacity := resource.MustParse(configCapacity)
+	vh, err := volumehandler.NewVolumeHandler(
+		volumehandler.WithClient(m.client),
+		volumehandler.WithRecorder(m.eventRecorder),
+		volumehandler.WithOwner(m.owner),
+		volumehandler.Capacity(&capacity),
+		volumehandler.StorageClassName(dataPVC.Spec.StorageClassName),
+		volumehandler.AccessModes([]corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return vh.EnsureNewPVC(ctx, m.logger, "volsync-"+m.owner.GetName()+"-config")
+}
+
+// ensureSecret ensures the Secret holding the API key and the HTTPS
+// certificate for the Syncthing API is present. The credentials are
+// generated once and then preserved.
+func (m *Mover) ensureSecret(ctx context.Context) (*corev1.Secret, error) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "volsync-syncthing-" + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	logger := m.logger.WithValues("secret", client.ObjectKeyFromObject(secret))
+
+	op, err := ctrlutil.CreateOrUpdate(ctx, m.client, secret, func() error {
+		if err := ctrl.SetControllerReference(m.owner, secret, m.client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		if len(secret.Data[apiKeyField]) == 0 {
+			apiKey, err := generateAPIKey()
+			if err != nil {
+				return err
+			}
+			secret.Data[apiKeyField] = apiKey
+		}
+		if len(secret.Data[httpsCertPEM]) == 0 || len(secret.Data[httpsKeyPEM]) == 0 {
+			certPEM, keyPEM, err := generateCertificate(m.apiServiceDNSNames())
+			if err != nil {
+				return err
+			}
+			secret.Data[httpsCertPEM] = certPEM
+			secret.Data[httpsKeyPEM] = keyPEM
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "Secret reconcile failed")
+		return nil, err
+	}
+	logger.V(1).Info("Secret reconciled", "operation", op)
+	return secret, nil
+}
+
+func (m *Mover) ensureSA(ctx context.Context) (*corev1.ServiceAccount, error) {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "volsync-src-" + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	saDesc := utils.NewSAHandler(ctx, m.client, m.owner, sa)
+	cont, err := saDesc.Reconcile(m.logger)
+	if cont {
+		return sa, err
+	}
+	return nil, err
+}
+
+func (m *Mover) selector() map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":      "syncthing-" + m.owner.GetName(),
+		"app.kubernetes.io/component": "syncthing-mover",
+		"app.kubernetes.io/part-of":   "volsync",
+	}
+}
+
+//nolint:funlen
+func (m *Mover) ensureDeployment(ctx context.Context, dataPVC *corev1.PersistentVolumeClaim,
+	configPVC *corev1.PersistentVolumeClaim, sa *corev1.ServiceAccount,
+	secret *corev1.Secret) (*appsv1.Deployment, error) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "volsync-syncthing-" + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	logger := m.logger.WithValues("deployment", client.ObjectKeyFromObject(deployment))
+
+	op, err := ctrlutil.CreateOrUpdate(ctx, m.client, deployment, func() error {
+		if err := ctrl.SetControllerReference(m.owner, deployment, m.client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+
+		replicas := int32(1)
+		if m.paused {
+			replicas = 0
+		}
+		deployment.Spec.Replicas = &replicas
+		// The volumes are likely RWO, so the old pod must be gone before a
+		// new one can start
+		deployment.Spec.Strategy = appsv1.DeploymentStrategy{
+			Type: appsv1.RecreateDeploymentStrategyType,
+		}
+		if deployment.CreationTimestamp.IsZero() { // selector is immutable
+			deployment.Spec.Selector = &metav1.LabelSelector{
+				MatchLabels: m.selector(),
+			}
+		}
+
+		deployment.Spec.Template.ObjectMeta.Name = deployment.Name
+		if deployment.Spec.Template.ObjectMeta.Labels == nil {
+			deployment.Spec.Template.ObjectMeta.Labels = map[string]string{}
+		}
+		for k, v := range m.selector() {
+			deployment.Spec.Template.ObjectMeta.Labels[k] = v
+		}
+
+		runAsUser := int64(0)
+		secretMode := int32(0600)
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{{
+			Name:    "syncthing",
+			Command: []string{"/entry.sh"},
+			Args:    []string{"run"},
+			Image:   m.containerImage,
+			Env: []corev1.EnvVar{
+				{Name: "SYNCTHING_CONFIG_DIR", Value: configMountPath},
+				{Name: "SYNCTHING_DATA_DIR", Value: dataMountPath},
+				{Name: "SYNCTHING_DATA_TRANSFERMODE", Value: "sendreceive"},
+				{Name: apiKeyEnvName, ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+						Key:                  apiKeyField,
+					},
+				}},
+			},
+			Ports: []corev1.ContainerPort{
+				{Name: "api", ContainerPort: apiPort, Protocol: corev1.ProtocolTCP},
+				{Name: "data", ContainerPort: dataPort, Protocol: corev1.ProtocolTCP},
+			},
+			SecurityContext: &corev1.SecurityContext{
+				RunAsUser: &runAsUser,
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: dataVolumeName, MountPath: dataMountPath},
+				{Name: configVolumeName, MountPath: configMountPath},
+				// Syncthing loads the API certificate from its config dir
+				{Name: certVolumeName, MountPath: configMountPath + "/https-cert.pem", SubPath: httpsCertPEM},
+				{Name: certVolumeName, MountPath: configMountPath + "/https-key.pem", SubPath: httpsKeyPEM},
+			},
+		}}
+		deployment.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+		deployment.Spec.Template.Spec.ServiceAccountName = sa.Name
+		deployment.Spec.Template.Spec.Volumes = []corev1.Volume{
+			{Name: dataVolumeName, VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: dataPVC.Name,
+				}},
+			},
+			{Name: configVolumeName, VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: configPVC.Name,
+				}},
+			},
+			{Name: certVolumeName, VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName:  secret.Name,
+					DefaultMode: &secretMode,
+					Items: []corev1.KeyToPath{
+						{Key: httpsCertPEM, Path: httpsCertPEM},
+						{Key: httpsKeyPEM, Path: httpsKeyPEM},
+					},
+				}},
+			},
+		}
+		utils.ApplyMoverPodTemplate(&deployment.Spec.Template.Spec, m.podTemplate)
+		if deployment.CreationTimestamp.IsZero() {
+			// Ensure the mover lands on the same node as any Pod already using
+			// the volume. This is only done at creation since the Pod template
+			// must remain stable for the life of the deployment.
+			nodeName, err := utils.NodeForVolume(ctx, m.client, logger, dataPVC)
+			if err != nil {
+				return err
+			}
+			utils.AddNodeAffinity(&deployment.Spec.Template.Spec, nodeName)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "Deployment reconcile failed")
+		return nil, err
+	}
+	logger.V(1).Info("Deployment reconciled", "operation", op)
+	return deployment, nil
+}
+
+func (m *Mover) apiServiceName() string {
+	return "volsync-syncthing-api-" + m.owner.GetName()
+}
+
+// apiServiceDNSNames are the names that the API certificate is issued for
+func (m *Mover) apiServiceDNSNames() []string {
+	name := m.apiServiceName()
+	ns := m.owner.GetNamespace()
+	return []string{
+		name,
+		name + "." + ns,
+		name + "." + ns + ".svc",
+		name + "." + ns + ".svc.cluster.local",
+	}
+}
+
+// ensureAPIService ensures the (internal) Service that the controller uses to
+// reach the Syncthing API.
+func (m *Mover) ensureAPIService(ctx context.Context) (*corev1.Service, error) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      m.apiServiceName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	svcType := corev1.ServiceTypeClusterIP
+	if err := m.ensureService(ctx, service, &svcType, "api", apiPort); err != nil {
+		return nil, err
+	}
+	if service.Spec.ClusterIP == "" {
+		return nil, nil
+	}
+	return service, nil
+}
+
+// ensureDataService ensures the Service that peers use to connect to this
+// Syncthing instance and publishes its address in the status.
+func (m *Mover) ensureDataService(ctx context.Context) error {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "volsync-syncthing-data-" + m.owner.GetName(),
+			Namespace: m.owner.GetNamespace(),
+		},
+	}
+	if err := m.ensureService(ctx, service, m.serviceType, "data", dataPort); err != nil {
+		return err
+	}
+
+	address := utils.GetServiceAddress(service)
+	if address == "" {
+		// We don't have an address yet
+		m.status.Address = ""
+		return nil
+	}
+	m.status.Address = fmt.Sprintf("tcp://%s:%d", address, dataPort)
+	return nil
+}
+
+func (m *Mover) ensureService(ctx context.Context, service *corev1.Service,
+	svcType *corev1.ServiceType, portName string, port int32) error {
+	logger := m.logger.WithValues("service", client.ObjectKeyFromObject(service))
+
+	op, err := ctrlutil.CreateOrUpdate(ctx, m.client, service, func() error {
+		if err := ctrl.SetControllerReference(m.owner, service, m.client.Scheme()); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		if svcType != nil {
+			service.Spec.Type = *svcType
+		} else {
+			service.Spec.Type = corev1.ServiceTypeClusterIP
+		}
+		service.Spec.Selector = m.selector()
+		if len(service.Spec.Ports) != 1 {
+			service.Spec.Ports = []corev1.ServicePort{{}}
+		}
+		service.Spec.Ports[0].Name = portName
+		service.Spec.Ports[0].Port = port
+		service.Spec.Ports[0].Protocol = corev1.ProtocolTCP
+		service.Spec.Ports[0].TargetPort = intstr.FromInt(int(port))
+		if service.Spec.Type == corev1.ServiceTypeClusterIP {
+			service.Spec.Ports[0].NodePort = 0
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "Service reconcile failed")
+		return err
+	}
+	logger.V(1).Info("Service reconciled", "operation", op)
+	return nil
+}
+
+func (m *Mover) apiConfigFor(apiSvc *corev1.Service, secret *corev1.Secret) apiConfig {
+	return apiConfig{
+		URL:        fmt.Sprintf("https://%s:%d", apiSvc.Spec.ClusterIP, apiPort),
+		APIKey:     string(secret.Data[apiKeyField]),
+		CertPEM:    secret.Data[httpsCertPEM],
+		ServerName: m.apiServiceDNSNames()[1],
+	}
+}
+
+// ensureConfigured records the local device ID and makes sure the Syncthing
+// devices and the shared folder match the peers from the spec. It returns the
+// resulting list of remote devices.
+func (m *Mover) ensureConfigured(ctx context.Context, api syncthingAPI) ([]device, error) {
+	sysStatus, err := api.SystemStatus(ctx)
+	if err != nil {
+		m.logger.Error(err, "unable to retrieve Syncthing system status")
+		return nil, err
+	}
+	m.status.DeviceID = sysStatus.MyID
+
+	current, err := api.Devices(ctx)
+	if err != nil {
+		m.logger.Error(err, "unable to retrieve Syncthing devices")
+		return nil, err
+	}
+	desired := desiredDevices(sysStatus.MyID, current, m.peers)
+	if !devicesEqual(current, desired) {
+		m.logger.Info("updating Syncthing devices", "devices", len(desired))
+		if err = api.SetDevices(ctx, desired); err != nil {
+			m.logger.Error(err, "unable to update Syncthing devices")
+			return nil, err
+		}
+	}
+
+	f, err := api.Folder(ctx, folderID)
+	if err != nil {
+		m.logger.Error(err, "unable to retrieve Syncthing folder", "folder", folderID)
+		return nil, err
+	}
+	desiredShares := desiredFolderDevices(desired)
+	if !folderDevicesEqual(f.Devices, desiredShares) {
+		m.logger.Info("updating Syncthing folder", "folder", folderID, "devices", len(desiredShares))
+		if err = api.SetFolderDevices(ctx, folderID, desiredShares); err != nil {
+			m.logger.Error(err, "unable to update Syncthing folder", "folder", folderID)
+			return nil, err
+		}
+	}
+
+	return remoteDevices(sysStatus.MyID, desired), nil
+}
+
+// updatePeerStatus fills in the status of each remote device based on
+// Syncthing's current connections
+func (m *Mover) updatePeerStatus(ctx context.Context, api syncthingAPI, devices []device) error {
+	conns, err := api.Connections(ctx)
+	if err != nil {
+		m.logger.Error(err, "unable to retrieve Syncthing connections")
+		return err
+	}
+
+	peers := make([]volsyncv1alpha1.SyncthingPeerStatus, 0, len(devices))
+	for _, d := range devices {
+		peer := volsyncv1alpha1.SyncthingPeerStatus{ID: d.DeviceID}
+		if len(d.Addresses) > 0 {
+			peer.Address = d.Addresses[0]
+		}
+		if c, ok := conns.Connections[d.DeviceID]; ok && c.Connected {
+			peer.Connected = true
+			if c.Address != "" {
+				peer.Address = c.Address
+			}
+		}
+		peers = append(peers, peer)
+	}
+	m.status.Peers = peers
+	return nil
+}
+
+// setPeersDisconnected marks all configured peers as disconnected
+func (m *Mover) setPeersDisconnected() {
+	peers := make([]volsyncv1alpha1.SyncthingPeerStatus, 0, len(m.peers))
+	for _, p := range m.peers {
+		peers = append(peers, volsyncv1alpha1.SyncthingPeerStatus{
+			ID:        p.ID,
+			Address:   p.Address,
+			Connected: false,
+		})
+	}
+	m.status.Peers = peers
+}
@@ -0,0 +1,467 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package syncthing
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/mover"
+)
+
+const (
+	timeout  = "30s"
+	interval = "1s"
+)
+
+// fakeAPI is an in-memory stand-in for a Syncthing instance
+type fakeAPI struct {
+	myID          string
+	devices       []device
+	folder        folder
+	connections   systemConnections
+	setDevicesCnt int
+	setFolderCnt  int
+}
+
+var _ syncthingAPI = &fakeAPI{}
+
+func (f *fakeAPI) SystemStatus(ctx context.Context) (*systemStatus, error) {
+	return &systemStatus{MyID: f.myID}, nil
+}
+
+func (f *fakeAPI) Connections(ctx context.Context) (*systemConnections, error) {
+	return &f.connections, nil
+}
+
+func (f *fakeAPI) Devices(ctx context.Context) ([]device, error) {
+	return f.devices, nil
+}
+
+func (f *fakeAPI) SetDevices(ctx context.Context, devices []device) error {
+	f.setDevicesCnt++
+	f.devices = devices
+	return nil
+}
+
+func (f *fakeAPI) Folder(ctx context.Context, id string) (*folder, error) {
+	return &f.folder, nil
+}
+
+func (f *fakeAPI) SetFolderDevices(ctx context.Context, id string, devices []folderDevice) error {
+	f.setFolderCnt++
+	f.folder.Devices = devices
+	return nil
+}
+
+var _ = Describe("Syncthing properly registers", func() {
+	When("Syncthing's registration function is called", func() {
+		BeforeEach(func() {
+			Expect(Register()).To(Succeed())
+		})
+
+		It("is added to the mover catalog", func() {
+			found := false
+			for _, v := range mover.Catalog {
+				if _, ok := v.(*Builder); ok {
+					found = true
+				}
+			}
+			Expect(found).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("Syncthing inits flags and env vars", func() {
+	When("Syncthing builder inits flags", func() {
+		var builderForInitTests *Builder
+		var testPflagSet *pflag.FlagSet
+		BeforeEach(func() {
+			os.Unsetenv(syncthingContainerImageEnvVar)
+
+			// Instantiate new viper instance and flagSet just for this test
+			testViper := viper.New()
+			testFlagSet := flag.NewFlagSet("testflagsetsyncthing", flag.ExitOnError)
+
+			var err error
+			builderForInitTests, err = newBuilder(testViper, testFlagSet)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(builderForInitTests).NotTo(BeNil())
+
+			testPflagSet = pflag.NewFlagSet("testpflagsetsyncthing", pflag.ExitOnError)
+			testPflagSet.AddGoFlagSet(testFlagSet)
+			Expect(testViper.BindPFlags(testPflagSet)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.Unsetenv(syncthingContainerImageEnvVar)
+		})
+
+		Context("When no command line flag or ENV var is specified", func() {
+			It("Should use the default syncthing container image", func() {
+				Expect(builderForInitTests.getSyncthingContainerImage()).To(Equal(defaultSyncthingContainerImage))
+			})
+		})
+
+		Context("When syncthing container image command line flag is specified", func() {
+			const cmdLineOverrideImageName = "test-syncthing-image-name:cmdlineoverride"
+			BeforeEach(func() {
+				Expect(testPflagSet.Set("syncthing-container-image", cmdLineOverrideImageName)).To(Succeed())
+				os.Setenv(syncthingContainerImageEnvVar, "test-syncthing-image-name:donotuseme")
+			})
+			It("Should use the syncthing container image set by the cmd line flag", func() {
+				Expect(builderForInitTests.getSyncthingContainerImage()).To(Equal(cmdLineOverrideImageName))
+			})
+		})
+
+		Context("When syncthing container image cmd line flag is not set and env var is", func() {
+			const envVarOverrideImageName = "test-syncthing-image-name:setbyenvvar"
+			BeforeEach(func() {
+				os.Setenv(syncthingContainerImageEnvVar, envVarOverrideImageName)
+			})
+			It("Should use the value from the env var", func() {
+				Expect(builderForInitTests.getSyncthingContainerImage()).To(Equal(envVarOverrideImageName))
+			})
+		})
+	})
+})
+
+var _ = Describe("Syncthing ignores other movers", func() {
+	logger := zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter))
+	When("An RS isn't for syncthing", func() {
+		It("is ignored", func() {
+			rs := &volsyncv1alpha1.ReplicationSource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cr",
+					Namespace: "blah",
+				},
+				Spec: volsyncv1alpha1.ReplicationSourceSpec{
+					Syncthing: nil,
+				},
+			}
+			m, e := commonBuilderForTestSuite.FromSource(context.TODO(), k8sClient, logger, &record.FakeRecorder{}, rs)
+			Expect(m).To(BeNil())
+			Expect(e).NotTo(HaveOccurred())
+		})
+	})
+	When("An RD is provided", func() {
+		It("is ignored", func() {
+			rd := &volsyncv1alpha1.ReplicationDestination{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "x",
+					Namespace: "y",
+				},
+			}
+			m, e := commonBuilderForTestSuite.FromDestination(context.TODO(), k8sClient, logger, &record.FakeRecorder{}, rd)
+			Expect(m).To(BeNil())
+			Expect(e).NotTo(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("Syncthing device configuration", func() {
+	const myID = "MYID"
+	peers := []volsyncv1alpha1.SyncthingPeer{
+		{ID: "PEER1", Address: "tcp://1.1.1.1:22000", Introducer: true},
+		{ID: "PEER2", Address: "tcp://2.2.2.2:22000"},
+	}
+
+	It("keeps the local device and adds the peers", func() {
+		current := []device{{DeviceID: myID, Name: "me", Addresses: []string{"dynamic"}}}
+		desired := desiredDevices(myID, current, peers)
+		Expect(desired).To(HaveLen(3))
+		Expect(desired[0].DeviceID).To(Equal(myID))
+		Expect(desired[0].Name).To(Equal("me"))
+		Expect(desired[1]).To(Equal(device{DeviceID: "PEER1", Addresses: []string{"tcp://1.1.1.1:22000"}, Introducer: true}))
+		Expect(desired[2]).To(Equal(device{DeviceID: "PEER2", Addresses: []string{"tcp://2.2.2.2:22000"}}))
+		Expect(remoteDevices(myID, desired)).To(HaveLen(2))
+	})
+
+	It("removes devices that are no longer peers", func() {
+		current := []device{
+			{DeviceID: myID},
+			{DeviceID: "OLD", Addresses: []string{"tcp://3.3.3.3:22000"}},
+		}
+		desired := desiredDevices(myID, current, peers)
+		for _, d := range desired {
+			Expect(d.DeviceID).NotTo(Equal("OLD"))
+		}
+	})
+
+	It("retains devices added by an introducer", func() {
+		current := []device{
+			{DeviceID: myID},
+			{DeviceID: "INTRO1", IntroducedBy: "PEER1"},
+			{DeviceID: "INTRO2", IntroducedBy: "PEER2"},
+		}
+		desired := desiredDevices(myID, current, peers)
+		ids := []string{}
+		for _, d := range desired {
+			ids = append(ids, d.DeviceID)
+		}
+		Expect(ids).To(ContainElement("INTRO1"))
+		// PEER2 isn't an introducer, so its introductions are dropped
+		Expect(ids).NotTo(ContainElement("INTRO2"))
+	})
+
+	It("compares device lists independent of order", func() {
+		a := []device{{DeviceID: "A"}, {DeviceID: "B"}}
+		b := []device{{DeviceID: "B"}, {DeviceID: "A"}}
+		Expect(devicesEqual(a, b)).To(BeTrue())
+		Expect(devicesEqual(a, a[:1])).To(BeFalse())
+		Expect(folderDevicesEqual(desiredFolderDevices(a), desiredFolderDevices(b))).To(BeTrue())
+	})
+
+	It("generates a certificate for the requested names", func() {
+		names := []string{"svc", "svc.ns", "svc.ns.svc"}
+		certPEM, keyPEM, err := generateCertificate(names)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keyPEM).NotTo(BeEmpty())
+		block, _ := pem.Decode(certPEM)
+		Expect(block).NotTo(BeNil())
+		cert, err := x509.ParseCertificate(block.Bytes)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cert.DNSNames).To(Equal(names))
+	})
+
+	It("reuses the API client of each instance until its configuration changes", func() {
+		certPEM, _, err := generateCertificate([]string{"svc"})
+		Expect(err).NotTo(HaveOccurred())
+		cfg := apiConfig{URL: "https://10.0.0.1:8384", APIKey: "key", CertPEM: certPEM, ServerName: "svc"}
+		cache := newAPIClientCache(apiClientExpiry)
+		api, err := cache.get("ns/rs", cfg)
+		Expect(err).NotTo(HaveOccurred())
+		again, err := cache.get("ns/rs", cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(BeIdenticalTo(api))
+		other, err := cache.get("ns/other", cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(other).NotTo(BeIdenticalTo(api))
+
+		cfg.URL = "https://10.0.0.2:8384"
+		changed, err := cache.get("ns/rs", cfg)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).NotTo(BeIdenticalTo(api))
+		cache.remove("ns/rs")
+		Expect(cache.clients).NotTo(HaveKey("ns/rs"))
+	})
+
+	It("evicts the API clients of instances that are no longer polled", func() {
+		certPEM, _, err := generateCertificate([]string{"svc"})
+		Expect(err).NotTo(HaveOccurred())
+		cfg := apiConfig{URL: "https://10.0.0.1:8384", APIKey: "key", CertPEM: certPEM, ServerName: "svc"}
+		cache := newAPIClientCache(100 * time.Millisecond)
+		_, err = cache.get("ns/rs", cfg)
+		Expect(err).NotTo(HaveOccurred())
+		cached := func() int {
+			cache.mutex.Lock()
+			defer cache.mutex.Unlock()
+			return len(cache.clients)
+		}
+		Expect(cached()).To(Equal(1))
+		Eventually(cached, time.Second).Should(BeZero())
+		cache.mutex.Lock()
+		defer cache.mutex.Unlock()
+		Expect(cache.evictTimer).To(BeNil())
+	})
+})
+
+var _ = Describe("Syncthing as a source", func() {
+	var ctx = context.TODO()
+	var ns *corev1.Namespace
+	logger := zap.New(zap.UseDevMode(true), zap.WriteTo(GinkgoWriter))
+	var rs *volsyncv1alpha1.ReplicationSource
+	var sPVC *corev1.PersistentVolumeClaim
+	var m *Mover
+	var api *fakeAPI
+	BeforeEach(func() {
+		ns = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "st-",
+			},
+		}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+		Expect(ns.Name).NotTo(BeEmpty())
+
+		sc := "spvcsc"
+		sPVC = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "s",
+				Namespace: ns.Name,
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{
+					corev1.ReadWriteOnce,
+				},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						"storage": resource.MustParse("7Gi"),
+					},
+				},
+				StorageClassName: &sc,
+			},
+		}
+		Expect(k8sClient.Create(ctx, sPVC)).To(Succeed())
+
+		rs = &volsyncv1alpha1.ReplicationSource{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rs",
+				Namespace: ns.Name,
+			},
+			Spec: volsyncv1alpha1.ReplicationSourceSpec{
+				SourcePVC: sPVC.Name,
+				Syncthing: &volsyncv1alpha1.ReplicationSourceSyncthingSpec{
+					Peers: []volsyncv1alpha1.SyncthingPeer{
+						{ID: "PEER1", Address: "tcp://1.1.1.1:22000"},
+					},
+				},
+			},
+		}
+		api = &fakeAPI{
+			myID:    "MYID",
+			devices: []device{{DeviceID: "MYID", Name: "me"}},
+			folder:  folder{ID: folderID},
+			connections: systemConnections{Connections: map[string]connection{
+				"PEER1": {Connected: true, Address: "1.1.1.1:22000"},
+			}},
+		}
+	})
+	JustBeforeEach(func() {
+		Expect(k8sClient.Create(ctx, rs)).To(Succeed())
+		rs.Status = &volsyncv1alpha1.ReplicationSourceStatus{}
+		mm, err := commonBuilderForTestSuite.FromSource(context.TODO(), k8sClient, logger, &record.FakeRecorder{}, rs)
+		if rs.Spec.Trigger != nil {
+			Expect(err).To(HaveOccurred())
+			Expect(mm).To(BeNil())
+			return
+		}
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mm).NotTo(BeNil())
+		m, _ = mm.(*Mover)
+		Expect(m).NotTo(BeNil())
+		m.apiConnect = func(apiConfig) (syncthingAPI, error) { return api, nil }
+	})
+	AfterEach(func() {
+		Expect(k8sClient.Delete(ctx, ns)).To(Succeed())
+	})
+
+	When("a trigger is specified", func() {
+		BeforeEach(func() {
+			rs.Spec.Trigger = &volsyncv1alpha1.ReplicationSourceTriggerSpec{}
+		})
+		It("is rejected", func() {
+			Expect(m).To(BeNil())
+		})
+	})
+
+	It("creates the Syncthing resources", func() {
+		result, err := m.Synchronize(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Completed).To(BeFalse())
+
+		secret := &corev1.Secret{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "volsync-syncthing-rs", Namespace: ns.Name},
+			secret)).To(Succeed())
+		Expect(secret.Data).To(HaveKey(apiKeyField))
+		Expect(secret.Data).To(HaveKey(httpsCertPEM))
+		Expect(secret.Data).To(HaveKey(httpsKeyPEM))
+
+		configPVC := &corev1.PersistentVolumeClaim{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "volsync-rs-config", Namespace: ns.Name},
+			configPVC)).To(Succeed())
+		Expect(*configPVC.Spec.StorageClassName).To(Equal(*sPVC.Spec.StorageClassName))
+
+		deployment := &appsv1.Deployment{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "volsync-syncthing-rs", Namespace: ns.Name},
+			deployment)).To(Succeed())
+		Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+		Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal(defaultSyncthingContainerImage))
+
+		svc := &corev1.Service{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "volsync-syncthing-data-rs", Namespace: ns.Name},
+			svc)).To(Succeed())
+		Expect(svc.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+		Expect(rs.Status.Syncthing.Address).To(Equal("tcp://" + svc.Spec.ClusterIP + ":22000"))
+
+		// Secret contents are preserved across reconciles
+		apiKey := secret.Data[apiKeyField]
+		_, err = m.Synchronize(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+		Expect(secret.Data[apiKeyField]).To(Equal(apiKey))
+	})
+
+	When("the source is paused", func() {
+		BeforeEach(func() {
+			rs.Spec.Paused = true
+		})
+		It("scales the deployment down and marks the peers disconnected", func() {
+			_, err := m.Synchronize(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			deployment := &appsv1.Deployment{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, client.ObjectKey{Name: "volsync-syncthing-rs", Namespace: ns.Name},
+					deployment)
+			}, timeout, interval).Should(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(0)))
+			Expect(rs.Status.Syncthing.Peers).To(HaveLen(1))
+			Expect(rs.Status.Syncthing.Peers[0].Connected).To(BeFalse())
+		})
+	})
+
+	It("configures Syncthing to match the peers", func() {
+		devices, err := m.ensureConfigured(ctx, api)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rs.Status.Syncthing.DeviceID).To(Equal("MYID"))
+		Expect(devices).To(HaveLen(1))
+		Expect(api.devices).To(HaveLen(2))
+		Expect(api.folder.Devices).To(HaveLen(2))
+		Expect(api.setDevicesCnt).To(Equal(1))
+		Expect(api.setFolderCnt).To(Equal(1))
+
+		// Already configured, so nothing changes
+		_, err = m.ensureConfigured(ctx, api)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(api.setDevicesCnt).To(Equal(1))
+		Expect(api.setFolderCnt).To(Equal(1))
+
+		Expect(m.updatePeerStatus(ctx, api, devices)).To(Succeed())
+		Expect(rs.Status.Syncthing.Peers).To(ConsistOf(volsyncv1alpha1.SyncthingPeerStatus{
+			ID:        "PEER1",
+			Address:   "1.1.1.1:22000",
+			Connected: true,
+		}))
+	})
+})
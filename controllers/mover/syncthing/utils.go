@@ -0,0 +1,167 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package syncthing
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"reflect"
+	"sort"
+	"time"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+)
+
+// Validity period of the generated API certificate
+const certValidity = 10 * 365 * 24 * time.Hour
+
+// generateAPIKey returns a random key to secure the Syncthing API
+func generateAPIKey() ([]byte, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	key := make([]byte, base64.RawURLEncoding.EncodedLen(len(raw)))
+	base64.RawURLEncoding.Encode(key, raw)
+	return key, nil
+}
+
+// generateCertificate creates a self-signed certificate (and its key) for the
+// Syncthing API, valid for the provided DNS names. Both are PEM-encoded.
+func generateCertificate(dnsNames []string) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: dnsNames[0], Organization: []string{"VolSync"}},
+		DNSNames:              dnsNames,
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// desiredDevices determines the list of Syncthing devices based on the
+// current configuration and the peers in the spec. The local device is always
+// retained, as are devices that were added by one of the introducer peers.
+func desiredDevices(myID string, current []device, peers []volsyncv1alpha1.SyncthingPeer) []device {
+	existing := map[string]device{}
+	for _, d := range current {
+		existing[d.DeviceID] = d
+	}
+
+	devices := []device{}
+	if self, ok := existing[myID]; ok {
+		devices = append(devices, self)
+	}
+
+	wanted := map[string]bool{myID: true}
+	introducers := map[string]bool{}
+	for _, p := range peers {
+		if wanted[p.ID] {
+			continue
+		}
+		wanted[p.ID] = true
+		if p.Introducer {
+			introducers[p.ID] = true
+		}
+		devices = append(devices, device{
+			DeviceID:   p.ID,
+			Name:       existing[p.ID].Name,
+			Addresses:  []string{p.Address},
+			Introducer: p.Introducer,
+		})
+	}
+
+	for _, d := range current {
+		if !wanted[d.DeviceID] && d.IntroducedBy != "" && introducers[d.IntroducedBy] {
+			wanted[d.DeviceID] = true
+			devices = append(devices, d)
+		}
+	}
+	return devices
+}
+
+// remoteDevices returns all devices except the local one
+func remoteDevices(myID string, devices []device) []device {
+	remote := []device{}
+	for _, d := range devices {
+		if d.DeviceID != myID {
+			remote = append(remote, d)
+		}
+	}
+	return remote
+}
+
+func devicesEqual(a []device, b []device) bool {
+	return reflect.DeepEqual(sortedDevices(a), sortedDevices(b))
+}
+
+func sortedDevices(devices []device) []device {
+	sorted := append([]device{}, devices...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].DeviceID < sorted[j].DeviceID })
+	return sorted
+}
+
+// desiredFolderDevices is the list of devices the folder should be shared with
+func desiredFolderDevices(devices []device) []folderDevice {
+	shares := make([]folderDevice, 0, len(devices))
+	for _, d := range devices {
+		shares = append(shares, folderDevice{
+			DeviceID:     d.DeviceID,
+			IntroducedBy: d.IntroducedBy,
+		})
+	}
+	return shares
+}
+
+func folderDevicesEqual(a []folderDevice, b []folderDevice) bool {
+	sortFn := func(fd []folderDevice) []folderDevice {
+		sorted := append([]folderDevice{}, fd...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].DeviceID < sorted[j].DeviceID })
+		return sorted
+	}
+	return reflect.DeepEqual(sortFn(a), sortFn(b))
+}
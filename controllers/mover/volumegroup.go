@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mover
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+)
+
+// SourceVolumeGroupStatus describes a volume group that was replicated from
+// pvcs, the point-in-time copies of its members. The group's time is when the
+// earliest copy was created, or now if the members were replicated directly.
+func SourceVolumeGroupStatus(members []string,
+	pvcs []*corev1.PersistentVolumeClaim) *volsyncv1alpha1.VolumeGroupStatus {
+	now := metav1.Now()
+	status := &volsyncv1alpha1.VolumeGroupStatus{Time: &now}
+	for i, pvc := range pvcs {
+		status.Members = append(status.Members, volsyncv1alpha1.VolumeGroupMember{PVCName: members[i]})
+		if pvc.Name == members[i] {
+			continue
+		}
+		if created := pvc.CreationTimestamp; !created.IsZero() && created.Before(status.Time) {
+			status.Time = &created
+		}
+	}
+	return status
+}
+
+// DestinationVolumeGroupStatus describes a volume group that was replicated
+// into the PVCs, members, and then preserved as images
+func DestinationVolumeGroupStatus(members []string,
+	images []*corev1.TypedLocalObjectReference) *volsyncv1alpha1.VolumeGroupStatus {
+	now := metav1.Now()
+	status := &volsyncv1alpha1.VolumeGroupStatus{Time: &now}
+	for i := range members {
+		status.Members = append(status.Members, volsyncv1alpha1.VolumeGroupMember{
+			PVCName: members[i],
+			Image:   images[i],
+		})
+	}
+	return status
+}
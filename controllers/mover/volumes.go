@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package mover
+
+import (
+	"fmt"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DataVolumes returns the Volumes, VolumeMounts, and VolumeDevices that present
+// the data PVCs to the mover container. When members is empty, the single PVC
+// is mounted at mountPath or, if it's a Block volume, attached as a device at
+// devicePath. Otherwise, pvcs[i] holds the data of the volume group member,
+// members[i], and it is presented at a path that is named after the member.
+func DataVolumes(volumeName string, mountPath string, devicePath string, members []string,
+	pvcs []*corev1.PersistentVolumeClaim) ([]corev1.Volume, []corev1.VolumeMount, []corev1.VolumeDevice) {
+	volumes := []corev1.Volume{}
+	mounts := []corev1.VolumeMount{}
+	devices := []corev1.VolumeDevice{}
+	for i, pvc := range pvcs {
+		name := volumeName
+		dir := mountPath
+		device := devicePath
+		if len(members) > 0 {
+			name = fmt.Sprintf("%s-%d", volumeName, i)
+			dir = path.Join(mountPath, members[i])
+			device = devicePath + "-" + members[i]
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: pvc.Name,
+				},
+			},
+		})
+		if IsBlockVolume(pvc) {
+			devices = append(devices, corev1.VolumeDevice{Name: name, DevicePath: device})
+		} else {
+			mounts = append(mounts, corev1.VolumeMount{Name: name, MountPath: dir})
+		}
+	}
+	return volumes, mounts, devices
+}
+
+// IsBlockVolume returns true if the PVC is a raw block volume
+func IsBlockVolume(pvc *corev1.PersistentVolumeClaim) bool {
+	return pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock
+}
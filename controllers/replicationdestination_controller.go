@@ -34,9 +34,11 @@ import (
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
 	"github.com/backube/volsync/controllers/mover"
@@ -46,8 +48,12 @@ import (
 // ReplicationDestinationReconciler reconciles a ReplicationDestination object
 type ReplicationDestinationReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log           logr.Logger
+	Scheme        *runtime.Scheme
+	EventRecorder record.EventRecorder
+	// SyncLimiter limits the number of concurrent synchronizations. It may
+	// be shared with other reconcilers.
+	SyncLimiter *SyncLimiter
 }
 
 //nolint:lll
@@ -55,6 +61,9 @@ type ReplicationDestinationReconciler struct {
 //+kubebuilder:rbac:groups=volsync.backube,resources=replicationdestinations/finalizers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=volsync.backube,resources=replicationdestinations/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;deletecollection
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
@@ -72,9 +81,18 @@ func (r *ReplicationDestinationReconciler) Reconcile(ctx context.Context, req ct
 	if err := r.Client.Get(ctx, req.NamespacedName, inst); err != nil {
 		if !kerrors.IsNotFound(err) {
 			logger.Error(err, "Failed to get Destination")
+		} else {
+			r.SyncLimiter.Release(syncLimiterKey("ReplicationDestination", req.Namespace, req.Name))
 		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	if !inst.DeletionTimestamp.IsZero() {
+		r.SyncLimiter.Release(syncLimiterKey("ReplicationDestination", inst.Namespace, inst.Name))
+		return r.reconcileDeletion(ctx, inst, logger)
+	}
+	if err := updateDeletionFinalizer(ctx, r.Client, inst, inst.Spec.DeletionPolicy); err != nil {
+		return ctrl.Result{}, err
+	}
 	// Prepare the .Status fields if necessary
 	if inst.Status == nil {
 		inst.Status = &volsyncv1alpha1.ReplicationDestinationStatus{}
@@ -104,10 +122,14 @@ func (r *ReplicationDestinationReconciler) Reconcile(ctx context.Context, req ct
 			Message: "Reconcile complete",
 		})
 	} else {
+		reason := volsyncv1alpha1.ReconciledReasonError
+		if errors.Is(err, utils.ErrVolumeInUse) {
+			reason = volsyncv1alpha1.ReconciledReasonVolumeInUse
+		}
 		apimeta.SetStatusCondition(&inst.Status.Conditions, metav1.Condition{
 			Type:    volsyncv1alpha1.ConditionReconciled,
 			Status:  metav1.ConditionFalse,
-			Reason:  volsyncv1alpha1.ReconciledReasonError,
+			Reason:  reason,
 			Message: err.Error(),
 		})
 	}
@@ -128,28 +150,89 @@ func (r *ReplicationDestinationReconciler) Reconcile(ctx context.Context, req ct
 	return result, err
 }
 
-//nolint:funlen
-func reconcileDestUsingCatalog(
+// destinationMover searches the Mover catalog for the data mover of the
+// ReplicationDestination
+func destinationMover(
 	ctx context.Context,
 	instance *volsyncv1alpha1.ReplicationDestination,
 	dr *ReplicationDestinationReconciler,
 	logger logr.Logger,
-) (ctrl.Result, error) {
-	// Search the Mover catalog for a suitable data mover
+) (mover.Mover, error) {
 	var dataMover mover.Mover
 	for _, builder := range mover.Catalog {
-		if candidate, err := builder.FromDestination(dr.Client, logger, instance); err == nil && candidate != nil {
+		candidate, err := builder.FromDestination(ctx, dr.Client, logger, dr.EventRecorder, instance)
+		if err != nil {
+			// The mover recognized the CR, but it's not valid
+			return nil, err
+		}
+		if candidate != nil {
 			if dataMover != nil {
 				// Found 2 movers claiming this CR...
-				return ctrl.Result{}, fmt.Errorf("only a single replication method can be provided")
+				return nil, fmt.Errorf("only a single replication method can be provided")
 			}
 			dataMover = candidate
 		}
 	}
 	if dataMover == nil { // No mover matched
-		return ctrl.Result{}, errNoMoverFound
+		return nil, errNoMoverFound
+	}
+	return dataMover, nil
+}
+
+// reconcileDeletion removes the replicated data and the retained images of a
+// ReplicationDestination that is being deleted with a deletionPolicy of Delete
+func (r *ReplicationDestinationReconciler) reconcileDeletion(ctx context.Context,
+	inst *volsyncv1alpha1.ReplicationDestination, logger logr.Logger) (ctrl.Result, error) {
+	if !ctrlutil.ContainsFinalizer(inst, deletionFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	if inst.Status == nil {
+		inst.Status = &volsyncv1alpha1.ReplicationDestinationStatus{}
+	}
+
+	dataMover, err := destinationMover(ctx, inst, r, logger)
+	if err != nil && !errors.Is(err, errNoMoverFound) {
+		return ctrl.Result{}, err
+	}
+	done, result, err := deleteMoverData(ctx, logger, dataMover)
+	if !done || err != nil {
+		return result, err
 	}
 
+	images := []*corev1.TypedLocalObjectReference{inst.Status.LatestImage}
+	for i := range inst.Status.Images {
+		images = append(images, &inst.Status.Images[i].Image)
+	}
+	if group := inst.Status.VolumeGroup; group != nil {
+		for _, member := range group.Members {
+			images = append(images, member.Image)
+		}
+	}
+	for _, image := range images {
+		if err := utils.DeleteSnapshot(ctx, r.Client, logger, inst, image); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	r.EventRecorder.Event(inst, corev1.EventTypeNormal, utils.EvRDataDeleted,
+		"replicated data has been removed")
+	ctrlutil.RemoveFinalizer(inst, deletionFinalizer)
+	return ctrl.Result{}, r.Client.Update(ctx, inst)
+}
+
+//nolint:funlen
+func reconcileDestUsingCatalog(
+	ctx context.Context,
+	instance *volsyncv1alpha1.ReplicationDestination,
+	dr *ReplicationDestinationReconciler,
+	logger logr.Logger,
+) (ctrl.Result, error) {
+	dataMover, err := destinationMover(ctx, instance, dr, logger)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	instance.Status.MoverVersion = dataMover.VersionInfo()
+
 	metrics := newVolSyncMetrics(prometheus.Labels{
 		"obj_name":      instance.Name,
 		"obj_namespace": instance.Namespace,
@@ -163,32 +246,89 @@ func reconcileDestUsingCatalog(
 	}
 
 	var result mover.Result
+	limiterKey := syncLimiterKey("ReplicationDestination", instance.Namespace, instance.Name)
+	limiter := dr.SyncLimiter
 	if shouldSync && !apimeta.IsStatusConditionFalse(instance.Status.Conditions, volsyncv1alpha1.ConditionSynchronizing) {
+		started := instance.Status.LastSyncStartTime != nil
+		if instance.Spec.Paused {
+			// A paused synchronization doesn't make progress, so it gives up
+			// its slot
+			limiter.Pause(limiterKey)
+		} else if !limiter.Admit(limiterKey, instance.Namespace, started) {
+			apimeta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+				Type:    volsyncv1alpha1.ConditionSynchronizing,
+				Status:  metav1.ConditionTrue,
+				Reason:  volsyncv1alpha1.SynchronizingReasonQueued,
+				Message: "Waiting for other synchronizations to complete",
+			})
+			return ctrl.Result{RequeueAfter: queuedRequeueInterval}, nil
+		}
+		if wait := retryBackoffRemaining(instance.Spec.RetryPolicy, instance.Status.LastSyncResult, time.Now()); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+		if !started {
+			dr.EventRecorder.Event(instance, corev1.EventTypeNormal, utils.EvRSyncStarted,
+				"Synchronization started")
+		}
 		updateLastSyncStartTimeDestination(instance) // Make sure lastSyncStartTime is set
 
 		result, err = dataMover.Synchronize(ctx)
-		if result.Completed && result.Image != nil {
-			// Mark previous latestImage for cleanup if it was a snapshot
-			err = utils.MarkOldSnapshotForCleanup(ctx, dr.Client, logger, instance,
-				instance.Status.LatestImage, result.Image)
-			if err != nil {
-				return mover.InProgress().ReconcileResult(), err
+		if result.Failure != nil {
+			metrics.JobFailures.Inc()
+			recordSyncFailure(&instance.Status.LastSyncResult, &instance.Status.Conditions, result.Failure)
+			if retriesExhausted(instance.Spec.RetryPolicy, instance.Status.LastSyncResult) {
+				limiter.Release(limiterKey)
+				return mover.InProgress().ReconcileResult(), abandonExhaustedSyncDestination(ctx, instance, dr, metrics, logger)
+			}
+		}
+		if result.Completed && (result.Image != nil || result.VolumeGroup != nil) {
+			var message string
+			if result.VolumeGroup != nil {
+				// Replace the group's images, marking the previous ones for
+				// cleanup
+				if err = updateVolumeGroup(ctx, dr.Client, logger, instance, result.VolumeGroup); err != nil {
+					return mover.InProgress().ReconcileResult(), err
+				}
+				message = fmt.Sprintf("Synchronization completed, latest images of the %d PVCs in the volume group are available",
+					len(result.VolumeGroup.Members))
+			} else {
+				// Add the new image to the history & mark the images that are
+				// no longer retained for cleanup
+				if err = retainImages(ctx, dr.Client, logger, instance, result.Image); err != nil {
+					return mover.InProgress().ReconcileResult(), err
+				}
+				instance.Status.LatestImage = result.Image
+				message = fmt.Sprintf("Synchronization completed, latest image is %s %s",
+					result.Image.Kind, result.Image.Name)
 			}
 
-			instance.Status.LatestImage = result.Image
+			recordSyncSuccess(&instance.Status.LastSyncResult, &instance.Status.Conditions)
 			apimeta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
 				Type:    volsyncv1alpha1.ConditionSynchronizing,
 				Status:  metav1.ConditionFalse,
 				Reason:  volsyncv1alpha1.SynchronizingReasonCleanup,
 				Message: "Cleaning up",
 			})
+			limiter.Release(limiterKey)
+			dr.EventRecorder.Event(instance, corev1.EventTypeNormal, utils.EvRSyncCompleted, message)
 			if ok, err := updateLastSyncDestination(instance, metrics, logger); !ok {
 				return mover.InProgress().ReconcileResult(), err
 			}
+			metrics.recordSyncCompletion(instance.Status.LastSyncTime, instance.Status.LastSyncDuration, result.Stats)
+			if r := instance.Status.Restic; r != nil {
+				metrics.recordRepositoryStats(r.Repository)
+			}
 		}
 	} else {
+		cond := apimeta.FindStatusCondition(instance.Status.Conditions, volsyncv1alpha1.ConditionSynchronizing)
+		wasCleaningUp := cond != nil && cond.Reason == volsyncv1alpha1.SynchronizingReasonCleanup
+		limiter.Release(limiterKey)
 		result, err = dataMover.Cleanup(ctx)
 		if result.Completed {
+			if wasCleaningUp {
+				dr.EventRecorder.Event(instance, corev1.EventTypeNormal, utils.EvRCleanupCompleted,
+					"Temporary resources from the synchronization have been removed")
+			}
 			apimeta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
 				Type:    volsyncv1alpha1.ConditionSynchronizing,
 				Status:  metav1.ConditionTrue,
@@ -202,6 +342,102 @@ func reconcileDestUsingCatalog(
 	return result.ReconcileResult(), err
 }
 
+// updateVolumeGroup records the newly synchronized volume group in the status.
+// The previous image of each member is marked for cleanup.
+func updateVolumeGroup(ctx context.Context, c client.Client, logger logr.Logger,
+	rd *volsyncv1alpha1.ReplicationDestination, group *volsyncv1alpha1.VolumeGroupStatus) error {
+	if old := rd.Status.VolumeGroup; old != nil {
+		for _, oldMember := range old.Members {
+			for _, member := range group.Members {
+				if member.PVCName != oldMember.PVCName {
+					continue
+				}
+				if err := utils.MarkOldSnapshotForCleanup(ctx, c, logger, rd,
+					oldMember.Image, member.Image); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	rd.Status.VolumeGroup = group
+	return nil
+}
+
+// retainImages adds latestImage to the list of retained images in the
+// ReplicationDestination's status, applying the image retention policy. Images
+// that are no longer retained are marked to be removed during cleanup.
+func retainImages(ctx context.Context, c client.Client, logger logr.Logger,
+	rd *volsyncv1alpha1.ReplicationDestination, latestImage *corev1.TypedLocalObjectReference) error {
+	images := rd.Status.Images
+	if len(images) == 0 && rd.Status.LatestImage != nil && rd.Status.LastSyncTime != nil {
+		// Start tracking the image from before the history was recorded
+		images = []volsyncv1alpha1.RetainedImage{{
+			Image:        *rd.Status.LatestImage,
+			CreationTime: *rd.Status.LastSyncTime,
+		}}
+	}
+	images = append([]volsyncv1alpha1.RetainedImage{{
+		Image:        *latestImage,
+		CreationTime: metav1.Now(),
+	}}, images...)
+
+	keep, discard := selectRetainedImages(rd.Spec.ImageRetain, images)
+	for i := range discard {
+		if discard[i].Image.Name == latestImage.Name {
+			continue
+		}
+		if err := utils.MarkSnapshotForCleanup(ctx, c, logger, rd, &discard[i].Image); err != nil {
+			return err
+		}
+	}
+	rd.Status.Images = keep
+	return nil
+}
+
+// abandonExhaustedSyncDestination stops a synchronization that has failed on
+// each of the attempts allowed by its retryPolicy. The mover's resources are
+// removed by the cleanup that follows, and the next synchronization begins at
+// the next scheduled time. Replication is paused if the retryPolicy requests
+// it.
+func abandonExhaustedSyncDestination(
+	ctx context.Context,
+	rd *volsyncv1alpha1.ReplicationDestination,
+	dr *ReplicationDestinationReconciler,
+	metrics volsyncMetrics,
+	logger logr.Logger,
+) error {
+	logger.Info("synchronization retries exhausted", "attempts", rd.Status.LastSyncResult.Attempts)
+	recordRetriesExhausted(rd.Status.LastSyncResult, &rd.Status.Conditions)
+	dr.EventRecorder.Eventf(rd, corev1.EventTypeWarning, utils.EvRRetriesExhausted,
+		"Synchronization failed after %d attempts and has been stopped", rd.Status.LastSyncResult.Attempts)
+	if rd.Spec.RetryPolicy.OnExhausted == volsyncv1alpha1.RetryExhaustedPause {
+		// Updating the object replaces the in-memory status
+		status := rd.Status
+		rd.Spec.Paused = true
+		err := dr.Client.Update(ctx, rd)
+		rd.Status = status
+		if err != nil {
+			return err
+		}
+		dr.EventRecorder.Event(rd, corev1.EventTypeWarning, utils.EvRRetriesExhausted,
+			"Replication has been paused")
+	}
+
+	apimeta.SetStatusCondition(&rd.Status.Conditions, metav1.Condition{
+		Type:    volsyncv1alpha1.ConditionSynchronizing,
+		Status:  metav1.ConditionFalse,
+		Reason:  volsyncv1alpha1.SynchronizingReasonCleanup,
+		Message: "Cleaning up",
+	})
+	rd.Status.LastSyncStartTime = nil
+	// The manual trigger has been handled, even though it was unsuccessful
+	if rd.Spec.Trigger != nil {
+		rd.Status.LastManualSync = rd.Spec.Trigger.Manual
+	}
+	_, err := updateNextSyncDestination(rd, metrics, logger)
+	return err
+}
+
 func (r *ReplicationDestinationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&volsyncv1alpha1.ReplicationDestination{}).
@@ -246,6 +482,11 @@ func updateNextSyncDestination(
 		} else { // Never synced before, so we should ASAP
 			rd.Status.NextSyncTime = &metav1.Time{Time: time.Now()}
 		}
+		// A sync that was abandoned isn't retried until the following
+		// scheduled time
+		if t := abandonedAt(rd.Status.LastSyncResult); t != nil && rd.Status.NextSyncTime.Before(t) {
+			rd.Status.NextSyncTime = &metav1.Time{Time: schedule.Next(t.Time)}
+		}
 	} else { // No schedule, so there's no "next"
 		rd.Status.NextSyncTime = nil
 	}
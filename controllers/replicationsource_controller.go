@@ -27,6 +27,7 @@ import (
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	cron "github.com/robfig/cron/v3"
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -34,19 +35,27 @@ import (
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
 	"github.com/backube/volsync/controllers/mover"
+	"github.com/backube/volsync/controllers/utils"
+	"github.com/backube/volsync/controllers/volumehandler"
 )
 
 // ReplicationSourceReconciler reconciles a ReplicationSource object
 type ReplicationSourceReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log           logr.Logger
+	Scheme        *runtime.Scheme
+	EventRecorder record.EventRecorder
+	// SyncLimiter limits the number of concurrent synchronizations. It may
+	// be shared with other reconcilers.
+	SyncLimiter *SyncLimiter
 }
 
 //nolint:lll
@@ -54,7 +63,13 @@ type ReplicationSourceReconciler struct {
 //+kubebuilder:rbac:groups=volsync.backube,resources=replicationsources,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=volsync.backube,resources=replicationsources/finalizers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=volsync.backube,resources=replicationsources/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;deletecollection
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create
+//+kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete;deletecollection
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
@@ -71,10 +86,22 @@ func (r *ReplicationSourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	if err := r.Client.Get(ctx, req.NamespacedName, inst); err != nil {
 		if kerrors.IsNotFound(err) {
 			logger.Error(err, "Failed to get Source")
+			r.SyncLimiter.Release(syncLimiterKey("ReplicationSource", req.Namespace, req.Name))
 		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if !inst.DeletionTimestamp.IsZero() {
+		r.SyncLimiter.Release(syncLimiterKey("ReplicationSource", inst.Namespace, inst.Name))
+		return r.reconcileDeletion(ctx, inst, logger)
+	}
+	if err := updateDeletionFinalizer(ctx, r.Client, inst, inst.Spec.DeletionPolicy); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := updateFinalizer(ctx, r.Client, inst, hooksFinalizer, inst.Spec.Hooks != nil); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if inst.Status == nil {
 		inst.Status = &volsyncv1alpha1.ReplicationSourceStatus{}
 	}
@@ -103,10 +130,14 @@ func (r *ReplicationSourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			Message: "Reconcile complete",
 		})
 	} else {
+		reason := volsyncv1alpha1.ReconciledReasonError
+		if errors.Is(err, utils.ErrVolumeInUse) {
+			reason = volsyncv1alpha1.ReconciledReasonVolumeInUse
+		}
 		apimeta.SetStatusCondition(&inst.Status.Conditions, metav1.Condition{
 			Type:    volsyncv1alpha1.ConditionReconciled,
 			Status:  metav1.ConditionFalse,
-			Reason:  volsyncv1alpha1.ReconciledReasonError,
+			Reason:  reason,
 			Message: err.Error(),
 		})
 	}
@@ -129,27 +160,102 @@ func (r *ReplicationSourceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 var errNoMoverFound = fmt.Errorf("no matching data mover was found")
 
-//nolint:funlen
-func reconcileSrcUsingCatalog(
+// sourceMover searches the Mover catalog for the data mover of the
+// ReplicationSource
+func sourceMover(
 	ctx context.Context,
 	instance *volsyncv1alpha1.ReplicationSource,
 	sr *ReplicationSourceReconciler,
 	logger logr.Logger,
-) (ctrl.Result, error) {
-	// Search the Mover catalog for a suitable data mover
+) (mover.Mover, error) {
 	var dataMover mover.Mover
 	for _, builder := range mover.Catalog {
-		if candidate, err := builder.FromSource(sr.Client, logger, instance); err == nil && candidate != nil {
+		candidate, err := builder.FromSource(ctx, sr.Client, logger, sr.EventRecorder, instance)
+		if err != nil {
+			// The mover recognized the CR, but it's not valid
+			return nil, err
+		}
+		if candidate != nil {
 			if dataMover != nil {
 				// Found 2 movers claiming this CR...
-				return ctrl.Result{}, fmt.Errorf("only a single replication method can be provided")
+				return nil, fmt.Errorf("only a single replication method can be provided")
 			}
 			dataMover = candidate
 		}
 	}
 	if dataMover == nil { // No mover matched
-		return ctrl.Result{}, errNoMoverFound
+		return nil, errNoMoverFound
+	}
+	return dataMover, nil
+}
+
+// reconcileDeletion runs the post hooks that are still pending and removes the
+// replicated data of a ReplicationSource that is being deleted with a
+// deletionPolicy of Delete
+func (r *ReplicationSourceReconciler) reconcileDeletion(ctx context.Context,
+	inst *volsyncv1alpha1.ReplicationSource, logger logr.Logger) (ctrl.Result, error) {
+	if ctrlutil.ContainsFinalizer(inst, hooksFinalizer) {
+		if err := releaseCopyHooks(ctx, inst, r, logger); err != nil {
+			return ctrl.Result{}, err
+		}
+		ctrlutil.RemoveFinalizer(inst, hooksFinalizer)
+		if err := r.Client.Update(ctx, inst); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if !ctrlutil.ContainsFinalizer(inst, deletionFinalizer) {
+		return ctrl.Result{}, nil
+	}
+	if inst.Status == nil {
+		inst.Status = &volsyncv1alpha1.ReplicationSourceStatus{}
+	}
+
+	dataMover, err := sourceMover(ctx, inst, r, logger)
+	if err != nil && !errors.Is(err, errNoMoverFound) {
+		return ctrl.Result{}, err
+	}
+	done, result, err := deleteMoverData(ctx, logger, dataMover)
+	if !done || err != nil {
+		return result, err
+	}
+
+	r.EventRecorder.Event(inst, corev1.EventTypeNormal, utils.EvRDataDeleted,
+		"replicated data has been removed")
+	ctrlutil.RemoveFinalizer(inst, deletionFinalizer)
+	return ctrl.Result{}, r.Client.Update(ctx, inst)
+}
+
+// releaseCopyHooks runs the post hooks of the ReplicationSource's copies that
+// won't be taken, since the synchronization has ended or can't make progress
+func releaseCopyHooks(ctx context.Context, rs *volsyncv1alpha1.ReplicationSource,
+	sr *ReplicationSourceReconciler, logger logr.Logger) error {
+	if rs.Spec.Hooks == nil {
+		return nil
+	}
+	vh, err := volumehandler.NewVolumeHandler(
+		volumehandler.WithClient(sr.Client),
+		volumehandler.WithRecorder(sr.EventRecorder),
+		volumehandler.WithOwner(rs),
+		volumehandler.WithHooks(rs.Spec.Hooks),
+	)
+	if err != nil {
+		return err
+	}
+	return vh.ReleaseCopyHooks(ctx, logger)
+}
+
+//nolint:funlen
+func reconcileSrcUsingCatalog(
+	ctx context.Context,
+	instance *volsyncv1alpha1.ReplicationSource,
+	sr *ReplicationSourceReconciler,
+	logger logr.Logger,
+) (ctrl.Result, error) {
+	dataMover, err := sourceMover(ctx, instance, sr, logger)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
+	instance.Status.MoverVersion = dataMover.VersionInfo()
 
 	metrics := newVolSyncMetrics(prometheus.Labels{
 		"obj_name":      instance.Name,
@@ -163,24 +269,90 @@ func reconcileSrcUsingCatalog(
 	}
 
 	var mResult mover.Result
+	limiterKey := syncLimiterKey("ReplicationSource", instance.Namespace, instance.Name)
+	limiter := sr.SyncLimiter
+	if instance.Spec.Syncthing != nil {
+		// Syncthing runs continuously and never completes a synchronization,
+		// so it would hold its slot forever
+		limiter = nil
+	}
 	if shouldSync && !apimeta.IsStatusConditionFalse(instance.Status.Conditions, volsyncv1alpha1.ConditionSynchronizing) {
+		started := instance.Status.LastSyncStartTime != nil
+		if instance.Spec.Paused {
+			// A paused synchronization doesn't make progress, so it gives up
+			// its slot and doesn't keep the application quiesced
+			limiter.Pause(limiterKey)
+			if err := releaseCopyHooks(ctx, instance, sr, logger); err != nil {
+				return ctrl.Result{}, err
+			}
+		} else if !limiter.Admit(limiterKey, instance.Namespace, started) {
+			apimeta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
+				Type:    volsyncv1alpha1.ConditionSynchronizing,
+				Status:  metav1.ConditionTrue,
+				Reason:  volsyncv1alpha1.SynchronizingReasonQueued,
+				Message: "Waiting for other synchronizations to complete",
+			})
+			return ctrl.Result{RequeueAfter: queuedRequeueInterval}, nil
+		}
+		if syncTimedOut(instance.Spec.SyncTimeout, instance.Spec.Paused, instance.Status.LastSyncStartTime, time.Now()) {
+			metrics.JobFailures.Inc()
+			limiter.Release(limiterKey)
+			return mover.InProgress().ReconcileResult(), abortTimedOutSyncSource(instance, sr, metrics, logger)
+		}
+		if wait := retryBackoffRemaining(instance.Spec.RetryPolicy, instance.Status.LastSyncResult, time.Now()); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+		if !started {
+			sr.EventRecorder.Event(instance, corev1.EventTypeNormal, utils.EvRSyncStarted,
+				"Synchronization started")
+		}
 		updateLastSyncStartTimeSource(instance) // Make sure lastSyncStartTime is set
 
 		mResult, err = dataMover.Synchronize(ctx)
+		if mResult.Failure != nil {
+			metrics.JobFailures.Inc()
+			recordSyncFailure(&instance.Status.LastSyncResult, &instance.Status.Conditions, mResult.Failure)
+			if retriesExhausted(instance.Spec.RetryPolicy, instance.Status.LastSyncResult) {
+				limiter.Release(limiterKey)
+				return mover.InProgress().ReconcileResult(), abandonExhaustedSyncSource(ctx, instance, sr, metrics, logger)
+			}
+		}
 		if mResult.Completed {
+			instance.Status.VolumeGroup = mResult.VolumeGroup
+			recordSyncSuccess(&instance.Status.LastSyncResult, &instance.Status.Conditions)
 			apimeta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
 				Type:    volsyncv1alpha1.ConditionSynchronizing,
 				Status:  metav1.ConditionFalse,
 				Reason:  volsyncv1alpha1.SynchronizingReasonCleanup,
 				Message: "Cleaning up",
 			})
+			limiter.Release(limiterKey)
+			sr.EventRecorder.Event(instance, corev1.EventTypeNormal, utils.EvRSyncCompleted,
+				"Synchronization completed")
 			if ok, err := updateLastSyncSource(instance, metrics, logger); !ok {
 				return mover.InProgress().ReconcileResult(), err
 			}
+			metrics.recordSyncCompletion(instance.Status.LastSyncTime, instance.Status.LastSyncDuration, mResult.Stats)
+			metrics.recordRepositoryCheck(instance.Status.Restic)
+			if r := instance.Status.Restic; r != nil {
+				metrics.recordRepositoryStats(r.Repository)
+			}
 		}
 	} else {
+		cond := apimeta.FindStatusCondition(instance.Status.Conditions, volsyncv1alpha1.ConditionSynchronizing)
+		wasCleaningUp := cond != nil && cond.Reason == volsyncv1alpha1.SynchronizingReasonCleanup
+		limiter.Release(limiterKey)
+		// The copies of a synchronization that was abandoned are removed
+		// without having been taken
+		if err := releaseCopyHooks(ctx, instance, sr, logger); err != nil {
+			return ctrl.Result{}, err
+		}
 		mResult, err = dataMover.Cleanup(ctx)
 		if mResult.Completed {
+			if wasCleaningUp {
+				sr.EventRecorder.Event(instance, corev1.EventTypeNormal, utils.EvRCleanupCompleted,
+					"Temporary resources from the synchronization have been removed")
+			}
 			apimeta.SetStatusCondition(&instance.Status.Conditions, metav1.Condition{
 				Type:    volsyncv1alpha1.ConditionSynchronizing,
 				Status:  metav1.ConditionTrue,
@@ -194,12 +366,80 @@ func reconcileSrcUsingCatalog(
 	return mResult.ReconcileResult(), err
 }
 
+// abortTimedOutSyncSource stops a synchronization that has exceeded its
+// syncTimeout
+func abortTimedOutSyncSource(
+	rs *volsyncv1alpha1.ReplicationSource,
+	sr *ReplicationSourceReconciler,
+	metrics volsyncMetrics,
+	logger logr.Logger,
+) error {
+	logger.Info("synchronization timed out", "syncTimeout", rs.Spec.SyncTimeout.Duration)
+	recordSyncTimeout(&rs.Status.LastSyncResult, &rs.Status.Conditions, rs.Spec.SyncTimeout.Duration)
+	sr.EventRecorder.Eventf(rs, corev1.EventTypeWarning, utils.EvRSyncTimedOut,
+		"Synchronization did not complete within %s and has been stopped", rs.Spec.SyncTimeout.Duration)
+	return abandonSyncSource(rs, metrics, logger)
+}
+
+// abandonExhaustedSyncSource stops a synchronization that has failed on each of
+// the attempts allowed by its retryPolicy. Replication is paused if the
+// retryPolicy requests it.
+func abandonExhaustedSyncSource(
+	ctx context.Context,
+	rs *volsyncv1alpha1.ReplicationSource,
+	sr *ReplicationSourceReconciler,
+	metrics volsyncMetrics,
+	logger logr.Logger,
+) error {
+	logger.Info("synchronization retries exhausted", "attempts", rs.Status.LastSyncResult.Attempts)
+	recordRetriesExhausted(rs.Status.LastSyncResult, &rs.Status.Conditions)
+	sr.EventRecorder.Eventf(rs, corev1.EventTypeWarning, utils.EvRRetriesExhausted,
+		"Synchronization failed after %d attempts and has been stopped", rs.Status.LastSyncResult.Attempts)
+	if rs.Spec.RetryPolicy.OnExhausted == volsyncv1alpha1.RetryExhaustedPause {
+		// Updating the object replaces the in-memory status
+		status := rs.Status
+		rs.Spec.Paused = true
+		err := sr.Client.Update(ctx, rs)
+		rs.Status = status
+		if err != nil {
+			return err
+		}
+		sr.EventRecorder.Event(rs, corev1.EventTypeWarning, utils.EvRRetriesExhausted,
+			"Replication has been paused")
+	}
+	return abandonSyncSource(rs, metrics, logger)
+}
+
+// abandonSyncSource ends a synchronization that won't be completed. The mover's
+// resources are removed by the cleanup that follows, and the next
+// synchronization begins at the next scheduled time.
+func abandonSyncSource(
+	rs *volsyncv1alpha1.ReplicationSource,
+	metrics volsyncMetrics,
+	logger logr.Logger,
+) error {
+	apimeta.SetStatusCondition(&rs.Status.Conditions, metav1.Condition{
+		Type:    volsyncv1alpha1.ConditionSynchronizing,
+		Status:  metav1.ConditionFalse,
+		Reason:  volsyncv1alpha1.SynchronizingReasonCleanup,
+		Message: "Cleaning up",
+	})
+	rs.Status.LastSyncStartTime = nil
+	// The manual trigger has been handled, even though it was unsuccessful
+	if rs.Spec.Trigger != nil {
+		rs.Status.LastManualSync = rs.Spec.Trigger.Manual
+	}
+	_, err := updateNextSyncSource(rs, metrics, logger)
+	return err
+}
+
 func (r *ReplicationSourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&volsyncv1alpha1.ReplicationSource{}).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: 100,
 		}).
+		Owns(&appsv1.Deployment{}).
 		Owns(&batchv1.Job{}).
 		Owns(&corev1.PersistentVolumeClaim{}).
 		Owns(&corev1.Secret{}).
@@ -247,6 +487,11 @@ func updateNextSyncSource(
 		} else { // Never synced before, so we should ASAP
 			rs.Status.NextSyncTime = &metav1.Time{Time: time.Now()}
 		}
+		// A sync that was abandoned isn't retried until the following
+		// scheduled time
+		if t := abandonedAt(rs.Status.LastSyncResult); t != nil && rs.Status.NextSyncTime.Before(t) {
+			rs.Status.NextSyncTime = &metav1.Time{Time: schedule.Next(t.Time)}
+		}
 	} else { // No schedule, so there's no "next"
 		rs.Status.NextSyncTime = nil
 	}
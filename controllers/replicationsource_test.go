@@ -6,9 +6,11 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -65,6 +67,19 @@ var _ = Describe("Source trigger", func() {
 			Expect(e).To(BeNil())
 			Expect(rs.Status.NextSyncTime).To(Not(BeNil()))
 		})
+		It("if the last sync timed out, wait for the next scheduled time", func() {
+			when := metav1.Time{Time: time.Now().Add(-50000 * time.Hour)}
+			rs.Status.LastSyncTime = &when
+			timedOut := metav1.Time{Time: time.Now().Add(-1 * time.Minute)}
+			rs.Status.LastSyncResult = &volsyncv1alpha1.SyncResult{
+				Result: volsyncv1alpha1.SyncResultTimedOut,
+				Time:   &timedOut,
+			}
+			b, e := awaitNextSyncSource(rs, metrics, logger)
+			Expect(b).To(BeFalse())
+			Expect(e).To(BeNil())
+			Expect(rs.Status.NextSyncTime.Time).To(BeTemporally(">", time.Now()))
+		})
 	})
 
 	Context("When a manual trigger is specified", func() {
@@ -598,6 +613,88 @@ var _ = Describe("ReplicationSource", func() {
 		})
 	})
 
+	Context("when hooks are configured and the snapshot stays pending", func() {
+		var exec *recordingExecutor
+		BeforeEach(func() {
+			rs.Spec.Rsync = &volsyncv1alpha1.ReplicationSourceRsyncSpec{
+				ReplicationSourceVolumeOptions: volsyncv1alpha1.ReplicationSourceVolumeOptions{
+					CopyMethod: volsyncv1alpha1.CopyMethodSnapshot,
+				},
+			}
+			rs.Spec.SyncTimeout = &metav1.Duration{Duration: 2 * time.Second}
+			// A single synchronization, so that it isn't started again once
+			// it has been abandoned
+			rs.Spec.Trigger = &volsyncv1alpha1.ReplicationSourceTriggerSpec{Manual: "once"}
+			selector := metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}
+			rs.Spec.Hooks = &volsyncv1alpha1.SyncHooksSpec{
+				Pre:  []volsyncv1alpha1.HookSpec{{PodSelector: selector, Command: []string{"pre"}}},
+				Post: []volsyncv1alpha1.HookSpec{{PodSelector: selector, Command: []string{"post"}}},
+			}
+			exec = &recordingExecutor{}
+			utils.PodExec = exec
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "db",
+					Namespace: namespace.Name,
+					Labels:    map[string]string{"app": "db"},
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "c", Image: "db"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			pod.Status.Phase = corev1.PodRunning
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+		})
+		AfterEach(func() {
+			utils.PodExec = nil
+		})
+		It("runs the post hooks once the sync times out", func() {
+			Eventually(func() volsyncv1alpha1.SyncResultType {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+				if rs.Status == nil || rs.Status.LastSyncResult == nil {
+					return ""
+				}
+				return rs.Status.LastSyncResult.Result
+			}, maxWait, interval).Should(Equal(volsyncv1alpha1.SyncResultTimedOut))
+			Eventually(exec.run, maxWait, interval).Should(Equal([][]string{{"pre"}, {"post"}}))
+			Consistently(exec.run, 2*time.Second, interval).Should(Equal([][]string{{"pre"}, {"post"}}))
+		})
+	})
+
+	Context("when the deletionPolicy is Delete", func() {
+		BeforeEach(func() {
+			rs.Spec.DeletionPolicy = volsyncv1alpha1.DeletionPolicyDelete
+			rs.Spec.Rsync = &volsyncv1alpha1.ReplicationSourceRsyncSpec{}
+		})
+		It("a finalizer holds the object until the data is removed", func() {
+			Eventually(func() []string {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+				return rs.Finalizers
+			}, maxWait, interval).Should(ContainElement(deletionFinalizer))
+			Expect(k8sClient.Delete(ctx, rs)).To(Succeed())
+			// Rsync doesn't store data outside the cluster, so it's removed
+			// right away
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(rs), rs)
+				return kerrors.IsNotFound(err)
+			}, maxWait, interval).Should(BeTrue())
+		})
+		It("the finalizer is removed if the policy changes to Retain", func() {
+			Eventually(func() []string {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+				return rs.Finalizers
+			}, maxWait, interval).Should(ContainElement(deletionFinalizer))
+			rs.Spec.DeletionPolicy = volsyncv1alpha1.DeletionPolicyRetain
+			Expect(k8sClient.Update(ctx, rs)).To(Succeed())
+			Eventually(func() []string {
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(rs), rs)).To(Succeed())
+				return rs.Finalizers
+			}, maxWait, interval).ShouldNot(ContainElement(deletionFinalizer))
+		})
+	})
+
 	Context("rsync: when no remote address is specified", func() {
 		BeforeEach(func() {
 			rs.Spec.Rsync = &volsyncv1alpha1.ReplicationSourceRsyncSpec{
@@ -801,3 +898,27 @@ var _ = Describe("ReplicationSource", func() {
 	})
 
 })
+
+// recordingExecutor records the commands that the hooks run
+type recordingExecutor struct {
+	mu       sync.Mutex
+	commands [][]string
+}
+
+func (e *recordingExecutor) CanExec(ctx context.Context, pod *corev1.Pod, serviceAccount string) (bool, error) {
+	return true, nil
+}
+
+func (e *recordingExecutor) Exec(ctx context.Context, pod *corev1.Pod, container string,
+	command []string) (string, string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.commands = append(e.commands, command)
+	return "", "", nil
+}
+
+func (e *recordingExecutor) run() [][]string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([][]string{}, e.commands...)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package controllers
+
+import (
+	"sync"
+	"time"
+)
+
+// queuedRequeueInterval is how often a synchronization that is waiting for
+// the SyncLimiter is reconciled to check whether it may start
+const queuedRequeueInterval = 15 * time.Second
+
+// SyncLimiter limits the number of synchronizations that may run at the same
+// time, both across the cluster and within each namespace. Synchronizations
+// over the limit wait in a queue and are admitted in the order they arrived.
+// A nil SyncLimiter admits everything.
+type SyncLimiter struct {
+	// maxRunning is the maximum number of synchronizations in the cluster (0
+	// is unlimited)
+	maxRunning int
+	// maxRunningPerNamespace is the maximum number of synchronizations in a
+	// single namespace (0 is unlimited)
+	maxRunningPerNamespace int
+
+	mutex sync.Mutex
+	// running maps the key of each admitted synchronization to its namespace
+	running map[string]string
+	// queue holds the synchronizations that are waiting to be admitted
+	queue []syncLimiterEntry
+	// paused holds the keys of started synchronizations that gave up their
+	// slot while paused
+	paused map[string]bool
+}
+
+type syncLimiterEntry struct {
+	key       string
+	namespace string
+}
+
+// NewSyncLimiter creates a SyncLimiter with the given global and per-namespace
+// limits. A limit of 0 means unlimited.
+func NewSyncLimiter(maxRunning int, maxRunningPerNamespace int) *SyncLimiter {
+	return &SyncLimiter{
+		maxRunning:             maxRunning,
+		maxRunningPerNamespace: maxRunningPerNamespace,
+		running:                map[string]string{},
+		paused:                 map[string]bool{},
+	}
+}
+
+// Admit returns true if the synchronization identified by key may run. If
+// not, it is queued until a slot is available. A synchronization that has
+// already started (e.g., before the controller was restarted) is always
+// admitted so that it can complete, unless it gave up its slot by being
+// paused.
+func (l *SyncLimiter) Admit(key string, namespace string, started bool) bool {
+	if l == nil {
+		return true
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if _, ok := l.running[key]; ok {
+		return true
+	}
+	if started && !l.paused[key] {
+		l.start(key, namespace)
+		return true
+	}
+	if l.queuePosition(key) < 0 {
+		l.queue = append(l.queue, syncLimiterEntry{key: key, namespace: namespace})
+	}
+
+	// Walk the queue in order, reserving slots for the entries ahead of this
+	// one. An entry that is only blocked by its namespace limit doesn't hold
+	// up entries from other namespaces.
+	globalFree := l.maxRunning - len(l.running)
+	nsRunning := map[string]int{}
+	for _, ns := range l.running {
+		nsRunning[ns]++
+	}
+	for _, entry := range l.queue {
+		if l.maxRunning > 0 && globalFree <= 0 {
+			return false
+		}
+		if l.maxRunningPerNamespace > 0 && nsRunning[entry.namespace] >= l.maxRunningPerNamespace {
+			continue
+		}
+		if entry.key == key {
+			l.start(key, namespace)
+			return true
+		}
+		globalFree--
+		nsRunning[entry.namespace]++
+	}
+	return false
+}
+
+// Release frees the slot (or the place in the queue) of the synchronization
+// identified by key. It is safe to call for synchronizations that aren't
+// running.
+func (l *SyncLimiter) Release(key string) {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.running, key)
+	delete(l.paused, key)
+	if pos := l.queuePosition(key); pos >= 0 {
+		l.queue = append(l.queue[:pos], l.queue[pos+1:]...)
+	}
+}
+
+// Pause frees the slot (or the place in the queue) of a paused
+// synchronization. When it resumes, it waits for a slot like a new
+// synchronization.
+func (l *SyncLimiter) Pause(key string) {
+	if l == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.running, key)
+	if pos := l.queuePosition(key); pos >= 0 {
+		l.queue = append(l.queue[:pos], l.queue[pos+1:]...)
+	}
+	l.paused[key] = true
+}
+
+// start marks the synchronization as running. The mutex must be held.
+func (l *SyncLimiter) start(key string, namespace string) {
+	l.running[key] = namespace
+	delete(l.paused, key)
+	if pos := l.queuePosition(key); pos >= 0 {
+		l.queue = append(l.queue[:pos], l.queue[pos+1:]...)
+	}
+}
+
+// queuePosition returns the index of key in the queue or -1. The mutex must be
+// held.
+func (l *SyncLimiter) queuePosition(key string) int {
+	for i, entry := range l.queue {
+		if entry.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// syncLimiterKey returns the key used to identify an object's synchronization
+// in the SyncLimiter
+func syncLimiterKey(kind string, namespace string, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
@@ -18,11 +18,16 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package controllers
 
 import (
+	"fmt"
+	"sort"
 	"time"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/mover"
 	"github.com/go-logr/logr"
 	"github.com/prometheus/client_golang/prometheus"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
@@ -32,9 +37,18 @@ const (
 
 // volsyncMetrics holds references to fully qualified instances of the metrics
 type volsyncMetrics struct {
-	MissedIntervals prometheus.Counter
-	OutOfSync       prometheus.Gauge
-	SyncDurations   prometheus.Observer
+	MissedIntervals     prometheus.Counter
+	OutOfSync           prometheus.Gauge
+	SyncDurations       prometheus.Observer
+	BytesTransferred    prometheus.Counter
+	FilesTransferred    prometheus.Counter
+	Throughput          prometheus.Gauge
+	LastSyncTimestamp   prometheus.Gauge
+	JobFailures         prometheus.Counter
+	LastCheckTimestamp  prometheus.Gauge
+	CheckFailed         prometheus.Gauge
+	RepositorySize      prometheus.Gauge
+	RepositorySnapshots prometheus.Gauge
 }
 
 var (
@@ -71,19 +85,146 @@ var (
 		},
 		metricLabels,
 	)
+	bytesTransferred = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "bytes_transferred_total",
+			Namespace: metricsNamespace,
+			Help:      "The amount of data transferred by completed synchronizations, in bytes",
+		},
+		metricLabels,
+	)
+	filesTransferred = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "files_transferred_total",
+			Namespace: metricsNamespace,
+			Help:      "The number of files transferred by completed synchronizations",
+		},
+		metricLabels,
+	)
+	throughput = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "sync_throughput_bytes_per_second",
+			Namespace: metricsNamespace,
+			Help:      "The average data transfer rate of the most recent synchronization",
+		},
+		metricLabels,
+	)
+	lastSyncTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "last_sync_timestamp_seconds",
+			Namespace: metricsNamespace,
+			Help:      "The time of the most recent successful synchronization, in seconds since the epoch",
+		},
+		metricLabels,
+	)
+	jobFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:      "job_failures_total",
+			Namespace: metricsNamespace,
+			Help:      "The number of times the data mover failed to complete a synchronization attempt",
+		},
+		metricLabels,
+	)
+	lastCheckTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "repository_last_check_timestamp_seconds",
+			Namespace: metricsNamespace,
+			Help:      "The time of the most recent integrity check of the backup repository, in seconds since the epoch",
+		},
+		metricLabels,
+	)
+	checkFailed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "repository_check_failed",
+			Namespace: metricsNamespace,
+			Help:      "Set to 1 if the most recent integrity check of the backup repository found errors",
+		},
+		metricLabels,
+	)
+	repositorySize = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "repository_size_bytes",
+			Namespace: metricsNamespace,
+			Help:      "The amount of data stored in the backup repository",
+		},
+		metricLabels,
+	)
+	repositorySnapshots = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:      "repository_snapshots",
+			Namespace: metricsNamespace,
+			Help:      "The number of snapshots in the backup repository",
+		},
+		metricLabels,
+	)
 )
 
 func newVolSyncMetrics(labels prometheus.Labels) volsyncMetrics {
 	return volsyncMetrics{
-		MissedIntervals: missedIntervals.With(labels),
-		OutOfSync:       outOfSync.With(labels),
-		SyncDurations:   syncDurations.With(labels),
+		MissedIntervals:     missedIntervals.With(labels),
+		OutOfSync:           outOfSync.With(labels),
+		SyncDurations:       syncDurations.With(labels),
+		BytesTransferred:    bytesTransferred.With(labels),
+		FilesTransferred:    filesTransferred.With(labels),
+		Throughput:          throughput.With(labels),
+		LastSyncTimestamp:   lastSyncTimestamp.With(labels),
+		JobFailures:         jobFailures.With(labels),
+		LastCheckTimestamp:  lastCheckTimestamp.With(labels),
+		CheckFailed:         checkFailed.With(labels),
+		RepositorySize:      repositorySize.With(labels),
+		RepositorySnapshots: repositorySnapshots.With(labels),
 	}
 }
 
+// recordSyncCompletion updates the metrics that describe a successful
+// synchronization. The transfer statistics are optional since not all movers
+// report them.
+func (m volsyncMetrics) recordSyncCompletion(lastSyncTime *metav1.Time, duration *metav1.Duration,
+	stats *mover.TransferStats) {
+	if lastSyncTime != nil {
+		m.LastSyncTimestamp.Set(float64(lastSyncTime.Unix()))
+	}
+	if stats == nil {
+		return
+	}
+	m.BytesTransferred.Add(float64(stats.BytesTransferred))
+	m.FilesTransferred.Add(float64(stats.FilesTransferred))
+	if duration != nil && duration.Seconds() > 0 {
+		m.Throughput.Set(float64(stats.BytesTransferred) / duration.Seconds())
+	}
+}
+
+// recordRepositoryCheck updates the metrics that describe the most recent
+// restic repository integrity check, if one has been performed.
+func (m volsyncMetrics) recordRepositoryCheck(status *volsyncv1alpha1.ReplicationSourceResticStatus) {
+	if status == nil || status.LastChecked == nil {
+		return
+	}
+	m.LastCheckTimestamp.Set(float64(status.LastChecked.Unix()))
+	if status.LastCheckResult == "Failed" {
+		m.CheckFailed.Set(1)
+	} else {
+		m.CheckFailed.Set(0)
+	}
+}
+
+// recordRepositoryStats updates the metrics that describe the size of the
+// backup repository, if it has been reported.
+func (m volsyncMetrics) recordRepositoryStats(stats *volsyncv1alpha1.ResticRepositoryStats) {
+	if stats == nil {
+		return
+	}
+	if stats.TotalSize != nil {
+		m.RepositorySize.Set(float64(stats.TotalSize.Value()))
+	}
+	m.RepositorySnapshots.Set(float64(stats.SnapshotCount))
+}
+
 func init() {
 	// Register custom metrics with the global prometheus registry
-	metrics.Registry.MustRegister(missedIntervals, outOfSync, syncDurations)
+	metrics.Registry.MustRegister(missedIntervals, outOfSync, syncDurations,
+		bytesTransferred, filesTransferred, throughput, lastSyncTimestamp, jobFailures,
+		lastCheckTimestamp, checkFailed, repositorySize, repositorySnapshots)
 }
 
 //nolint:funlen
@@ -100,6 +241,9 @@ func (r *ReplicationSourceReconciler) countReplicationMethods(instance *volsyncv
 	if instance.Spec.Restic != nil {
 		numOfReplication++
 	}
+	if instance.Spec.Syncthing != nil {
+		numOfReplication++
+	}
 	if instance.Spec.External != nil {
 		numOfReplication++
 	}
@@ -127,3 +271,193 @@ func (r *ReplicationDestinationReconciler) countReplicationMethods(instance *vol
 	logger.Info("Counting over ", "Number of Replication Methods: ", numOfReplication)
 	return numOfReplication
 }
+
+// nextAttempt returns the attempt number of the current synchronization based
+// on the previously recorded result
+func nextAttempt(last *volsyncv1alpha1.SyncResult) int32 {
+	if last != nil && last.Result == volsyncv1alpha1.SyncResultFailed {
+		return last.Attempts + 1
+	}
+	return 1
+}
+
+// recordSyncFailure updates the lastSyncResult and the Degraded condition to
+// reflect a failed synchronization attempt
+func recordSyncFailure(lastResult **volsyncv1alpha1.SyncResult, conditions *[]metav1.Condition,
+	failure *mover.Failure) {
+	attempt := nextAttempt(*lastResult)
+	*lastResult = &volsyncv1alpha1.SyncResult{
+		Result:   volsyncv1alpha1.SyncResultFailed,
+		Attempts: attempt,
+		ExitCode: failure.ExitCode,
+		Message:  failure.Message,
+		Time:     &metav1.Time{Time: time.Now()},
+	}
+	reason := failure.Reason
+	if reason == "" {
+		reason = volsyncv1alpha1.DegradedReasonSyncFailed
+	}
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    volsyncv1alpha1.ConditionDegraded,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: fmt.Sprintf("Synchronization attempt %d failed: %s", attempt, failure.Message),
+	})
+}
+
+// recordSyncTimeout updates the lastSyncResult and the Degraded condition to
+// reflect a synchronization that was stopped after exceeding its timeout
+func recordSyncTimeout(lastResult **volsyncv1alpha1.SyncResult, conditions *[]metav1.Condition,
+	timeout time.Duration) {
+	message := fmt.Sprintf("synchronization did not complete within %s", timeout)
+	*lastResult = &volsyncv1alpha1.SyncResult{
+		Result:   volsyncv1alpha1.SyncResultTimedOut,
+		Attempts: nextAttempt(*lastResult),
+		Message:  message,
+		Time:     &metav1.Time{Time: time.Now()},
+	}
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    volsyncv1alpha1.ConditionDegraded,
+		Status:  metav1.ConditionTrue,
+		Reason:  volsyncv1alpha1.DegradedReasonSyncTimedOut,
+		Message: "Synchronization timed out: " + message,
+	})
+}
+
+// recordRetriesExhausted updates the lastSyncResult and the Degraded condition
+// to reflect a synchronization that was abandoned after failing on each of the
+// attempts allowed by its retryPolicy
+func recordRetriesExhausted(lastResult *volsyncv1alpha1.SyncResult, conditions *[]metav1.Condition) {
+	lastResult.Result = volsyncv1alpha1.SyncResultRetriesExhausted
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:   volsyncv1alpha1.ConditionDegraded,
+		Status: metav1.ConditionTrue,
+		Reason: volsyncv1alpha1.DegradedReasonRetriesExhausted,
+		Message: fmt.Sprintf("Synchronization failed after %d attempts: %s",
+			lastResult.Attempts, lastResult.Message),
+	})
+}
+
+// retriesExhausted returns true if the failed synchronization has used all of
+// the attempts allowed by the retryPolicy and should no longer be retried
+func retriesExhausted(policy *volsyncv1alpha1.RetryPolicySpec, lastResult *volsyncv1alpha1.SyncResult) bool {
+	if policy == nil || policy.MaxAttempts == nil ||
+		policy.OnExhausted == "" || policy.OnExhausted == volsyncv1alpha1.RetryExhaustedRetry {
+		return false
+	}
+	return lastResult != nil && lastResult.Result == volsyncv1alpha1.SyncResultFailed &&
+		lastResult.Attempts >= *policy.MaxAttempts
+}
+
+// retryBackoffRemaining returns how much longer a failed synchronization must
+// wait before its next attempt according to the retryPolicy's backoffDelay
+func retryBackoffRemaining(policy *volsyncv1alpha1.RetryPolicySpec, lastResult *volsyncv1alpha1.SyncResult,
+	now time.Time) time.Duration {
+	if policy == nil || policy.BackoffDelay == nil || lastResult == nil ||
+		lastResult.Result != volsyncv1alpha1.SyncResultFailed || lastResult.Time == nil {
+		return 0
+	}
+	return lastResult.Time.Add(policy.BackoffDelay.Duration).Sub(now)
+}
+
+// abandonedAt returns the time when the most recent synchronization was
+// abandoned because it timed out or exhausted its retries, or nil if it
+// wasn't. An abandoned synchronization isn't retried until the following
+// scheduled time.
+func abandonedAt(lastResult *volsyncv1alpha1.SyncResult) *metav1.Time {
+	if lastResult == nil || (lastResult.Result != volsyncv1alpha1.SyncResultTimedOut &&
+		lastResult.Result != volsyncv1alpha1.SyncResultRetriesExhausted) {
+		return nil
+	}
+	return lastResult.Time
+}
+
+// syncTimedOut returns true if a synchronization that began at startTime has
+// exceeded its timeout. Paused synchronizations don't time out.
+func syncTimedOut(timeout *metav1.Duration, paused bool, startTime *metav1.Time, now time.Time) bool {
+	if timeout == nil || timeout.Duration <= 0 || paused || startTime == nil {
+		return false
+	}
+	return startTime.Add(timeout.Duration).Before(now)
+}
+
+// recordSyncSuccess updates the lastSyncResult and the Degraded condition to
+// reflect a successful synchronization
+func recordSyncSuccess(lastResult **volsyncv1alpha1.SyncResult, conditions *[]metav1.Condition) {
+	*lastResult = &volsyncv1alpha1.SyncResult{
+		Result:   volsyncv1alpha1.SyncResultSucceeded,
+		Attempts: nextAttempt(*lastResult),
+		Time:     &metav1.Time{Time: time.Now()},
+	}
+	apimeta.SetStatusCondition(conditions, metav1.Condition{
+		Type:    volsyncv1alpha1.ConditionDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  volsyncv1alpha1.DegradedReasonSyncSucceeded,
+		Message: "Synchronization completed successfully",
+	})
+}
+
+// selectRetainedImages partitions the images (ordered newest first) into those
+// that should be kept according to the retention policy and those that should
+// be discarded. The newest image is always kept, and a nil policy keeps only
+// the newest image. Duplicate entries for the same image are dropped.
+func selectRetainedImages(policy *volsyncv1alpha1.ImageRetainPolicy,
+	images []volsyncv1alpha1.RetainedImage) (keep, discard []volsyncv1alpha1.RetainedImage) {
+	var unique []volsyncv1alpha1.RetainedImage
+	seen := map[string]bool{}
+	for _, img := range images {
+		if !seen[img.Image.Name] {
+			seen[img.Image.Name] = true
+			unique = append(unique, img)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, nil
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		return unique[i].CreationTime.After(unique[j].CreationTime.Time)
+	})
+
+	retained := make([]bool, len(unique))
+	retained[0] = true
+	if policy != nil {
+		if policy.Last != nil {
+			for i := 0; i < int(*policy.Last) && i < len(unique); i++ {
+				retained[i] = true
+			}
+		}
+		// For each bucketed rule, keep the newest image from each of the N
+		// most recent periods that contain an image
+		keepBuckets := func(count *int32, bucket func(time.Time) string) {
+			if count == nil {
+				return
+			}
+			remaining := *count
+			lastBucket := ""
+			for i := 0; i < len(unique) && remaining > 0; i++ {
+				b := bucket(unique[i].CreationTime.UTC())
+				if b != lastBucket {
+					retained[i] = true
+					lastBucket = b
+					remaining--
+				}
+			}
+		}
+		keepBuckets(policy.Hourly, func(t time.Time) string { return t.Format("2006-01-02T15") })
+		keepBuckets(policy.Daily, func(t time.Time) string { return t.Format("2006-01-02") })
+		keepBuckets(policy.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		})
+		keepBuckets(policy.Monthly, func(t time.Time) string { return t.Format("2006-01") })
+	}
+
+	for i, img := range unique {
+		if retained[i] {
+			keep = append(keep, img)
+		} else {
+			discard = append(discard, img)
+		}
+	}
+	return keep, discard
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrVolumeInUse is returned when a ReadWriteOncePod volume is already mounted
+// by another Pod, preventing the data mover from accessing it.
+var ErrVolumeInUse = errors.New("volume is in use by another pod")
+
+// nodeNameField is the Node field used to pin a Pod to a specific Node
+const nodeNameField = "metadata.name"
+
+// NodeForVolume returns the name of the Node where the provided PVC is
+// currently mounted by a Pod that has been scheduled and hasn't terminated. An
+// empty string is returned if the PVC is not in use or if it may be attached to
+// multiple Nodes simultaneously.
+// For ReadWriteOncePod volumes that are in use, ErrVolumeInUse is returned
+// since no other Pod will be able to mount the volume.
+func NodeForVolume(ctx context.Context, c client.Client, logger logr.Logger,
+	pvc *corev1.PersistentVolumeClaim) (string, error) {
+	if !isSingleNodeVolume(pvc) {
+		return "", nil
+	}
+
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(pvc.Namespace)); err != nil {
+		logger.Error(err, "unable to list Pods", "namespace", pvc.Namespace)
+		return "", err
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if pod.Spec.NodeName == "" || podTerminated(pod) {
+			continue
+		}
+		if !podUsesPVC(pod, pvc.Name) {
+			continue
+		}
+		if hasAccessMode(pvc, corev1.ReadWriteOncePod) {
+			return "", fmt.Errorf("%w: PVC %s is ReadWriteOncePod and is mounted by Pod %s",
+				ErrVolumeInUse, pvc.Name, pod.Name)
+		}
+		logger.V(1).Info("volume is in use", "PVC", pvc.Name, "pod", pod.Name, "node", pod.Spec.NodeName)
+		return pod.Spec.NodeName, nil
+	}
+	return "", nil
+}
+
+// AddNodeAffinity constrains the Pod to run only on the named Node. The
+// requirement is merged with any existing required node affinity terms. An
+// empty nodeName leaves the PodSpec unchanged.
+func AddNodeAffinity(podSpec *corev1.PodSpec, nodeName string) {
+	if nodeName == "" {
+		return
+	}
+	requirement := corev1.NodeSelectorRequirement{
+		Key:      nodeNameField,
+		Operator: corev1.NodeSelectorOpIn,
+		Values:   []string{nodeName},
+	}
+
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.NodeAffinity == nil {
+		podSpec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := podSpec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	selector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(selector.NodeSelectorTerms) == 0 {
+		selector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	// Terms are ORed, so the requirement must be added to each of them
+	for i := range selector.NodeSelectorTerms {
+		selector.NodeSelectorTerms[i].MatchFields = append(selector.NodeSelectorTerms[i].MatchFields, requirement)
+	}
+}
+
+// pinnedNode returns the name of the Node that a PodSpec has been constrained
+// to via AddNodeAffinity, or an empty string if there is none.
+func pinnedNode(podSpec *corev1.PodSpec) string {
+	if podSpec.Affinity == nil || podSpec.Affinity.NodeAffinity == nil ||
+		podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return ""
+	}
+	for _, term := range podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		for _, field := range term.MatchFields {
+			if field.Key == nodeNameField && field.Operator == corev1.NodeSelectorOpIn && len(field.Values) == 1 {
+				return field.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+func isSingleNodeVolume(pvc *corev1.PersistentVolumeClaim) bool {
+	if hasAccessMode(pvc, corev1.ReadWriteMany) || hasAccessMode(pvc, corev1.ReadOnlyMany) {
+		return false
+	}
+	return hasAccessMode(pvc, corev1.ReadWriteOnce) || hasAccessMode(pvc, corev1.ReadWriteOncePod)
+}
+
+func hasAccessMode(pvc *corev1.PersistentVolumeClaim, mode corev1.PersistentVolumeAccessMode) bool {
+	modes := pvc.Status.AccessModes
+	if len(modes) == 0 {
+		modes = pvc.Spec.AccessModes
+	}
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// podTerminated returns true once all of the Pod's containers have stopped, so
+// it no longer holds its volumes
+func podTerminated(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+func podUsesPVC(pod *corev1.Pod, pvcName string) bool {
+	for _, v := range pod.Spec.Volumes {
+		if v.PersistentVolumeClaim != nil && v.PersistentVolumeClaim.ClaimName == pvcName {
+			return true
+		}
+	}
+	return false
+}
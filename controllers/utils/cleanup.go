@@ -75,38 +75,68 @@ func MarkOldSnapshotForCleanup(ctx context.Context, c client.Client, logger logr
 	if !isSnapshot(latestImage) {
 		return nil
 	}
-	// No oldImage or type != snapshot
-	if !isSnapshot(oldImage) {
+
+	// Also don't clean it up if it's the snap we're trying to preserve
+	if oldImage != nil && latestImage.Name == oldImage.Name {
 		return nil
 	}
 
-	// Also don't clean it up if it's the snap we're trying to preserve
-	if latestImage.Name == oldImage.Name {
+	return MarkSnapshotForCleanup(ctx, c, logger, owner, oldImage)
+}
+
+// MarkSnapshotForCleanup labels an existing VolumeSnapshot so that it will be
+// deleted by CleanupObjects(). Images that are not VolumeSnapshots are ignored.
+func MarkSnapshotForCleanup(ctx context.Context, c client.Client, logger logr.Logger,
+	owner metav1.Object, image *corev1.TypedLocalObjectReference) error {
+	// No image or type != snapshot
+	if !isSnapshot(image) {
 		return nil
 	}
 
-	oldSnap := &snapv1.VolumeSnapshot{
+	snap := &snapv1.VolumeSnapshot{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      oldImage.Name,
+			Name:      image.Name,
 			Namespace: owner.GetNamespace(),
 		},
 	}
-	err := c.Get(ctx, client.ObjectKeyFromObject(oldSnap), oldSnap)
+	err := c.Get(ctx, client.ObjectKeyFromObject(snap), snap)
 	if kerrors.IsNotFound(err) {
 		// Nothing to cleanup
 		return nil
 	}
 	if err != nil {
-		logger.Error(err, "unable to get snapshot", "name", oldSnap.GetName(), "namespace", oldSnap.GetNamespace())
+		logger.Error(err, "unable to get snapshot", "name", snap.GetName(), "namespace", snap.GetNamespace())
 		return err
 	}
 
-	// Update the old snapshot with the cleanup label
-	MarkForCleanup(owner, oldSnap)
-	err = c.Update(ctx, oldSnap)
+	// Update the snapshot with the cleanup label
+	MarkForCleanup(owner, snap)
+	err = c.Update(ctx, snap)
 	if err != nil && !kerrors.IsNotFound(err) {
 		logger.Error(err, "unable to update snapshot with cleanup label",
-			"name", oldSnap.GetName(), "namespace", oldSnap.GetNamespace())
+			"name", snap.GetName(), "namespace", snap.GetNamespace())
+		return err
+	}
+	return nil
+}
+
+// DeleteSnapshot deletes an image if it is a VolumeSnapshot. Other images are
+// ignored.
+func DeleteSnapshot(ctx context.Context, c client.Client, logger logr.Logger,
+	owner metav1.Object, image *corev1.TypedLocalObjectReference) error {
+	if !isSnapshot(image) {
+		return nil
+	}
+
+	snap := &snapv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      image.Name,
+			Namespace: owner.GetNamespace(),
+		},
+	}
+	err := c.Delete(ctx, snap, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	if client.IgnoreNotFound(err) != nil {
+		logger.Error(err, "unable to delete snapshot", "name", snap.GetName(), "namespace", snap.GetNamespace())
 		return err
 	}
 	return nil
@@ -116,7 +146,7 @@ func isSnapshot(image *corev1.TypedLocalObjectReference) bool {
 	if image == nil {
 		return false
 	}
-	if image.Kind != "VolumeSnapshot" || *image.APIGroup != snapv1.SchemeGroupVersion.Group {
+	if image.Kind != "VolumeSnapshot" || image.APIGroup == nil || *image.APIGroup != snapv1.SchemeGroupVersion.Group {
 		return false
 	}
 	return true
@@ -0,0 +1,72 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"context"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ConversionCRDs are the CustomResourceDefinitions that serve more than one
+// API version and are converted by the operator's webhook
+var ConversionCRDs = []string{
+	"replicationdestinations.volsync.backube",
+	"replicationsources.volsync.backube",
+}
+
+// The path that the operator's conversion webhook is served on
+const conversionWebhookPath = "/convert"
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,resourceNames=replicationdestinations.volsync.backube;replicationsources.volsync.backube,verbs=get;update
+
+// EnsureCRDConversion configures the CRDs to convert between API versions by
+// calling the conversion webhook behind the named Service, trusting caBundle.
+// This is used when the CRDs are installed in a way that can't inject the
+// webhook's configuration (e.g., from the static CRDs of the Helm chart).
+func EnsureCRDConversion(ctx context.Context, c client.Client, crdNames []string,
+	namespace string, service string, caBundle []byte) error {
+	path := conversionWebhookPath
+	port := int32(443)
+	for _, name := range crdNames {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+			return err
+		}
+		crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+			Strategy: apiextensionsv1.WebhookConverter,
+			Webhook: &apiextensionsv1.WebhookConversion{
+				ClientConfig: &apiextensionsv1.WebhookClientConfig{
+					Service: &apiextensionsv1.ServiceReference{
+						Namespace: namespace,
+						Name:      service,
+						Path:      &path,
+						Port:      &port,
+					},
+					CABundle: caBundle,
+				},
+				ConversionReviewVersions: []string{"v1"},
+			},
+		}
+		if err := c.Update(ctx, crd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
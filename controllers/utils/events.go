@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+// Reasons for the Events that are recorded against ReplicationSource and
+// ReplicationDestination objects
+const (
+	// EvRSyncStarted indicates a new synchronization iteration has begun
+	EvRSyncStarted = "SyncStarted"
+	// EvRSyncCompleted indicates the data mover has finished synchronizing
+	EvRSyncCompleted = "SyncCompleted"
+	// EvRSyncTimedOut indicates the synchronization was stopped because it
+	// exceeded its syncTimeout
+	EvRSyncTimedOut = "SyncTimedOut"
+	// EvRRetriesExhausted indicates the synchronization was stopped after
+	// failing on each of the attempts allowed by its retryPolicy
+	EvRRetriesExhausted = "RetriesExhausted"
+	// EvRCleanupCompleted indicates temporary resources from the most recent
+	// synchronization have been removed
+	EvRCleanupCompleted = "CleanupCompleted"
+	// EvRSnapshotCreated indicates a VolumeSnapshot has been created and
+	// VolSync is waiting for it to become bound
+	EvRSnapshotCreated = "SnapshotCreated"
+	// EvRJobFailed indicates the mover Job has reached its backoff limit
+	EvRJobFailed = "JobFailed"
+	// EvRSecretInvalid indicates a Secret referenced by the CR is missing or
+	// doesn't contain the required fields
+	EvRSecretInvalid = "SecretInvalid"
+	// EvRHookFailed indicates a pre or post sync hook could not be run
+	// successfully
+	EvRHookFailed = "HookFailed"
+	// EvRDataDeleted indicates the replicated data has been removed because
+	// the object was deleted with a deletionPolicy of Delete
+	EvRDataDeleted = "DataDeleted"
+	// EvRDataRetained indicates the replicated data could not be removed
+	// safely, so it has been left in place
+	EvRDataRetained = "DataRetained"
+	// EvRRepositoryCheckFailed indicates a periodic integrity check found
+	// errors in the backup repository
+	EvRRepositoryCheckFailed = "RepositoryCheckFailed"
+)
+
+// Reasons for the Events that are recorded against PVCs that are populated from
+// a ReplicationDestination
+const (
+	// EvRPopulatorPending indicates the ReplicationDestination doesn't yet
+	// have an image to populate the PVC from
+	EvRPopulatorPending = "PopulatorPending"
+	// EvRVolumePopulated indicates the PVC's volume has been provisioned from
+	// the ReplicationDestination's image
+	EvRVolumePopulated = "VolumePopulated"
+)
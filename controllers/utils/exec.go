@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PodExecutor runs commands inside the containers of running Pods
+type PodExecutor interface {
+	// Exec runs the command in the named container of the Pod and returns
+	// its output.
+	Exec(ctx context.Context, pod *corev1.Pod, container string, command []string) (stdout, stderr string, err error)
+	// CanExec returns true if the ServiceAccount, in the Pod's namespace, is
+	// allowed to run commands in the Pod.
+	CanExec(ctx context.Context, pod *corev1.Pod, serviceAccount string) (bool, error)
+}
+
+// PodExec is the PodExecutor used to run hooks. It is set during startup.
+var PodExec PodExecutor
+
+// ErrExecUnavailable is returned when a command must be run in a Pod, but no
+// PodExecutor has been configured
+var ErrExecUnavailable = errors.New("running commands in Pods is not available")
+
+type podExecutor struct {
+	config    *rest.Config
+	clientset kubernetes.Interface
+}
+
+// NewPodExecutor returns a PodExecutor that uses the Pods' exec subresource
+func NewPodExecutor(config *rest.Config) (PodExecutor, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &podExecutor{config: config, clientset: clientset}, nil
+}
+
+func (e *podExecutor) Exec(ctx context.Context, pod *corev1.Pod, container string,
+	command []string) (string, string, error) {
+	req := e.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	transport, upgrader, err := spdy.RoundTripperFor(e.config)
+	if err != nil {
+		return "", "", err
+	}
+	conn := &cancelableUpgrader{Upgrader: upgrader}
+	exec, err := remotecommand.NewSPDYExecutorForTransports(&contextRoundTripper{ctx: ctx, rt: transport},
+		conn, "POST", req.URL())
+	if err != nil {
+		return "", "", err
+	}
+
+	// Stream doesn't take a context, so the connection is closed once the
+	// context is done, which makes Stream return. The command may continue to
+	// run in the container.
+	var stdout, stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- exec.Stream(remotecommand.StreamOptions{
+			Stdout: &stdout,
+			Stderr: &stderr,
+		})
+	}()
+	select {
+	case err = <-done:
+		return stdout.String(), stderr.String(), err
+	case <-ctx.Done():
+		conn.close()
+		return "", "", ctx.Err()
+	}
+}
+
+// errStreamCanceled is returned when the exec connection is established after
+// the command has already been canceled
+var errStreamCanceled = errors.New("exec stream canceled")
+
+// cancelableUpgrader keeps the connection that it upgrades so that it can be
+// closed from outside of Stream
+type cancelableUpgrader struct {
+	spdy.Upgrader
+	mu     sync.Mutex
+	conn   httpstream.Connection
+	closed bool
+}
+
+func (u *cancelableUpgrader) NewConnection(resp *http.Response) (httpstream.Connection, error) {
+	conn, err := u.Upgrader.NewConnection(resp)
+	if err != nil {
+		return nil, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.closed {
+		conn.Close()
+		return nil, errStreamCanceled
+	}
+	u.conn = conn
+	return conn, nil
+}
+
+func (u *cancelableUpgrader) close() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.closed = true
+	if u.conn != nil {
+		u.conn.Close()
+	}
+}
+
+// contextRoundTripper sends the upgrade request with the context so that
+// dialing the API server is abandoned once it's done
+type contextRoundTripper struct {
+	ctx context.Context
+	rt  http.RoundTripper
+}
+
+func (t *contextRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.rt.RoundTrip(req.WithContext(t.ctx))
+}
+
+func (e *podExecutor) CanExec(ctx context.Context, pod *corev1.Pod, serviceAccount string) (bool, error) {
+	review := &authv1.SubjectAccessReview{
+		Spec: authv1.SubjectAccessReviewSpec{
+			User: "system:serviceaccount:" + pod.Namespace + ":" + serviceAccount,
+			Groups: []string{
+				"system:serviceaccounts",
+				"system:serviceaccounts:" + pod.Namespace,
+				"system:authenticated",
+			},
+			ResourceAttributes: &authv1.ResourceAttributes{
+				Namespace:   pod.Namespace,
+				Verb:        "create",
+				Resource:    "pods",
+				Subresource: "exec",
+				Name:        pod.Name,
+			},
+		},
+	}
+	review, err := e.clientset.AuthorizationV1().SubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
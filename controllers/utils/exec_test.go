@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	spdystream "k8s.io/apimachinery/pkg/util/httpstream/spdy"
+	remotecommandconsts "k8s.io/apimachinery/pkg/util/remotecommand"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Pod executor", func() {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "ns"},
+	}
+
+	Context("CanExec", func() {
+		var review *authv1.SubjectAccessReview
+		var allowed bool
+		var executor *podExecutor
+		BeforeEach(func() {
+			review = nil
+			clientset := fake.NewSimpleClientset()
+			clientset.PrependReactor("create", "subjectaccessreviews",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					review = action.(k8stesting.CreateAction).GetObject().(*authv1.SubjectAccessReview)
+					result := review.DeepCopy()
+					result.Status.Allowed = allowed
+					return true, result, nil
+				})
+			executor = &podExecutor{clientset: clientset}
+		})
+		It("asks whether the ServiceAccount may exec into the Pod", func() {
+			allowed = true
+			ok, err := executor.CanExec(context.TODO(), pod, "hooks")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(review.Spec.User).To(Equal("system:serviceaccount:ns:hooks"))
+			Expect(review.Spec.Groups).To(ConsistOf("system:serviceaccounts",
+				"system:serviceaccounts:ns", "system:authenticated"))
+			Expect(*review.Spec.ResourceAttributes).To(Equal(authv1.ResourceAttributes{
+				Namespace:   "ns",
+				Verb:        "create",
+				Resource:    "pods",
+				Subresource: "exec",
+				Name:        "db",
+			}))
+		})
+		It("reports when it may not", func() {
+			allowed = false
+			ok, err := executor.CanExec(context.TODO(), pod, "hooks")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("Exec", func() {
+		var server *httptest.Server
+		var conns chan httpstream.Connection
+		var executor *podExecutor
+		BeforeEach(func() {
+			conns = make(chan httpstream.Connection, 1)
+			// Accepts the exec streams, but the command never completes
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if _, err := httpstream.Handshake(req, w, []string{remotecommandconsts.StreamProtocolV4Name}); err != nil {
+					return
+				}
+				conn := spdystream.NewResponseUpgrader().UpgradeResponse(w, req,
+					func(stream httpstream.Stream, replySent <-chan struct{}) error { return nil })
+				if conn == nil {
+					return
+				}
+				conns <- conn
+				<-conn.CloseChan()
+			}))
+			config := &rest.Config{Host: server.URL}
+			executor = &podExecutor{config: config, clientset: kubernetes.NewForConfigOrDie(config)}
+		})
+		AfterEach(func() {
+			server.Close()
+		})
+		It("closes the connection when the command times out", func() {
+			ctx, cancel := context.WithTimeout(context.TODO(), time.Second)
+			defer cancel()
+			_, _, err := executor.Exec(ctx, pod, "c", []string{"sleep", "infinity"})
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+
+			var conn httpstream.Connection
+			Expect(conns).To(Receive(&conn))
+			Eventually(conn.CloseChan(), 10*time.Second).Should(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+)
+
+// ApplyMoverPodTemplate merges the user-provided settings from the CR's
+// moverPodTemplate into the PodSpec of a data mover. It should be called after
+// the mover has populated the PodSpec. The resource requirements are applied
+// to each of the containers in the Pod. A Node constraint previously added via
+// AddNodeAffinity is preserved.
+func ApplyMoverPodTemplate(podSpec *corev1.PodSpec, tmpl *volsyncv1alpha1.MoverPodTemplateSpec) {
+	if tmpl == nil {
+		tmpl = &volsyncv1alpha1.MoverPodTemplateSpec{}
+	}
+	tmpl = tmpl.DeepCopy()
+	nodeName := pinnedNode(podSpec)
+
+	for i := range podSpec.Containers {
+		podSpec.Containers[i].Resources = *tmpl.Resources.DeepCopy()
+	}
+	podSpec.NodeSelector = tmpl.NodeSelector
+	podSpec.Tolerations = tmpl.Tolerations
+	podSpec.Affinity = tmpl.Affinity
+	podSpec.PriorityClassName = tmpl.PriorityClassName
+	AddNodeAffinity(podSpec, nodeName)
+}
+
+const (
+	customCAVolumeName = "volsync-custom-ca"
+	customCAMountPath  = "/etc/volsync/ca"
+	customCAFile       = "ca.crt"
+	defaultCustomCAKey = "ca.crt"
+)
+
+// DefaultCustomCA is the CA bundle used by the movers when the CR's
+// moverPodTemplate doesn't specify one. It is set from the command line, and
+// the ConfigMap or Secret it names is taken from the CR's namespace.
+var DefaultCustomCA volsyncv1alpha1.CustomCASpec
+
+// DefaultProxy is the proxy configuration used by the movers when the CR's
+// moverPodTemplate doesn't specify one. It is set from the command line.
+var DefaultProxy volsyncv1alpha1.ProxySpec
+
+// ApplyMoverNetwork configures the containers of a data mover to trust the
+// custom CA bundle and to use the HTTP proxy from the CR's moverPodTemplate,
+// falling back to the controller's defaults. The bundle is mounted in each
+// container, and its path is provided in the CUSTOM_CA environment variable.
+// Since the default bundle's ConfigMap or Secret may not exist in every
+// namespace, it is optional. It should be called after the mover has populated
+// the PodSpec.
+func ApplyMoverNetwork(podSpec *corev1.PodSpec, tmpl *volsyncv1alpha1.MoverPodTemplateSpec) {
+	ca, optional := &DefaultCustomCA, true
+	proxy := &DefaultProxy
+	if tmpl != nil && tmpl.CustomCA != nil {
+		ca, optional = tmpl.CustomCA, false
+	}
+	if tmpl != nil && tmpl.Proxy != nil {
+		proxy = tmpl.Proxy
+	}
+
+	env := []corev1.EnvVar{}
+	// Both forms of the variables are set since tools differ in which they use
+	for _, v := range []struct {
+		names []string
+		value string
+	}{
+		{[]string{"HTTP_PROXY", "http_proxy"}, proxy.HTTPProxy},
+		{[]string{"HTTPS_PROXY", "https_proxy"}, proxy.HTTPSProxy},
+		{[]string{"NO_PROXY", "no_proxy"}, proxy.NoProxy},
+	} {
+		if v.value == "" {
+			continue
+		}
+		for _, name := range v.names {
+			env = append(env, corev1.EnvVar{Name: name, Value: v.value})
+		}
+	}
+
+	volume := customCAVolume(ca, optional)
+	if volume != nil {
+		podSpec.Volumes = append(podSpec.Volumes, *volume)
+		env = append(env, corev1.EnvVar{Name: "CUSTOM_CA", Value: path.Join(customCAMountPath, customCAFile)})
+	}
+	for i := range podSpec.Containers {
+		c := &podSpec.Containers[i]
+		c.Env = append(c.Env, env...)
+		if volume != nil {
+			c.VolumeMounts = append(c.VolumeMounts,
+				corev1.VolumeMount{Name: customCAVolumeName, MountPath: customCAMountPath, ReadOnly: true})
+		}
+	}
+}
+
+// customCAVolume returns the volume that provides the CA bundle as
+// customCAFile, or nil if no bundle has been specified
+func customCAVolume(ca *volsyncv1alpha1.CustomCASpec, optional bool) *corev1.Volume {
+	key := ca.Key
+	if key == "" {
+		key = defaultCustomCAKey
+	}
+	items := []corev1.KeyToPath{{Key: key, Path: customCAFile}}
+	volume := &corev1.Volume{Name: customCAVolumeName}
+	switch {
+	case ca.ConfigMapName != "":
+		volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: ca.ConfigMapName},
+			Items:                items,
+			Optional:             &optional,
+		}
+	case ca.SecretName != "":
+		volume.Secret = &corev1.SecretVolumeSource{
+			SecretName: ca.SecretName,
+			Items:      items,
+			Optional:   &optional,
+		}
+	default:
+		return nil
+	}
+	return volume
+}
+
+// ActiveDeadlineSeconds converts the syncTimeout of a ReplicationSource into the
+// activeDeadlineSeconds of a mover Job. There is no deadline while the mover is
+// paused.
+func ActiveDeadlineSeconds(syncTimeout *metav1.Duration, paused bool) *int64 {
+	if syncTimeout == nil || syncTimeout.Duration <= 0 || paused {
+		return nil
+	}
+	seconds := int64(syncTimeout.Duration.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	return &seconds
+}
+
+// JobBackoffLimit returns the backoffLimit of a mover Job. When the retryPolicy
+// sets maxAttempts, the controller retries the synchronization, so the Job is
+// replaced after its first failed Pod.
+func JobBackoffLimit(defaultLimit int32, retryPolicy *volsyncv1alpha1.RetryPolicySpec) *int32 {
+	limit := defaultLimit
+	if retryPolicy != nil && retryPolicy.MaxAttempts != nil {
+		limit = 1
+	}
+	return &limit
+}
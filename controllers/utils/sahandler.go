@@ -36,10 +36,12 @@ const DefaultSCCName = "volsync-mover"
 var SCCName string
 
 type SAHandler struct {
-	Context     context.Context
-	Client      client.Client
-	SA          *corev1.ServiceAccount
-	Owner       metav1.Object
+	Context context.Context
+	Client  client.Client
+	SA      *corev1.ServiceAccount
+	Owner   metav1.Object
+	// Annotations are added to the ServiceAccount
+	Annotations map[string]string
 	role        *rbacv1.Role
 	roleBinding *rbacv1.RoleBinding
 }
@@ -68,6 +70,12 @@ func (d *SAHandler) ensureSA(l logr.Logger) (bool, error) {
 			logger.Error(err, "unable to set controller reference")
 			return err
 		}
+		if len(d.Annotations) > 0 && d.SA.Annotations == nil {
+			d.SA.Annotations = map[string]string{}
+		}
+		for k, v := range d.Annotations {
+			d.SA.Annotations[k] = v
+		}
 		return nil
 	})
 	if err != nil {
@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+)
+
+// GetVolumeGroupPVCs returns the PVCs that are members of the volume group,
+// sorted by name. Groups given by pvcNames must have all of their PVCs present,
+// and a selector must match at least one PVC.
+func GetVolumeGroupPVCs(ctx context.Context, c client.Client, namespace string,
+	group *volsyncv1alpha1.VolumeGroupSpec) ([]*corev1.PersistentVolumeClaim, error) {
+	pvcs := []*corev1.PersistentVolumeClaim{}
+	if group.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(group.Selector)
+		if err != nil {
+			return nil, err
+		}
+		list := &corev1.PersistentVolumeClaimList{}
+		if err := c.List(ctx, list, client.InNamespace(namespace),
+			client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		for i := range list.Items {
+			if list.Items[i].DeletionTimestamp.IsZero() {
+				pvcs = append(pvcs, &list.Items[i])
+			}
+		}
+		if len(pvcs) == 0 {
+			return nil, fmt.Errorf("no PVCs match the volume group selector")
+		}
+	} else {
+		for _, name := range group.PVCNames {
+			pvc := &corev1.PersistentVolumeClaim{}
+			if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pvc); err != nil {
+				return nil, err
+			}
+			pvcs = append(pvcs, pvc)
+		}
+	}
+	sort.Slice(pvcs, func(i, j int) bool { return pvcs[i].Name < pvcs[j].Name })
+	return pvcs, nil
+}
@@ -0,0 +1,326 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/backube/volsync/controllers/mover"
+)
+
+var _ = Describe("Sync result tracking", func() {
+	var status *volsyncv1alpha1.ReplicationSourceStatus
+
+	BeforeEach(func() {
+		status = &volsyncv1alpha1.ReplicationSourceStatus{}
+	})
+
+	It("counts consecutive failures", func() {
+		exitCode := int32(3)
+		failure := &mover.Failure{ExitCode: &exitCode, Message: "connection refused"}
+		recordSyncFailure(&status.LastSyncResult, &status.Conditions, failure)
+		recordSyncFailure(&status.LastSyncResult, &status.Conditions, failure)
+
+		Expect(status.LastSyncResult).NotTo(BeNil())
+		Expect(status.LastSyncResult.Result).To(Equal(volsyncv1alpha1.SyncResultFailed))
+		Expect(status.LastSyncResult.Attempts).To(Equal(int32(2)))
+		Expect(*status.LastSyncResult.ExitCode).To(Equal(int32(3)))
+		Expect(status.LastSyncResult.Message).To(Equal("connection refused"))
+
+		cond := apimeta.FindStatusCondition(status.Conditions, volsyncv1alpha1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(volsyncv1alpha1.DegradedReasonSyncFailed))
+		Expect(cond.Message).To(ContainSubstring("connection refused"))
+	})
+
+	It("uses the reason provided by the mover", func() {
+		failure := &mover.Failure{Message: "repository is already locked",
+			Reason: volsyncv1alpha1.DegradedReasonRepositoryLocked}
+		recordSyncFailure(&status.LastSyncResult, &status.Conditions, failure)
+		cond := apimeta.FindStatusCondition(status.Conditions, volsyncv1alpha1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(volsyncv1alpha1.DegradedReasonRepositoryLocked))
+	})
+
+	It("clears the failure once a sync succeeds", func() {
+		recordSyncFailure(&status.LastSyncResult, &status.Conditions, &mover.Failure{Message: "oops"})
+		recordSyncSuccess(&status.LastSyncResult, &status.Conditions)
+
+		Expect(status.LastSyncResult.Result).To(Equal(volsyncv1alpha1.SyncResultSucceeded))
+		Expect(status.LastSyncResult.Attempts).To(Equal(int32(2)))
+		Expect(status.LastSyncResult.ExitCode).To(BeNil())
+		Expect(apimeta.IsStatusConditionFalse(status.Conditions, volsyncv1alpha1.ConditionDegraded)).To(BeTrue())
+
+		// The next sync starts counting from 1 again
+		recordSyncSuccess(&status.LastSyncResult, &status.Conditions)
+		Expect(status.LastSyncResult.Attempts).To(Equal(int32(1)))
+	})
+})
+
+var _ = Describe("Sync timeouts", func() {
+	It("detects a synchronization that has run for too long", func() {
+		timeout := &metav1.Duration{Duration: time.Hour}
+		start := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+		Expect(syncTimedOut(timeout, false, &start, time.Now())).To(BeTrue())
+		Expect(syncTimedOut(timeout, false, &start, start.Add(30*time.Minute))).To(BeFalse())
+		// No timeout, paused, or not started
+		Expect(syncTimedOut(nil, false, &start, time.Now())).To(BeFalse())
+		Expect(syncTimedOut(timeout, true, &start, time.Now())).To(BeFalse())
+		Expect(syncTimedOut(timeout, false, nil, time.Now())).To(BeFalse())
+	})
+
+	It("records the timeout in the status", func() {
+		status := &volsyncv1alpha1.ReplicationSourceStatus{}
+		recordSyncTimeout(&status.LastSyncResult, &status.Conditions, time.Hour)
+		Expect(status.LastSyncResult.Result).To(Equal(volsyncv1alpha1.SyncResultTimedOut))
+		Expect(status.LastSyncResult.Message).To(ContainSubstring("1h0m0s"))
+		cond := apimeta.FindStatusCondition(status.Conditions, volsyncv1alpha1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		Expect(cond.Reason).To(Equal(volsyncv1alpha1.DegradedReasonSyncTimedOut))
+	})
+})
+
+var _ = Describe("Retry policy", func() {
+	var failed *volsyncv1alpha1.SyncResult
+	maxAttempts := int32(3)
+
+	BeforeEach(func() {
+		failed = &volsyncv1alpha1.SyncResult{
+			Result:   volsyncv1alpha1.SyncResultFailed,
+			Attempts: 3,
+			Message:  "connection refused",
+			Time:     &metav1.Time{Time: time.Now()},
+		}
+	})
+
+	It("gives up after maxAttempts unless the action is Retry", func() {
+		policy := &volsyncv1alpha1.RetryPolicySpec{MaxAttempts: &maxAttempts}
+		Expect(retriesExhausted(nil, failed)).To(BeFalse())
+		Expect(retriesExhausted(policy, failed)).To(BeFalse())
+		policy.OnExhausted = volsyncv1alpha1.RetryExhaustedSkip
+		Expect(retriesExhausted(policy, failed)).To(BeTrue())
+		failed.Attempts = 2
+		Expect(retriesExhausted(policy, failed)).To(BeFalse())
+	})
+
+	It("waits for the backoffDelay after a failure", func() {
+		policy := &volsyncv1alpha1.RetryPolicySpec{BackoffDelay: &metav1.Duration{Duration: time.Minute}}
+		Expect(retryBackoffRemaining(nil, failed, time.Now())).To(BeNumerically("<=", 0))
+		Expect(retryBackoffRemaining(policy, failed, failed.Time.Time)).To(Equal(time.Minute))
+		Expect(retryBackoffRemaining(policy, failed, failed.Time.Add(2*time.Minute))).To(BeNumerically("<=", 0))
+		failed.Result = volsyncv1alpha1.SyncResultSucceeded
+		Expect(retryBackoffRemaining(policy, failed, failed.Time.Time)).To(BeNumerically("<=", 0))
+	})
+
+	It("records that the retries were exhausted", func() {
+		var conditions []metav1.Condition
+		recordRetriesExhausted(failed, &conditions)
+		Expect(failed.Result).To(Equal(volsyncv1alpha1.SyncResultRetriesExhausted))
+		Expect(abandonedAt(failed)).To(Equal(failed.Time))
+		cond := apimeta.FindStatusCondition(conditions, volsyncv1alpha1.ConditionDegraded)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(volsyncv1alpha1.DegradedReasonRetriesExhausted))
+		Expect(cond.Message).To(ContainSubstring("3 attempts"))
+		// A new synchronization starts counting attempts from 1
+		Expect(nextAttempt(failed)).To(Equal(int32(1)))
+	})
+})
+
+var _ = Describe("Transfer metrics", func() {
+	var metrics volsyncMetrics
+
+	BeforeEach(func() {
+		metrics = newVolSyncMetrics(prometheus.Labels{
+			"obj_name": "xfer", "obj_namespace": "ns", "role": "source", "method": "rsync"})
+	})
+
+	It("accumulates the reported statistics", func() {
+		now := metav1.Now()
+		duration := &metav1.Duration{Duration: 10 * time.Second}
+		stats := &mover.TransferStats{BytesTransferred: 1000, FilesTransferred: 4}
+		metrics.recordSyncCompletion(&now, duration, stats)
+		metrics.recordSyncCompletion(&now, duration, stats)
+
+		Expect(testutil.ToFloat64(metrics.BytesTransferred)).To(Equal(float64(2000)))
+		Expect(testutil.ToFloat64(metrics.FilesTransferred)).To(Equal(float64(8)))
+		Expect(testutil.ToFloat64(metrics.Throughput)).To(Equal(float64(100)))
+		Expect(testutil.ToFloat64(metrics.LastSyncTimestamp)).To(Equal(float64(now.Unix())))
+	})
+
+	It("only updates the timestamp when there are no statistics", func() {
+		now := metav1.Now()
+		metrics.recordSyncCompletion(&now, nil, nil)
+		Expect(testutil.ToFloat64(metrics.LastSyncTimestamp)).To(Equal(float64(now.Unix())))
+	})
+
+	It("reports the result of the last repository check", func() {
+		now := metav1.Now()
+		status := &volsyncv1alpha1.ReplicationSourceResticStatus{
+			LastChecked:     &now,
+			LastCheckResult: "Failed",
+		}
+		metrics.recordRepositoryCheck(status)
+		Expect(testutil.ToFloat64(metrics.LastCheckTimestamp)).To(Equal(float64(now.Unix())))
+		Expect(testutil.ToFloat64(metrics.CheckFailed)).To(Equal(float64(1)))
+		status.LastCheckResult = "Succeeded"
+		metrics.recordRepositoryCheck(status)
+		Expect(testutil.ToFloat64(metrics.CheckFailed)).To(Equal(float64(0)))
+	})
+
+	It("reports the size of the repository", func() {
+		size := resource.MustParse("3Gi")
+		metrics.recordRepositoryStats(&volsyncv1alpha1.ResticRepositoryStats{SnapshotCount: 7, TotalSize: &size})
+		Expect(testutil.ToFloat64(metrics.RepositorySize)).To(Equal(float64(3 * 1024 * 1024 * 1024)))
+		Expect(testutil.ToFloat64(metrics.RepositorySnapshots)).To(Equal(float64(7)))
+		metrics.recordRepositoryStats(nil)
+		Expect(testutil.ToFloat64(metrics.RepositorySnapshots)).To(Equal(float64(7)))
+	})
+})
+
+var _ = Describe("Image retention", func() {
+	var images []volsyncv1alpha1.RetainedImage
+	// Images taken every 6 hours, starting with the newest at noon on a Monday
+	newest := time.Date(2022, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	BeforeEach(func() {
+		images = nil
+		for i := 0; i < 60; i++ {
+			images = append(images, volsyncv1alpha1.RetainedImage{
+				Image: corev1.TypedLocalObjectReference{
+					Kind: "VolumeSnapshot",
+					Name: fmt.Sprintf("snap-%d", i),
+				},
+				CreationTime: metav1.Time{Time: newest.Add(-time.Duration(i) * 6 * time.Hour)},
+			})
+		}
+	})
+
+	names := func(list []volsyncv1alpha1.RetainedImage) []string {
+		var n []string
+		for _, i := range list {
+			n = append(n, i.Image.Name)
+		}
+		return n
+	}
+
+	It("keeps only the newest image if there is no policy", func() {
+		keep, discard := selectRetainedImages(nil, images)
+		Expect(names(keep)).To(Equal([]string{"snap-0"}))
+		Expect(discard).To(HaveLen(59))
+	})
+
+	It("keeps the last N images", func() {
+		last := int32(3)
+		keep, _ := selectRetainedImages(&volsyncv1alpha1.ImageRetainPolicy{Last: &last}, images)
+		Expect(names(keep)).To(Equal([]string{"snap-0", "snap-1", "snap-2"}))
+	})
+
+	It("keeps the newest image in each period", func() {
+		daily := int32(3)
+		weekly := int32(2)
+		keep, _ := selectRetainedImages(&volsyncv1alpha1.ImageRetainPolicy{
+			Daily:  &daily,
+			Weekly: &weekly,
+		}, images)
+		// snap-0..2: Mon 3/14; snap-3..6: Sun 3/13; snap-7..10: Sat 3/12
+		// The previous ISO week ends with Sun 3/13, so snap-3 is also the
+		// weekly image.
+		Expect(names(keep)).To(Equal([]string{"snap-0", "snap-3", "snap-7"}))
+	})
+
+	It("orders images by creation time and drops duplicates", func() {
+		last := int32(2)
+		shuffled := []volsyncv1alpha1.RetainedImage{images[2], images[0], images[1], images[0]}
+		keep, discard := selectRetainedImages(&volsyncv1alpha1.ImageRetainPolicy{Last: &last}, shuffled)
+		Expect(names(keep)).To(Equal([]string{"snap-0", "snap-1"}))
+		Expect(names(discard)).To(Equal([]string{"snap-2"}))
+	})
+})
+
+var _ = Describe("Sync limiter", func() {
+	It("admits everything when there are no limits", func() {
+		limiter := NewSyncLimiter(0, 0)
+		for i := 0; i < 10; i++ {
+			Expect(limiter.Admit(fmt.Sprintf("rs/ns/%d", i), "ns", false)).To(BeTrue())
+		}
+		var nilLimiter *SyncLimiter
+		Expect(nilLimiter.Admit("rs/ns/a", "ns", false)).To(BeTrue())
+		nilLimiter.Release("rs/ns/a")
+	})
+
+	It("queues synchronizations over the global limit in order", func() {
+		limiter := NewSyncLimiter(2, 0)
+		Expect(limiter.Admit("a", "ns1", false)).To(BeTrue())
+		Expect(limiter.Admit("b", "ns2", false)).To(BeTrue())
+		Expect(limiter.Admit("c", "ns3", false)).To(BeFalse())
+		Expect(limiter.Admit("d", "ns4", false)).To(BeFalse())
+		// Admitting again is idempotent
+		Expect(limiter.Admit("a", "ns1", false)).To(BeTrue())
+
+		limiter.Release("a")
+		// "c" was queued first
+		Expect(limiter.Admit("d", "ns4", false)).To(BeFalse())
+		Expect(limiter.Admit("c", "ns3", false)).To(BeTrue())
+		limiter.Release("b")
+		Expect(limiter.Admit("d", "ns4", false)).To(BeTrue())
+	})
+
+	It("limits each namespace without blocking the others", func() {
+		limiter := NewSyncLimiter(3, 1)
+		Expect(limiter.Admit("a", "ns1", false)).To(BeTrue())
+		Expect(limiter.Admit("b", "ns1", false)).To(BeFalse())
+		Expect(limiter.Admit("c", "ns2", false)).To(BeTrue())
+
+		limiter.Release("a")
+		Expect(limiter.Admit("b", "ns1", false)).To(BeTrue())
+	})
+
+	It("always admits synchronizations that have already started", func() {
+		limiter := NewSyncLimiter(1, 0)
+		Expect(limiter.Admit("a", "ns", true)).To(BeTrue())
+		Expect(limiter.Admit("b", "ns", true)).To(BeTrue())
+		Expect(limiter.Admit("c", "ns", false)).To(BeFalse())
+
+		limiter.Release("a")
+		Expect(limiter.Admit("c", "ns", false)).To(BeFalse())
+		limiter.Release("b")
+		Expect(limiter.Admit("c", "ns", false)).To(BeTrue())
+	})
+
+	It("drops queued synchronizations when they are released", func() {
+		limiter := NewSyncLimiter(1, 0)
+		Expect(limiter.Admit("a", "ns", false)).To(BeTrue())
+		Expect(limiter.Admit("b", "ns", false)).To(BeFalse())
+		Expect(limiter.Admit("c", "ns", false)).To(BeFalse())
+		limiter.Release("b")
+		limiter.Release("a")
+		Expect(limiter.Admit("c", "ns", false)).To(BeTrue())
+	})
+
+	It("frees the slot of paused synchronizations until they are admitted again", func() {
+		limiter := NewSyncLimiter(1, 0)
+		Expect(limiter.Admit("a", "ns", false)).To(BeTrue())
+		Expect(limiter.Admit("b", "ns", false)).To(BeFalse())
+
+		limiter.Pause("a")
+		Expect(limiter.Admit("b", "ns", false)).To(BeTrue())
+		// Resuming doesn't bypass the limit even though "a" had started
+		Expect(limiter.Admit("a", "ns", true)).To(BeFalse())
+		limiter.Release("b")
+		Expect(limiter.Admit("a", "ns", true)).To(BeTrue())
+
+		var nilLimiter *SyncLimiter
+		nilLimiter.Pause("a")
+	})
+})
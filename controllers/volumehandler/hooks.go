@@ -0,0 +1,275 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package volumehandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
+)
+
+// Annotation placed on a point-in-time copy while its post hooks are still
+// waiting to be run
+const postHooksAnnotation = "volsync.backube/post-hooks-pending"
+
+// The ServiceAccount whose permissions are used to run the hooks if the
+// ReplicationSource doesn't name one
+const defaultHookServiceAccount = "default"
+
+// preCopyHooks runs the pre hooks if none of the point-in-time copies, objs,
+// have been created yet. It returns true if the hooks were run. If a pre hook
+// fails, the post hooks are run to undo any changes before returning the error.
+func (vh *VolumeHandler) preCopyHooks(ctx context.Context, log logr.Logger, objs []client.Object) (bool, error) {
+	if vh.hooks == nil {
+		return false, nil
+	}
+	for _, obj := range objs {
+		err := vh.client.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if err == nil || !kerrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	if err := vh.runHooks(ctx, log, "pre", vh.hooks.Pre); err != nil {
+		_ = vh.runHooks(ctx, log, "post", vh.hooks.Post)
+		return false, err
+	}
+	return true, nil
+}
+
+// abortCopyHooks runs the post hooks after creating the point-in-time copies,
+// objs, has failed, if the pre hooks were run for them. This is the case if the
+// pre hooks were just run, or if one of the copies that already exists is
+// still waiting for its post hooks. The copies' pending annotations are
+// removed so that the post hooks aren't run again once they have been taken.
+func (vh *VolumeHandler) abortCopyHooks(ctx context.Context, log logr.Logger, objs []client.Object,
+	ranPreHooks bool) {
+	if vh.hooks == nil {
+		return
+	}
+	pending := []client.Object{}
+	for _, obj := range objs {
+		if err := vh.client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			continue
+		}
+		if _, ok := obj.GetAnnotations()[postHooksAnnotation]; ok {
+			pending = append(pending, obj)
+		}
+	}
+	if !ranPreHooks && len(pending) == 0 {
+		return
+	}
+	_ = vh.releasePostHooks(ctx, log, pending)
+}
+
+// ReleaseCopyHooks runs the post hooks for the owner's point-in-time copies
+// that are still waiting for them. It's used when a synchronization ends
+// before the copies have been taken (e.g., it timed out, was paused, or the
+// owner is being deleted) so that the application isn't left quiesced once the
+// copies are removed.
+func (vh *VolumeHandler) ReleaseCopyHooks(ctx context.Context, log logr.Logger) error {
+	if vh.hooks == nil {
+		return nil
+	}
+	snaps := &snapv1.VolumeSnapshotList{}
+	if err := vh.client.List(ctx, snaps, client.InNamespace(vh.owner.GetNamespace())); err != nil {
+		return err
+	}
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := vh.client.List(ctx, pvcs, client.InNamespace(vh.owner.GetNamespace())); err != nil {
+		return err
+	}
+	copies := []client.Object{}
+	for i := range snaps.Items {
+		copies = append(copies, &snaps.Items[i])
+	}
+	for i := range pvcs.Items {
+		copies = append(copies, &pvcs.Items[i])
+	}
+	pending := []client.Object{}
+	for _, obj := range copies {
+		if _, ok := obj.GetAnnotations()[postHooksAnnotation]; ok && metav1.IsControlledBy(obj, vh.owner) {
+			pending = append(pending, obj)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	log.Info("running the post hooks of copies that weren't taken")
+	return vh.releasePostHooks(ctx, log, pending)
+}
+
+// releasePostHooks runs the post hooks without waiting for the copies,
+// pending, to be taken and removes their pending annotations. Failures of the
+// hooks are recorded, but they don't prevent the annotations from being
+// removed since the copies won't be completed.
+func (vh *VolumeHandler) releasePostHooks(ctx context.Context, log logr.Logger, pending []client.Object) error {
+	_ = vh.runHooks(ctx, log, "post", vh.hooks.Post)
+	for _, obj := range pending {
+		annotations := obj.GetAnnotations()
+		delete(annotations, postHooksAnnotation)
+		obj.SetAnnotations(annotations)
+		if err := vh.client.Update(ctx, obj); client.IgnoreNotFound(err) != nil {
+			log.Error(err, "unable to remove post hook annotation")
+			return err
+		}
+	}
+	return nil
+}
+
+// markPostHooksPending records on a newly created point-in-time copy that the
+// post hooks need to be run once the copy has been taken
+func (vh *VolumeHandler) markPostHooksPending(obj metav1.Object) {
+	created := obj.GetCreationTimestamp()
+	if vh.hooks == nil || !created.IsZero() {
+		return
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[postHooksAnnotation] = "true"
+	obj.SetAnnotations(annotations)
+}
+
+// postCopyHooks runs the post hooks for the point-in-time copies, objs, once
+// all of them have been taken. The copies' pending annotations are removed
+// after the hooks succeed so that they are only run once.
+func (vh *VolumeHandler) postCopyHooks(ctx context.Context, log logr.Logger, objs []client.Object, taken bool) error {
+	if !taken {
+		return nil
+	}
+	pending := []client.Object{}
+	for _, obj := range objs {
+		if _, ok := obj.GetAnnotations()[postHooksAnnotation]; ok {
+			pending = append(pending, obj)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if vh.hooks != nil {
+		if err := vh.runHooks(ctx, log, "post", vh.hooks.Post); err != nil {
+			return err
+		}
+	}
+	for _, obj := range pending {
+		annotations := obj.GetAnnotations()
+		delete(annotations, postHooksAnnotation)
+		obj.SetAnnotations(annotations)
+		if err := vh.client.Update(ctx, obj); err != nil {
+			log.Error(err, "unable to remove post hook annotation")
+			return err
+		}
+	}
+	return nil
+}
+
+// runHooks executes the hooks in order. Failures of hooks with the Continue
+// error policy are recorded, but they don't stop the remaining hooks.
+func (vh *VolumeHandler) runHooks(ctx context.Context, log logr.Logger, phase string,
+	hooks []volsyncv1alpha1.HookSpec) error {
+	for i := range hooks {
+		logger := log.WithValues("hook", fmt.Sprintf("%s[%d]", phase, i))
+		err := vh.runHook(ctx, logger, &hooks[i])
+		if err == nil {
+			logger.V(1).Info("hook completed")
+			continue
+		}
+		vh.eventRecorder.Eventf(vh.owner, corev1.EventTypeWarning, utils.EvRHookFailed,
+			"%s hook %d failed: %v", phase, i, err)
+		if hooks[i].OnError == volsyncv1alpha1.HookErrorPolicyContinue {
+			logger.Info("ignoring failed hook", "error", err.Error())
+			continue
+		}
+		logger.Error(err, "hook failed")
+		return fmt.Errorf("%s hook %d failed: %w", phase, i, err)
+	}
+	return nil
+}
+
+// runHook executes the hook's command in each of the running Pods that are
+// selected by the hook
+func (vh *VolumeHandler) runHook(ctx context.Context, log logr.Logger, hook *volsyncv1alpha1.HookSpec) error {
+	if utils.PodExec == nil {
+		return utils.ErrExecUnavailable
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&hook.PodSelector)
+	if err != nil {
+		return err
+	}
+	pods := &corev1.PodList{}
+	if err := vh.client.List(ctx, pods, client.InNamespace(vh.owner.GetNamespace()),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return err
+	}
+
+	timeout := volsyncv1alpha1.DefaultHookTimeoutSeconds
+	if hook.TimeoutSeconds != nil {
+		timeout = *hook.TimeoutSeconds
+	}
+	serviceAccount := vh.hooks.ServiceAccountName
+	if serviceAccount == "" {
+		serviceAccount = defaultHookServiceAccount
+	}
+	ran := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || !pod.DeletionTimestamp.IsZero() || len(pod.Spec.Containers) == 0 {
+			continue
+		}
+		container := hook.Container
+		if container == "" {
+			container = pod.Spec.Containers[0].Name
+		}
+		// The operator may exec into any Pod, so the hooks are limited to
+		// the Pods that the namespace's ServiceAccount may exec into
+		allowed, err := utils.PodExec.CanExec(ctx, pod, serviceAccount)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("ServiceAccount %s is not allowed to exec into Pod %s", serviceAccount, pod.Name)
+		}
+		execCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		stdout, stderr, err := utils.PodExec.Exec(execCtx, pod, container, hook.Command)
+		cancel()
+		log.V(1).Info("hook output", "pod", pod.Name, "stdout", stdout, "stderr", stderr)
+		if err != nil {
+			if msg := strings.TrimSpace(stderr); msg != "" {
+				return fmt.Errorf("command failed in Pod %s: %w: %s", pod.Name, err, msg)
+			}
+			return fmt.Errorf("command failed in Pod %s: %w", pod.Name, err)
+		}
+		ran++
+	}
+	if ran == 0 {
+		return fmt.Errorf("no running Pods match the podSelector")
+	}
+	return nil
+}
@@ -23,7 +23,7 @@ import (
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -42,6 +42,9 @@ func NewVolumeHandler(options ...VHOption) (*VolumeHandler, error) {
 	if vh.client == nil {
 		return nil, errors.New("a Client must be provided")
 	}
+	if vh.eventRecorder == nil {
+		return nil, errors.New("an EventRecorder must be provided")
+	}
 	return vh, nil
 }
 
@@ -52,14 +55,30 @@ func WithClient(c client.Client) VHOption {
 	}
 }
 
+// WithRecorder specifies the EventRecorder used to record Events against the
+// owner
+func WithRecorder(r record.EventRecorder) VHOption {
+	return func(vh *VolumeHandler) {
+		vh.eventRecorder = r
+	}
+}
+
 // WithOwner specifies the Object should be the owner of Objects created by the
 // VolumeHandler
-func WithOwner(o metav1.Object) VHOption {
+func WithOwner(o client.Object) VHOption {
 	return func(vh *VolumeHandler) {
 		vh.owner = o
 	}
 }
 
+// WithHooks specifies the hooks that are run around the creation of
+// point-in-time copies of the source volume
+func WithHooks(h *volsyncv1alpha1.SyncHooksSpec) VHOption {
+	return func(vh *VolumeHandler) {
+		vh.hooks = h
+	}
+}
+
 // From populates the VolumeHandler as a copy of an existing VolumeHandler
 func From(v *VolumeHandler) VHOption {
 	return func(vh *VolumeHandler) {
@@ -87,6 +106,7 @@ func FromDestination(d *volsyncv1alpha1.ReplicationDestinationVolumeOptions) VHO
 		vh.capacity = d.Capacity
 		vh.storageClassName = d.StorageClassName
 		vh.accessModes = d.AccessModes
+		vh.volumeMode = d.VolumeMode
 		vh.volumeSnapshotClassName = d.VolumeSnapshotClassName
 	}
 }
@@ -97,6 +117,12 @@ func AccessModes(am []corev1.PersistentVolumeAccessMode) VHOption {
 	}
 }
 
+func VolumeMode(vm *corev1.PersistentVolumeMode) VHOption {
+	return func(vh *VolumeHandler) {
+		vh.volumeMode = vm
+	}
+}
+
 func CopyMethod(cm volsyncv1alpha1.CopyMethodType) VHOption {
 	return func(vh *VolumeHandler) {
 		vh.copyMethod = cm
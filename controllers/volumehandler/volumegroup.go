@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package volumehandler
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
+)
+
+// EnsureVolumeGroupFromSrc ensures the presence of PVCs that are based on each
+// of the members of the source volume group. The copy of member X is named
+// prefix-X. It returns the names of the members along with their copies. Note:
+// it's possible to return nil, nil, nil. In this case, the operation should be
+// retried.
+func (vh *VolumeHandler) EnsureVolumeGroupFromSrc(ctx context.Context, log logr.Logger,
+	group *volsyncv1alpha1.VolumeGroupSpec, prefix string,
+	isTemporary bool) ([]string, []*corev1.PersistentVolumeClaim, error) {
+	srcs, err := utils.GetVolumeGroupPVCs(ctx, vh.client, vh.owner.GetNamespace(), group)
+	if err != nil {
+		log.Error(err, "unable to find the PVCs of the volume group")
+		return nil, nil, err
+	}
+	members := []string{}
+	names := []string{}
+	for _, src := range srcs {
+		members = append(members, src.Name)
+		names = append(names, prefix+"-"+src.Name)
+	}
+	pvcs, err := vh.EnsurePVCsFromSrc(ctx, log, srcs, names, isTemporary)
+	if pvcs == nil || err != nil {
+		return nil, nil, err
+	}
+	return members, pvcs, nil
+}
+
+// EnsureVolumeGroupPVCs ensures the presence of the PVCs of a destination volume
+// group. Existing PVCs that weren't created by VolSync are used as-is. Missing
+// ones are allocated based on the VolumeHandler's configuration.
+func (vh *VolumeHandler) EnsureVolumeGroupPVCs(ctx context.Context, log logr.Logger,
+	group *volsyncv1alpha1.VolumeGroupSpec) ([]*corev1.PersistentVolumeClaim, error) {
+	pvcs := []*corev1.PersistentVolumeClaim{}
+	for _, name := range group.PVCNames {
+		pvc, err := vh.getPVCByName(ctx, name)
+		if err != nil && !kerrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err != nil || metav1.IsControlledBy(pvc, vh.owner) {
+			if pvc, err = vh.EnsureNewPVC(ctx, log, name); err != nil {
+				return nil, err
+			}
+		}
+		pvcs = append(pvcs, pvc)
+	}
+	return pvcs, nil
+}
@@ -29,6 +29,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -46,12 +47,15 @@ const (
 
 type VolumeHandler struct {
 	client                  client.Client
-	owner                   metav1.Object
+	eventRecorder           record.EventRecorder
+	owner                   client.Object
 	copyMethod              volsyncv1alpha1.CopyMethodType
 	capacity                *resource.Quantity
 	storageClassName        *string
 	accessModes             []corev1.PersistentVolumeAccessMode
+	volumeMode              *corev1.PersistentVolumeMode
 	volumeSnapshotClassName *string
+	hooks                   *volsyncv1alpha1.SyncHooksSpec
 }
 
 // EnsurePVCFromSrc ensures the presence of a PVC that is based on the provided
@@ -60,19 +64,41 @@ type VolumeHandler struct {
 // the operation should be retried.
 func (vh *VolumeHandler) EnsurePVCFromSrc(ctx context.Context, log logr.Logger,
 	src *corev1.PersistentVolumeClaim, name string, isTemporary bool) (*corev1.PersistentVolumeClaim, error) {
+	pvcs, err := vh.EnsurePVCsFromSrc(ctx, log, []*corev1.PersistentVolumeClaim{src}, []string{name}, isTemporary)
+	if len(pvcs) == 0 || err != nil {
+		return nil, err
+	}
+	return pvcs[0], nil
+}
+
+// EnsurePVCsFromSrc is the same as EnsurePVCFromSrc, but for a group of PVCs.
+// The point-in-time copies of all the srcs are created together, so the pre
+// and post hooks are run only once for the whole group. The copy of srcs[i] is
+// named names[i]. Note: it's possible to return nil, nil. In this case, the
+// operation should be retried.
+func (vh *VolumeHandler) EnsurePVCsFromSrc(ctx context.Context, log logr.Logger,
+	srcs []*corev1.PersistentVolumeClaim, names []string, isTemporary bool) ([]*corev1.PersistentVolumeClaim, error) {
 	switch vh.copyMethod {
 	case volsyncv1alpha1.CopyMethodNone:
 		fallthrough // Same as CopyMethodDirect
 	case volsyncv1alpha1.CopyMethodDirect:
-		return src, nil
+		return srcs, nil
 	case volsyncv1alpha1.CopyMethodClone:
-		return vh.ensureClone(ctx, log, src, name, isTemporary)
+		return vh.ensureClones(ctx, log, srcs, names, isTemporary)
 	case volsyncv1alpha1.CopyMethodSnapshot:
-		snap, err := vh.ensureSnapshot(ctx, log, src, name, isTemporary)
-		if snap == nil || err != nil {
+		snaps, err := vh.ensureSnapshots(ctx, log, srcs, names, isTemporary)
+		if snaps == nil || err != nil {
 			return nil, err
 		}
-		return vh.pvcFromSnapshot(ctx, log, snap, src, name, isTemporary)
+		pvcs := []*corev1.PersistentVolumeClaim{}
+		for i, snap := range snaps {
+			pvc, err := vh.pvcFromSnapshot(ctx, log, snap, srcs[i], names[i], isTemporary)
+			if pvc == nil || err != nil {
+				return nil, err
+			}
+			pvcs = append(pvcs, pvc)
+		}
+		return pvcs, nil
 	default:
 		return nil, fmt.Errorf("unsupported copyMethod: %v -- must be Direct, None, Clone, or Snapshot", vh.copyMethod)
 	}
@@ -156,6 +182,9 @@ func (vh *VolumeHandler) EnsureNewPVC(ctx context.Context, log logr.Logger,
 			pvc.Spec.AccessModes = vh.accessModes
 			pvc.Spec.StorageClassName = vh.storageClassName
 			volumeMode := corev1.PersistentVolumeFilesystem
+			if vh.volumeMode != nil {
+				volumeMode = *vh.volumeMode
+			}
 			pvc.Spec.VolumeMode = &volumeMode
 		}
 
@@ -226,6 +255,10 @@ func (vh *VolumeHandler) ensureImageSnapshot(ctx context.Context, log logr.Logge
 		return nil, err
 	}
 	logger.V(1).Info("Snapshot reconciled", "operation", op)
+	if op == ctrlutil.OperationResultCreated {
+		vh.eventRecorder.Eventf(vh.owner, corev1.EventTypeNormal, utils.EvRSnapshotCreated,
+			"created VolumeSnapshot %s from PVC %s, waiting for it to be bound", snap.Name, src.Name)
+	}
 
 	// We only continue reconciling if the snapshot has been bound & not deleted
 	if snap.Status == nil || snap.Status.BoundVolumeSnapshotContentName == nil || !snap.DeletionTimestamp.IsZero() {
@@ -252,6 +285,86 @@ func (vh *VolumeHandler) RemoveSnapshotAnnotationFromPVC(ctx context.Context, lo
 	return nil
 }
 
+// ensureClones clones each of the srcs, running the hooks around the creation
+// of the whole set of clones
+func (vh *VolumeHandler) ensureClones(ctx context.Context, log logr.Logger,
+	srcs []*corev1.PersistentVolumeClaim, names []string, isTemporary bool) ([]*corev1.PersistentVolumeClaim, error) {
+	objs := []client.Object{}
+	for _, name := range names {
+		objs = append(objs, &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: vh.owner.GetNamespace()},
+		})
+	}
+	ranPreHooks, err := vh.preCopyHooks(ctx, log, objs)
+	if err != nil {
+		return nil, err
+	}
+
+	clones := []*corev1.PersistentVolumeClaim{}
+	// The clones' contents are fixed once they have been provisioned
+	taken := true
+	for i, src := range srcs {
+		clone, err := vh.ensureClone(ctx, log, src, names[i], isTemporary)
+		if err != nil {
+			vh.abortCopyHooks(ctx, log, objs, ranPreHooks)
+			return nil, err
+		}
+		clones = append(clones, clone)
+		objs[i] = clone
+		taken = taken && clone.Status.Phase == corev1.ClaimBound
+	}
+	if err := vh.postCopyHooks(ctx, log, objs, taken); err != nil {
+		return nil, err
+	}
+	for _, clone := range clones {
+		if !clone.DeletionTimestamp.IsZero() {
+			log.V(1).Info("PVC is being deleted-- need to wait", "clone", clone.Name)
+			return nil, nil
+		}
+	}
+	return clones, nil
+}
+
+// ensureSnapshots snapshots each of the srcs, running the hooks around the
+// creation of the whole set of snapshots. The snapshots are only returned once
+// all of them are ready to be used.
+func (vh *VolumeHandler) ensureSnapshots(ctx context.Context, log logr.Logger,
+	srcs []*corev1.PersistentVolumeClaim, names []string, isTemporary bool) ([]*snapv1.VolumeSnapshot, error) {
+	objs := []client.Object{}
+	for _, name := range names {
+		objs = append(objs, &snapv1.VolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: vh.owner.GetNamespace()},
+		})
+	}
+	ranPreHooks, err := vh.preCopyHooks(ctx, log, objs)
+	if err != nil {
+		return nil, err
+	}
+
+	snaps := []*snapv1.VolumeSnapshot{}
+	// A snapshot has been cut once it has a creationTime (or has failed)
+	taken := true
+	for i, src := range srcs {
+		snap, err := vh.ensureSnapshot(ctx, log, src, names[i], isTemporary)
+		if err != nil {
+			vh.abortCopyHooks(ctx, log, objs, ranPreHooks)
+			return nil, err
+		}
+		snaps = append(snaps, snap)
+		objs[i] = snap
+		taken = taken && snap.Status != nil && (snap.Status.CreationTime != nil || snap.Status.Error != nil)
+	}
+	if err := vh.postCopyHooks(ctx, log, objs, taken); err != nil {
+		return nil, err
+	}
+	for _, snap := range snaps {
+		if !vh.snapshotReady(log, snap) {
+			return nil, nil
+		}
+	}
+	return snaps, nil
+}
+
 func (vh *VolumeHandler) ensureClone(ctx context.Context, log logr.Logger,
 	src *corev1.PersistentVolumeClaim, name string, isTemporary bool) (*corev1.PersistentVolumeClaim, error) {
 	clone := &corev1.PersistentVolumeClaim{
@@ -270,6 +383,7 @@ func (vh *VolumeHandler) ensureClone(ctx context.Context, log logr.Logger,
 		if isTemporary {
 			utils.MarkForCleanup(vh.owner, clone)
 		}
+		vh.markPostHooksPending(clone)
 		if clone.CreationTimestamp.IsZero() {
 			if vh.capacity != nil {
 				clone.Spec.Resources.Requests = corev1.ResourceList{
@@ -290,6 +404,7 @@ func (vh *VolumeHandler) ensureClone(ctx context.Context, log logr.Logger,
 			} else {
 				clone.Spec.AccessModes = src.Spec.AccessModes
 			}
+			clone.Spec.VolumeMode = src.Spec.VolumeMode
 			clone.Spec.DataSource = &corev1.TypedLocalObjectReference{
 				APIGroup: nil,
 				Kind:     "PersistentVolumeClaim",
@@ -302,12 +417,8 @@ func (vh *VolumeHandler) ensureClone(ctx context.Context, log logr.Logger,
 		logger.Error(err, "reconcile failed")
 		return nil, err
 	}
-	if !clone.DeletionTimestamp.IsZero() {
-		logger.V(1).Info("PVC is being deleted-- need to wait")
-		return nil, nil
-	}
 	logger.V(1).Info("clone reconciled", "operation", op)
-	return clone, err
+	return clone, nil
 }
 
 func (vh *VolumeHandler) ensureSnapshot(ctx context.Context, log logr.Logger,
@@ -328,6 +439,7 @@ func (vh *VolumeHandler) ensureSnapshot(ctx context.Context, log logr.Logger,
 		if isTemporary {
 			utils.MarkForCleanup(vh.owner, snap)
 		}
+		vh.markPostHooksPending(snap)
 		if snap.CreationTimestamp.IsZero() {
 			snap.Spec.Source.PersistentVolumeClaimName = &src.Name
 			snap.Spec.VolumeSnapshotClassName = vh.volumeSnapshotClassName
@@ -338,16 +450,27 @@ func (vh *VolumeHandler) ensureSnapshot(ctx context.Context, log logr.Logger,
 		logger.Error(err, "reconcile failed")
 		return nil, err
 	}
+	if op == ctrlutil.OperationResultCreated {
+		vh.eventRecorder.Eventf(vh.owner, corev1.EventTypeNormal, utils.EvRSnapshotCreated,
+			"created VolumeSnapshot %s from PVC %s, waiting for it to be bound", snap.Name, src.Name)
+	}
+	logger.V(1).Info("temporary snapshot reconciled", "operation", op)
+	return snap, nil
+}
+
+// snapshotReady returns true if the snapshot can be used as the data source of
+// a PVC.
+func (vh *VolumeHandler) snapshotReady(log logr.Logger, snap *snapv1.VolumeSnapshot) bool {
+	logger := log.WithValues("snapshot", client.ObjectKeyFromObject(snap))
 	if !snap.DeletionTimestamp.IsZero() {
 		logger.V(1).Info("snap is being deleted-- need to wait")
-		return nil, nil
+		return false
 	}
 	if snap.Status == nil || snap.Status.BoundVolumeSnapshotContentName == nil {
 		logger.V(1).Info("waiting for snapshot to be bound")
-		return nil, nil
+		return false
 	}
-	logger.V(1).Info("temporary snapshot reconciled", "operation", op)
-	return snap, nil
+	return true
 }
 
 func (vh *VolumeHandler) pvcFromSnapshot(ctx context.Context, log logr.Logger,
@@ -393,6 +516,7 @@ func (vh *VolumeHandler) pvcFromSnapshot(ctx context.Context, log logr.Logger,
 			} else {
 				pvc.Spec.AccessModes = original.Spec.AccessModes
 			}
+			pvc.Spec.VolumeMode = original.Spec.VolumeMode
 			pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
 				APIGroup: &snapv1.SchemeGroupVersion.Group,
 				Kind:     "VolumeSnapshot",
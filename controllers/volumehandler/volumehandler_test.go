@@ -19,18 +19,22 @@ package volumehandler
 
 import (
 	"context"
+	"errors"
 
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
 	//sc "github.com/backube/volsync/controllers"
 )
 
@@ -99,6 +103,7 @@ var _ = Describe("Volumehandler", func() {
 			It("can be used to provision a temporary PVC", func() {
 				vh, err := NewVolumeHandler(
 					WithClient(k8sClient),
+					WithRecorder(&record.FakeRecorder{}),
 					WithOwner(rd),
 					FromDestination(&rd.Spec.Rsync.ReplicationDestinationVolumeOptions),
 				)
@@ -112,6 +117,22 @@ var _ = Describe("Volumehandler", func() {
 				Expect(*new.Spec.StorageClassName).To(Equal(customSC))
 				Expect(*(new.Spec.Resources.Requests.Storage())).To(Equal((capacity)))
 				Expect(new.Name).To(Equal(pvcName))
+				Expect(*new.Spec.VolumeMode).To(Equal(corev1.PersistentVolumeFilesystem))
+			})
+			It("can provision a Block volume", func() {
+				block := corev1.PersistentVolumeBlock
+				rd.Spec.Rsync.VolumeMode = &block
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithRecorder(&record.FakeRecorder{}),
+					WithOwner(rd),
+					FromDestination(&rd.Spec.Rsync.ReplicationDestinationVolumeOptions),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				new, err := vh.EnsureNewPVC(context.TODO(), logger, "blockpvc")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(*new.Spec.VolumeMode).To(Equal(corev1.PersistentVolumeBlock))
 			})
 		})
 
@@ -131,6 +152,7 @@ var _ = Describe("Volumehandler", func() {
 				It("the preserved image is the PVC", func() {
 					vh, err := NewVolumeHandler(
 						WithClient(k8sClient),
+						WithRecorder(&record.FakeRecorder{}),
 						WithOwner(rd),
 						FromDestination(&rd.Spec.Rsync.ReplicationDestinationVolumeOptions),
 					)
@@ -179,6 +201,7 @@ var _ = Describe("Volumehandler", func() {
 			It("the preserved image is a snapshot of the PVC", func() {
 				vh, err := NewVolumeHandler(
 					WithClient(k8sClient),
+					WithRecorder(&record.FakeRecorder{}),
 					WithOwner(rd),
 					FromDestination(&rd.Spec.Rsync.ReplicationDestinationVolumeOptions),
 				)
@@ -308,6 +331,7 @@ var _ = Describe("Volumehandler", func() {
 			It("creates a temporary PVC from a source", func() {
 				vh, err := NewVolumeHandler(
 					WithClient(k8sClient),
+					WithRecorder(&record.FakeRecorder{}),
 					WithOwner(rs),
 					FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
 				)
@@ -335,6 +359,7 @@ var _ = Describe("Volumehandler", func() {
 				It("is reflected in the cloned PVC", func() {
 					vh, err := NewVolumeHandler(
 						WithClient(k8sClient),
+						WithRecorder(&record.FakeRecorder{}),
 						WithOwner(rs),
 						FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
 					)
@@ -353,6 +378,263 @@ var _ = Describe("Volumehandler", func() {
 				})
 			})
 		})
+		When("hooks are configured", func() {
+			var exec *fakeExecutor
+			BeforeEach(func() {
+				rs.Spec.Rsync.CopyMethod = volsyncv1alpha1.CopyMethodClone
+				selector := metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}
+				rs.Spec.Hooks = &volsyncv1alpha1.SyncHooksSpec{
+					Pre:  []volsyncv1alpha1.HookSpec{{PodSelector: selector, Command: []string{"pre"}}},
+					Post: []volsyncv1alpha1.HookSpec{{PodSelector: selector, Command: []string{"post"}}},
+				}
+				exec = &fakeExecutor{}
+				utils.PodExec = exec
+			})
+			AfterEach(func() {
+				utils.PodExec = nil
+			})
+			JustBeforeEach(func() {
+				pod := &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "db",
+						Namespace: ns.Name,
+						Labels:    map[string]string{"app": "db"},
+					},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "c", Image: "db"}},
+					},
+				}
+				Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+				pod.Status.Phase = corev1.PodRunning
+				Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+			})
+
+			It("runs the pre hooks before the copy and the post hooks once it's taken", func() {
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithRecorder(&record.FakeRecorder{}),
+					WithOwner(rs),
+					FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
+					WithHooks(rs.Spec.Hooks),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				clone, err := vh.EnsurePVCFromSrc(ctx, logger, src, "newpvc", true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(clone).ToNot(BeNil())
+				Expect(exec.commands).To(Equal([][]string{{"pre"}}))
+				Expect(clone.Annotations).To(HaveKey(postHooksAnnotation))
+
+				// The clone hasn't been provisioned yet
+				_, err = vh.EnsurePVCFromSrc(ctx, logger, src, "newpvc", true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(exec.commands).To(Equal([][]string{{"pre"}}))
+
+				clone.Status.Phase = corev1.ClaimBound
+				Expect(k8sClient.Status().Update(ctx, clone)).To(Succeed())
+				clone, err = vh.EnsurePVCFromSrc(ctx, logger, src, "newpvc", true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(exec.commands).To(Equal([][]string{{"pre"}, {"post"}}))
+				Expect(clone.Annotations).NotTo(HaveKey(postHooksAnnotation))
+			})
+			It("runs the hooks only once for a group of copies", func() {
+				src2 := &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "src2",
+						Namespace: ns.Name,
+					},
+				}
+				src.Spec.DeepCopyInto(&src2.Spec)
+				Expect(k8sClient.Create(ctx, src2)).To(Succeed())
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithRecorder(&record.FakeRecorder{}),
+					WithOwner(rs),
+					FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
+					WithHooks(rs.Spec.Hooks),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				srcs := []*corev1.PersistentVolumeClaim{src, src2}
+				names := []string{"newpvc", "newpvc2"}
+				clones, err := vh.EnsurePVCsFromSrc(ctx, logger, srcs, names, true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(clones).To(HaveLen(2))
+				Expect(exec.commands).To(Equal([][]string{{"pre"}}))
+
+				// The post hooks wait for all the clones to be provisioned
+				clones[0].Status.Phase = corev1.ClaimBound
+				Expect(k8sClient.Status().Update(ctx, clones[0])).To(Succeed())
+				_, err = vh.EnsurePVCsFromSrc(ctx, logger, srcs, names, true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(exec.commands).To(Equal([][]string{{"pre"}}))
+
+				clones[1].Status.Phase = corev1.ClaimBound
+				Expect(k8sClient.Status().Update(ctx, clones[1])).To(Succeed())
+				clones, err = vh.EnsurePVCsFromSrc(ctx, logger, srcs, names, true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(exec.commands).To(Equal([][]string{{"pre"}, {"post"}}))
+				for _, clone := range clones {
+					Expect(clone.Annotations).NotTo(HaveKey(postHooksAnnotation))
+				}
+			})
+			It("runs the post hooks if a pre hook fails", func() {
+				exec.err = errors.New("table is locked")
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithRecorder(&record.FakeRecorder{}),
+					WithOwner(rs),
+					FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
+					WithHooks(rs.Spec.Hooks),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				clone, err := vh.EnsurePVCFromSrc(ctx, logger, src, "newpvc", true)
+				Expect(err).To(HaveOccurred())
+				Expect(clone).To(BeNil())
+				Expect(exec.commands).To(Equal([][]string{{"pre"}, {"post"}}))
+				pvc := &corev1.PersistentVolumeClaim{}
+				err = k8sClient.Get(ctx, types.NamespacedName{Name: "newpvc", Namespace: ns.Name}, pvc)
+				Expect(kerrors.IsNotFound(err)).To(BeTrue())
+			})
+			It("doesn't run hooks in Pods the ServiceAccount may not exec into", func() {
+				exec.denied = true
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithRecorder(&record.FakeRecorder{}),
+					WithOwner(rs),
+					FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
+					WithHooks(rs.Spec.Hooks),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				clone, err := vh.EnsurePVCFromSrc(ctx, logger, src, "newpvc", true)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("not allowed to exec"))
+				Expect(clone).To(BeNil())
+				Expect(exec.commands).To(BeEmpty())
+			})
+			It("runs the post hooks once if creating one of the copies fails", func() {
+				// The clone of a source without a capacity is rejected
+				src2 := &corev1.PersistentVolumeClaim{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "src2",
+						Namespace: ns.Name,
+					},
+				}
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithRecorder(&record.FakeRecorder{}),
+					WithOwner(rs),
+					FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
+					WithHooks(rs.Spec.Hooks),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				srcs := []*corev1.PersistentVolumeClaim{src, src2}
+				names := []string{"newpvc", "newpvc2"}
+				_, err = vh.EnsurePVCsFromSrc(ctx, logger, srcs, names, true)
+				Expect(err).To(HaveOccurred())
+				Expect(exec.commands).To(Equal([][]string{{"pre"}, {"post"}}))
+				pvc := &corev1.PersistentVolumeClaim{}
+				err = k8sClient.Get(ctx, types.NamespacedName{Name: "newpvc", Namespace: ns.Name}, pvc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pvc.Annotations).NotTo(HaveKey(postHooksAnnotation))
+
+				// The hooks aren't run again while the copies are incomplete
+				_, err = vh.EnsurePVCsFromSrc(ctx, logger, srcs, names, true)
+				Expect(err).To(HaveOccurred())
+				Expect(exec.commands).To(Equal([][]string{{"pre"}, {"post"}}))
+			})
+			It("runs the pending post hooks of copies that won't be taken", func() {
+				vh, err := NewVolumeHandler(
+					WithClient(k8sClient),
+					WithRecorder(&record.FakeRecorder{}),
+					WithOwner(rs),
+					FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
+					WithHooks(rs.Spec.Hooks),
+				)
+				Expect(err).NotTo(HaveOccurred())
+
+				clone, err := vh.EnsurePVCFromSrc(ctx, logger, src, "newpvc", true)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(clone.Annotations).To(HaveKey(postHooksAnnotation))
+
+				Expect(vh.ReleaseCopyHooks(ctx, logger)).To(Succeed())
+				Expect(exec.commands).To(Equal([][]string{{"pre"}, {"post"}}))
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(clone), clone)).To(Succeed())
+				Expect(clone.Annotations).NotTo(HaveKey(postHooksAnnotation))
+
+				// Nothing is pending anymore
+				Expect(vh.ReleaseCopyHooks(ctx, logger)).To(Succeed())
+				Expect(exec.commands).To(Equal([][]string{{"pre"}, {"post"}}))
+			})
+			When("there are several hooks", func() {
+				BeforeEach(func() {
+					selector := rs.Spec.Hooks.Pre[0].PodSelector
+					rs.Spec.Hooks.Pre = append(rs.Spec.Hooks.Pre,
+						volsyncv1alpha1.HookSpec{PodSelector: selector, Command: []string{"pre2"}})
+				})
+				It("stops at a failed hook with the Fail policy", func() {
+					exec.err = errors.New("table is locked")
+					exec.failOn = "pre"
+					vh, err := NewVolumeHandler(
+						WithClient(k8sClient),
+						WithRecorder(&record.FakeRecorder{}),
+						WithOwner(rs),
+						FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
+						WithHooks(rs.Spec.Hooks),
+					)
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = vh.EnsurePVCFromSrc(ctx, logger, src, "newpvc", true)
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("pre hook 0 failed"))
+					Expect(exec.commands).To(Equal([][]string{{"pre"}, {"post"}}))
+				})
+				It("runs the remaining hooks after a failed hook with the Continue policy", func() {
+					rs.Spec.Hooks.Pre[0].OnError = volsyncv1alpha1.HookErrorPolicyContinue
+					exec.err = errors.New("table is locked")
+					exec.failOn = "pre"
+					recorder := record.NewFakeRecorder(10)
+					vh, err := NewVolumeHandler(
+						WithClient(k8sClient),
+						WithRecorder(recorder),
+						WithOwner(rs),
+						FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
+						WithHooks(rs.Spec.Hooks),
+					)
+					Expect(err).NotTo(HaveOccurred())
+
+					clone, err := vh.EnsurePVCFromSrc(ctx, logger, src, "newpvc", true)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(clone).ToNot(BeNil())
+					Expect(exec.commands).To(Equal([][]string{{"pre"}, {"pre2"}}))
+					// The failure is still reported
+					Expect(recorder.Events).To(Receive(ContainSubstring(utils.EvRHookFailed)))
+				})
+			})
+			When("the hook failure policy is Continue", func() {
+				BeforeEach(func() {
+					rs.Spec.Hooks.Pre[0].OnError = volsyncv1alpha1.HookErrorPolicyContinue
+				})
+				It("creates the copy anyway", func() {
+					exec.err = errors.New("table is locked")
+					vh, err := NewVolumeHandler(
+						WithClient(k8sClient),
+						WithRecorder(&record.FakeRecorder{}),
+						WithOwner(rs),
+						FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
+						WithHooks(rs.Spec.Hooks),
+					)
+					Expect(err).NotTo(HaveOccurred())
+
+					clone, err := vh.EnsurePVCFromSrc(ctx, logger, src, "newpvc", true)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(clone).ToNot(BeNil())
+				})
+			})
+		})
 		When("CopyMethod is Snapshot", func() {
 			BeforeEach(func() {
 				rs.Spec.Rsync.CopyMethod = volsyncv1alpha1.CopyMethodSnapshot
@@ -360,6 +642,7 @@ var _ = Describe("Volumehandler", func() {
 			It("creates a temporary PVC from a source", func() {
 				vh, err := NewVolumeHandler(
 					WithClient(k8sClient),
+					WithRecorder(&record.FakeRecorder{}),
 					WithOwner(rs),
 					FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
 				)
@@ -413,6 +696,7 @@ var _ = Describe("Volumehandler", func() {
 				It("is reflected in the new PVC", func() {
 					vh, err := NewVolumeHandler(
 						WithClient(k8sClient),
+						WithRecorder(&record.FakeRecorder{}),
 						WithOwner(rs),
 						FromSource(&rs.Spec.Rsync.ReplicationSourceVolumeOptions),
 					)
@@ -457,3 +741,26 @@ var _ = Describe("Volumehandler", func() {
 		})
 	})
 })
+
+// fakeExecutor records the commands it is asked to run
+type fakeExecutor struct {
+	commands [][]string
+	err      error
+	// failOn limits err to the command with this name
+	failOn string
+	// denied makes the hooks' ServiceAccount unable to exec into Pods
+	denied bool
+}
+
+func (e *fakeExecutor) CanExec(ctx context.Context, pod *corev1.Pod, serviceAccount string) (bool, error) {
+	return !e.denied, nil
+}
+
+func (e *fakeExecutor) Exec(ctx context.Context, pod *corev1.Pod, container string,
+	command []string) (string, string, error) {
+	e.commands = append(e.commands, command)
+	if e.failOn != "" && command[0] != e.failOn {
+		return "", "", nil
+	}
+	return "", "", e.err
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	"github.com/backube/volsync/controllers/utils"
+)
+
+const (
+	// Prefix of the name of the temporary PVC that is provisioned from the
+	// ReplicationDestination's image before its volume is handed to the PVC
+	// being populated
+	populatorPVCPrefix = "volsync-populate-"
+	// Annotation set by the scheduler on PVCs that use a WaitForFirstConsumer
+	// StorageClass
+	annSelectedNode = "volume.kubernetes.io/selected-node"
+	// Annotation recording the image that a PVC was populated from
+	annPopulatedFrom = "volsync.backube/populated-from"
+)
+
+// VolumePopulatorReconciler fills PVCs whose spec.dataSourceRef refers to a
+// ReplicationDestination with the contents of the destination's latest image
+type VolumePopulatorReconciler struct {
+	client.Client
+	Log           logr.Logger
+	Scheme        *runtime.Scheme
+	EventRecorder record.EventRecorder
+}
+
+//nolint:lll
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=volsync.backube,resources=replicationdestinations,verbs=get;list;watch
+
+// Reconcile provisions a temporary PVC from the ReplicationDestination's
+// latest image, then rebinds the resulting PersistentVolume to the PVC that is
+// being populated. This is the same approach that is used by the Kubernetes
+// lib-volume-populator.
+func (r *VolumePopulatorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("pvc", req.NamespacedName)
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Client.Get(ctx, req.NamespacedName, pvc); err != nil {
+		if !kerrors.IsNotFound(err) {
+			logger.Error(err, "Failed to get PVC")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !isPopulatedByReplicationDestination(pvc) || !pvc.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	populatorPVC := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      populatorPVCPrefix + string(pvc.UID),
+			Namespace: pvc.Namespace,
+		},
+	}
+
+	// Once the PVC has its volume, the temporary PVC is no longer needed
+	if pvc.Spec.VolumeName != "" {
+		err := r.Client.Delete(ctx, populatorPVC)
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	rd := &volsyncv1alpha1.ReplicationDestination{}
+	rdName := client.ObjectKey{Namespace: pvc.Namespace, Name: pvc.Spec.DataSourceRef.Name}
+	if err := r.Client.Get(ctx, rdName, rd); err != nil {
+		if kerrors.IsNotFound(err) {
+			r.EventRecorder.Eventf(pvc, corev1.EventTypeNormal, utils.EvRPopulatorPending,
+				"waiting for ReplicationDestination %s to be created", rdName.Name)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if rd.Status == nil || rd.Status.LatestImage == nil {
+		r.EventRecorder.Eventf(pvc, corev1.EventTypeNormal, utils.EvRPopulatorPending,
+			"waiting for ReplicationDestination %s to have an image", rd.Name)
+		return ctrl.Result{}, nil
+	}
+	image := rd.Status.LatestImage
+
+	// With WaitForFirstConsumer, the volume must be provisioned on the node
+	// that the scheduler selected for the PVC
+	waiting, err := r.waitForFirstConsumer(ctx, pvc)
+	if waiting || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	op, err := ctrlutil.CreateOrUpdate(ctx, r.Client, populatorPVC, func() error {
+		if err := ctrl.SetControllerReference(pvc, populatorPVC, r.Scheme); err != nil {
+			logger.Error(err, "unable to set controller reference")
+			return err
+		}
+		if node, ok := pvc.Annotations[annSelectedNode]; ok {
+			if populatorPVC.Annotations == nil {
+				populatorPVC.Annotations = make(map[string]string)
+			}
+			populatorPVC.Annotations[annSelectedNode] = node
+		}
+		if populatorPVC.CreationTimestamp.IsZero() {
+			populatorPVC.Spec.AccessModes = pvc.Spec.AccessModes
+			populatorPVC.Spec.Resources = pvc.Spec.Resources
+			populatorPVC.Spec.StorageClassName = pvc.Spec.StorageClassName
+			populatorPVC.Spec.VolumeMode = pvc.Spec.VolumeMode
+			populatorPVC.Spec.DataSource = image.DeepCopy()
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error(err, "unable to reconcile temporary PVC")
+		return ctrl.Result{}, err
+	}
+	logger.V(1).Info("temporary PVC reconciled", "operation", op)
+	if populatorPVC.Spec.VolumeName == "" {
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{}, r.rebindVolume(ctx, logger, pvc, populatorPVC)
+}
+
+// isPopulatedByReplicationDestination returns true if the PVC should be filled
+// from a ReplicationDestination
+func isPopulatedByReplicationDestination(pvc *corev1.PersistentVolumeClaim) bool {
+	ref := pvc.Spec.DataSourceRef
+	return ref != nil && ref.APIGroup != nil &&
+		*ref.APIGroup == volsyncv1alpha1.GroupVersion.Group &&
+		ref.Kind == "ReplicationDestination"
+}
+
+// waitForFirstConsumer returns true if the PVC uses a WaitForFirstConsumer
+// StorageClass and a node has not yet been selected for it
+func (r *VolumePopulatorReconciler) waitForFirstConsumer(ctx context.Context,
+	pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return false, nil
+	}
+	sc := &storagev1.StorageClass{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: *pvc.Spec.StorageClassName}, sc); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	if sc.VolumeBindingMode == nil || *sc.VolumeBindingMode != storagev1.VolumeBindingWaitForFirstConsumer {
+		return false, nil
+	}
+	_, selected := pvc.Annotations[annSelectedNode]
+	return !selected, nil
+}
+
+// rebindVolume hands the volume that was provisioned for the temporary PVC to
+// the PVC being populated. The Kubernetes PV controller completes the binding.
+func (r *VolumePopulatorReconciler) rebindVolume(ctx context.Context, logger logr.Logger,
+	pvc *corev1.PersistentVolumeClaim, populatorPVC *corev1.PersistentVolumeClaim) error {
+	pv := &corev1.PersistentVolume{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: populatorPVC.Spec.VolumeName}, pv); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if pv.Spec.ClaimRef != nil && pv.Spec.ClaimRef.UID == pvc.UID {
+		return nil // Already rebound, waiting for the PV controller
+	}
+
+	if pvc.Annotations == nil {
+		pvc.Annotations = make(map[string]string)
+	}
+	pvc.Annotations[annPopulatedFrom] = populatorPVC.Spec.DataSource.Kind + "/" + populatorPVC.Spec.DataSource.Name
+	if err := r.Client.Update(ctx, pvc); err != nil {
+		logger.Error(err, "unable to annotate PVC")
+		return err
+	}
+
+	pv.Spec.ClaimRef = &corev1.ObjectReference{
+		Kind:            "PersistentVolumeClaim",
+		APIVersion:      "v1",
+		Namespace:       pvc.Namespace,
+		Name:            pvc.Name,
+		UID:             pvc.UID,
+		ResourceVersion: pvc.ResourceVersion,
+	}
+	if err := r.Client.Update(ctx, pv); err != nil {
+		logger.Error(err, "unable to rebind PV", "pv", pv.Name)
+		return err
+	}
+	r.EventRecorder.Eventf(pvc, corev1.EventTypeNormal, utils.EvRVolumePopulated,
+		"populated from %s %s", populatorPVC.Spec.DataSource.Kind, populatorPVC.Spec.DataSource.Name)
+	logger.Info("volume populated", "pv", pv.Name, "source", populatorPVC.Spec.DataSource.Name)
+	return nil
+}
+
+// pvcsForReplicationDestination maps a ReplicationDestination to the PVCs that
+// are waiting to be populated from it
+func (r *VolumePopulatorReconciler) pvcsForReplicationDestination(obj client.Object) []reconcile.Request {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := r.Client.List(context.Background(), pvcs, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.Log.Error(err, "unable to list PVCs", "namespace", obj.GetNamespace())
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		if isPopulatedByReplicationDestination(pvc) && pvc.Spec.DataSourceRef.Name == obj.GetName() &&
+			pvc.Spec.VolumeName == "" {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(pvc)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *VolumePopulatorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("volumepopulator").
+		For(&corev1.PersistentVolumeClaim{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Watches(&source.Kind{Type: &volsyncv1alpha1.ReplicationDestination{}},
+			handler.EnqueueRequestsFromMapFunc(r.pvcsForReplicationDestination)).
+		Complete(r)
+}
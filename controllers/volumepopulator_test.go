@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The VolSync authors.
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU Affero General Public License as published
+by the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU Affero General Public License for more details.
+
+You should have received a copy of the GNU Affero General Public License
+along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+)
+
+var _ = Describe("Volume populator", func() {
+	ctx := context.Background()
+	var namespace *corev1.Namespace
+	var rd *volsyncv1alpha1.ReplicationDestination
+	var pvc *corev1.PersistentVolumeClaim
+	var populatorPVC *corev1.PersistentVolumeClaim
+
+	BeforeEach(func() {
+		namespace = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "volsync-populator-",
+			},
+		}
+		Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+		Expect(namespace.Name).NotTo(BeEmpty())
+
+		rd = &volsyncv1alpha1.ReplicationDestination{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "rd",
+				Namespace: namespace.Name,
+			},
+			Spec: volsyncv1alpha1.ReplicationDestinationSpec{
+				External: &volsyncv1alpha1.ReplicationDestinationExternalSpec{},
+			},
+		}
+		Expect(k8sClient.Create(ctx, rd)).To(Succeed())
+
+		apiGroup := volsyncv1alpha1.GroupVersion.Group
+		pvc = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "restored",
+				Namespace: namespace.Name,
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse("1Gi"),
+					},
+				},
+				DataSourceRef: &corev1.TypedLocalObjectReference{
+					APIGroup: &apiGroup,
+					Kind:     "ReplicationDestination",
+					Name:     rd.Name,
+				},
+			},
+		}
+	})
+	JustBeforeEach(func() {
+		Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+		populatorPVC = &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      populatorPVCPrefix + string(pvc.UID),
+				Namespace: namespace.Name,
+			},
+		}
+	})
+	AfterEach(func() {
+		Expect(k8sClient.Delete(ctx, namespace)).To(Succeed())
+	})
+
+	When("the ReplicationDestination has no image", func() {
+		It("waits to populate the PVC", func() {
+			Consistently(func() error {
+				return k8sClient.Get(ctx, client.ObjectKeyFromObject(populatorPVC), populatorPVC)
+			}, duration, interval).ShouldNot(Succeed())
+		})
+	})
+
+	When("the ReplicationDestination has an image", func() {
+		BeforeEach(func() {
+			rd.Status = &volsyncv1alpha1.ReplicationDestinationStatus{
+				LatestImage: &corev1.TypedLocalObjectReference{
+					APIGroup: &snapv1.SchemeGroupVersion.Group,
+					Kind:     "VolumeSnapshot",
+					Name:     "snap",
+				},
+			}
+			Expect(k8sClient.Status().Update(ctx, rd)).To(Succeed())
+		})
+		It("provisions a volume from the image and hands it to the PVC", func() {
+			Eventually(func() error {
+				return k8sClient.Get(ctx, client.ObjectKeyFromObject(populatorPVC), populatorPVC)
+			}, maxWait, interval).Should(Succeed())
+			Expect(populatorPVC).To(beOwnedBy(pvc))
+			Expect(populatorPVC.Spec.DataSource).To(Equal(rd.Status.LatestImage))
+			Expect(populatorPVC.Spec.Resources).To(Equal(pvc.Spec.Resources))
+
+			// Pretend the temporary PVC has been provisioned
+			pv := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					GenerateName: "pv-",
+				},
+				Spec: corev1.PersistentVolumeSpec{
+					AccessModes: pvc.Spec.AccessModes,
+					Capacity:    pvc.Spec.Resources.Requests,
+					PersistentVolumeSource: corev1.PersistentVolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{Path: "/tmp"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pv)).To(Succeed())
+			populatorPVC.Spec.VolumeName = pv.Name
+			Expect(k8sClient.Update(ctx, populatorPVC)).To(Succeed())
+
+			Eventually(func() types.UID {
+				_ = k8sClient.Get(ctx, client.ObjectKeyFromObject(pv), pv)
+				if pv.Spec.ClaimRef == nil {
+					return ""
+				}
+				return pv.Spec.ClaimRef.UID
+			}, maxWait, interval).Should(Equal(pvc.UID))
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+			Expect(pvc.Annotations).To(HaveKeyWithValue(annPopulatedFrom, "VolumeSnapshot/snap"))
+
+			// Once the PVC is bound, the temporary PVC is removed
+			pvc.Spec.VolumeName = pv.Name
+			Expect(k8sClient.Update(ctx, pvc)).To(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(ctx, client.ObjectKeyFromObject(populatorPVC), populatorPVC)
+				return err != nil || !populatorPVC.DeletionTimestamp.IsZero()
+			}, maxWait, interval).Should(BeTrue())
+			Expect(k8sClient.Delete(ctx, pv)).To(Succeed())
+		})
+	})
+})
@@ -178,3 +178,11 @@ kubectl annotate volumesnapshotclass/csi-hostpath-snapclass snapshot.storage.kub
 
 # Add a node topology key so that e2e tests can run
 kubectl label nodes --all topology.kubernetes.io/zone=z1
+
+# Install cert-manager. It issues the webhook certificate when VolSync is
+# deployed via kustomize (make deploy). The Helm chart doesn't need it.
+CERT_MANAGER_VERSION="${CERT_MANAGER_VERSION:-v1.8.0}"
+if [[ $KUBE_MINOR -ge 19 ]]; then
+  kubectl apply -f "https://github.com/cert-manager/cert-manager/releases/download/${CERT_MANAGER_VERSION}/cert-manager.yaml"
+  kubectl -n cert-manager wait --for=condition=Available --timeout=300s deployment --all
+fi
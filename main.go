@@ -18,10 +18,13 @@ along with this program.  If not, see <https://www.gnu.org/licenses/>.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -30,19 +33,25 @@ import (
 	snapv1 "github.com/kubernetes-csi/external-snapshotter/client/v4/apis/volumesnapshot/v1"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	kruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	volsyncv1alpha1 "github.com/backube/volsync/api/v1alpha1"
+	volsyncv1beta1 "github.com/backube/volsync/api/v1beta1"
 	"github.com/backube/volsync/controllers"
 	"github.com/backube/volsync/controllers/mover"
 	"github.com/backube/volsync/controllers/mover/rclone"
 	"github.com/backube/volsync/controllers/mover/restic"
 	"github.com/backube/volsync/controllers/mover/rsync"
+	"github.com/backube/volsync/controllers/mover/syncthing"
 	"github.com/backube/volsync/controllers/utils"
 	//+kubebuilder:scaffold:imports
 )
@@ -56,7 +65,9 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(snapv1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	utilruntime.Must(volsyncv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(volsyncv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -75,10 +86,17 @@ func main() {
 		setupLog.Error(err, "Error registering restic data mover")
 		os.Exit(1)
 	}
+	if err := syncthing.Register(); err != nil {
+		setupLog.Error(err, "Error registering syncthing data mover")
+		os.Exit(1)
+	}
 
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var maxConcurrentSyncs int
+	var maxConcurrentSyncsPerNamespace int
+	var conversionService string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -86,6 +104,25 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&utils.SCCName, "scc-name",
 		utils.DefaultSCCName, "The name of the volsync security context constraint")
+	flag.IntVar(&maxConcurrentSyncs, "max-concurrent-syncs", 0,
+		"The maximum number of synchronizations that may run at the same time (0 is unlimited)")
+	flag.IntVar(&maxConcurrentSyncsPerNamespace, "max-concurrent-syncs-per-namespace", 0,
+		"The maximum number of synchronizations that may run at the same time in a namespace (0 is unlimited)")
+	flag.StringVar(&utils.DefaultCustomCA.ConfigMapName, "mover-ca-configmap", "",
+		"The name of the ConfigMap in each namespace holding a CA bundle for the data movers to trust")
+	flag.StringVar(&utils.DefaultCustomCA.SecretName, "mover-ca-secret", "",
+		"The name of the Secret in each namespace holding a CA bundle for the data movers to trust")
+	flag.StringVar(&utils.DefaultCustomCA.Key, "mover-ca-key", "",
+		"The key of the CA bundle within its ConfigMap or Secret (default \"ca.crt\")")
+	flag.StringVar(&utils.DefaultProxy.HTTPProxy, "mover-http-proxy", "", "The HTTP proxy for the data movers")
+	flag.StringVar(&utils.DefaultProxy.HTTPSProxy, "mover-https-proxy", "", "The HTTPS proxy for the data movers")
+	flag.StringVar(&utils.DefaultProxy.NoProxy, "mover-no-proxy", "",
+		"Comma-separated hosts and domains that the data movers reach without the proxy")
+	flag.BoolVar(&mover.AllowCustomImages, "allow-custom-mover-images", false,
+		"Allow ReplicationSources and ReplicationDestinations to set the data mover image")
+	flag.StringVar(&conversionService, "conversion-webhook-service", "",
+		"The <namespace>/<name> of the webhook Service. If set, the CRDs are configured at startup "+
+			"to use it for conversion, trusting the ca.crt of the webhook's serving certificate.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -101,6 +138,12 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	if utils.DefaultCustomCA.ConfigMapName != "" && utils.DefaultCustomCA.SecretName != "" {
+		setupLog.Error(fmt.Errorf("only one of --mover-ca-configmap or --mover-ca-secret may be provided"),
+			"invalid CA bundle")
+		os.Exit(1)
+	}
+
 	setupLog.Info(fmt.Sprintf("Go Version: %s", runtime.Version()))
 	setupLog.Info(fmt.Sprintf("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH))
 	setupLog.Info(fmt.Sprintf("Operator Version: %s", volsyncVersion))
@@ -115,28 +158,69 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "b95b3104.backube",
+		// Pods and ConfigMaps are only read within a single namespace when
+		// needed, so read them directly instead of caching all of them in the
+		// cluster
+		ClientDisableCacheFor: []client.Object{&corev1.Pod{}, &corev1.ConfigMap{}},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	if conversionService != "" {
+		if err = setupCRDConversion(mgr.GetConfig(), conversionService); err != nil {
+			setupLog.Error(err, "unable to configure CRD conversion")
+			os.Exit(1)
+		}
+	}
+
+	if utils.PodExec, err = utils.NewPodExecutor(mgr.GetConfig()); err != nil {
+		setupLog.Error(err, "unable to create Pod executor")
+		os.Exit(1)
+	}
+
+	// Synchronizations of both sources and destinations count toward the limits
+	syncLimiter := controllers.NewSyncLimiter(maxConcurrentSyncs, maxConcurrentSyncsPerNamespace)
 	if err = (&controllers.ReplicationSourceReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("ReplicationSource"),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("ReplicationSource"),
+		Scheme:        mgr.GetScheme(),
+		EventRecorder: mgr.GetEventRecorderFor("volsync-controller"),
+		SyncLimiter:   syncLimiter,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ReplicationSource")
 		os.Exit(1)
 	}
 	if err = (&controllers.ReplicationDestinationReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("ReplicationDestination"),
-		Scheme: mgr.GetScheme(),
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("ReplicationDestination"),
+		Scheme:        mgr.GetScheme(),
+		EventRecorder: mgr.GetEventRecorderFor("volsync-controller"),
+		SyncLimiter:   syncLimiter,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ReplicationDestination")
 		os.Exit(1)
 	}
+	if err = (&controllers.VolumePopulatorReconciler{
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("VolumePopulator"),
+		Scheme:        mgr.GetScheme(),
+		EventRecorder: mgr.GetEventRecorderFor("volsync-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "VolumePopulator")
+		os.Exit(1)
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&volsyncv1alpha1.ReplicationSource{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ReplicationSource")
+			os.Exit(1)
+		}
+		if err = (&volsyncv1alpha1.ReplicationDestination{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ReplicationDestination")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -154,3 +238,22 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// setupCRDConversion points the CRDs' conversion at the webhook Service,
+// <namespace>/<name>, trusting the CA of the webhook's serving certificate
+func setupCRDConversion(config *rest.Config, service string) error {
+	parts := strings.Split(service, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("webhook service must be <namespace>/<name>: %s", service)
+	}
+	caBundle, err := os.ReadFile(filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs", "ca.crt"))
+	if err != nil {
+		return err
+	}
+	// The manager's cache isn't running yet
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	return utils.EnsureCRDConversion(context.Background(), c, utils.ConversionCRDs, parts[0], parts[1], caBundle)
+}
@@ -4,6 +4,13 @@ set -e -o pipefail
 
 echo "VolSync rclone container version: ${version:-unknown}"
 
+# Trust the custom CA bundle, if one was provided, in addition to the system's
+if [[ -s "${CUSTOM_CA}" ]]; then
+    CA_BUNDLE=$(mktemp -q)
+    cat /etc/pki/tls/certs/ca-bundle.crt "${CUSTOM_CA}" > "${CA_BUNDLE}" 2>/dev/null || true
+    export SSL_CERT_FILE="${CA_BUNDLE}"
+fi
+
 function error {
     rc="$1"
     shift
@@ -11,31 +18,66 @@ function error {
     exit "$rc"
 }
 
+# Write a summary of the transfer to the termination log so that it can be
+# collected by the VolSync controller. The size transferred is taken from the
+# last of rclone's one-line stats (e.g., "1.500 MiB / 1.500 MiB, 100%, ..."),
+# and each transferred file is logged as "Copied".
+function write_summary {
+    local bytes files
+    bytes=$(sed 's/\x1b\[[0-9;]*[A-Za-z]//g' "$1" |
+        grep -oE '[0-9.]+ ?[KMGTPE]?i?B? / [0-9.]+ ?[KMGTPE]?i?B?, [0-9-]+%' | tail -1 |
+        awk '{
+            size = $1; unit = ($2 == "/") ? "" : $2
+            if (unit == "") {
+                match(size, /[KMGTPE]?i?B?$/)
+                unit = substr(size, RSTART)
+                size = substr(size, 1, RSTART - 1)
+            }
+            printf "%d", size * 1024 ^ index("KMGTPE", substr(unit, 1, 1))
+        }' || true)
+    files=$(grep -c ': Copied (' "$1" || true)
+    printf '{"bytesTransferred":%d,"filesTransferred":%d}\n' "${bytes:-0}" "${files:-0}" \
+        > "${TERMINATION_LOG:-/dev/termination-log}" || true
+}
+
 # Rclone config file that gets mounted as a Secret onto RCLONE_CONFIG
 
 [[ -n "${RCLONE_DEST_PATH}" ]] || error 1 "RCLONE_DEST_PATH must be defined"
 [[ -n "${DIRECTION}" ]] || error 1 "DIRECTION must be defined"
 
-RCLONE_FLAGS=(--checksum --one-file-system --create-empty-src-dirs --progress --stats-one-line-date --stats 20s --transfers 10)
+RCLONE_FLAGS=(--checksum --create-empty-src-dirs --progress --stats-one-line-date --stats 20s --transfers 10)
+# The members of a volume group are separate mounts below MOUNT_PATH
+if [[ "${VOLUME_GROUP}" != "true" ]]; then
+    RCLONE_FLAGS+=(--one-file-system)
+fi
 
 START_TIME=$SECONDS
 case "${DIRECTION}" in
 source)
     getfacl -R "${MOUNT_PATH}" > "${MOUNT_PATH}"/permissons.facl
-    rclone sync "${RCLONE_FLAGS[@]}" "${MOUNT_PATH}" "${RCLONE_CONFIG_SECTION}:${RCLONE_DEST_PATH}" --log-level DEBUG
+    rclone sync "${RCLONE_FLAGS[@]}" "${MOUNT_PATH}" "${RCLONE_CONFIG_SECTION}:${RCLONE_DEST_PATH}" --log-level DEBUG 2>&1 | tee /tmp/rclone.log
     rm -rf "${MOUNT_PATH}"/permissons.facl
     rc=$?
     ;;
 destination)
-    rclone sync "${RCLONE_FLAGS[@]}" "${RCLONE_CONFIG_SECTION}:${RCLONE_DEST_PATH}" "${MOUNT_PATH}" --log-level DEBUG
+    rclone sync "${RCLONE_FLAGS[@]}" "${RCLONE_CONFIG_SECTION}:${RCLONE_DEST_PATH}" "${MOUNT_PATH}" --log-level DEBUG 2>&1 | tee /tmp/rclone.log
     setfacl --restore="${MOUNT_PATH}"/permissons.facl || true
     rm -rf "${MOUNT_PATH}"/permissons.facl
     rc=$?
     ;;
+delete)
+    # The path may have already been removed
+    if rclone lsf "${RCLONE_CONFIG_SECTION}:${RCLONE_DEST_PATH}" > /dev/null 2>&1; then
+        rclone purge "${RCLONE_CONFIG_SECTION}:${RCLONE_DEST_PATH}"
+    fi
+    echo "Removed ${RCLONE_CONFIG_SECTION}:${RCLONE_DEST_PATH}"
+    exit 0
+    ;;
 *)
     error 1 "unknown value for DIRECTION: ${DIRECTION}"
     ;;
 esac
 sync
+write_summary /tmp/rclone.log
 echo "Rclone completed in $(( SECONDS - START_TIME ))s rc=$rc"
 exit "$rc"
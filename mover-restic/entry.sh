@@ -9,11 +9,28 @@ set -e -o pipefail
 echo "VolSync restic container version: ${version:-unknown}"
 echo  "$@"
 
+# Trust the custom CA bundle, if one was provided, in addition to the system's
+if [[ -s "${CUSTOM_CA}" ]]; then
+    CA_BUNDLE=$(mktemp -q)
+    cat /etc/pki/tls/certs/ca-bundle.crt "${CUSTOM_CA}" > "${CA_BUNDLE}" 2>/dev/null || true
+    export SSL_CERT_FILE="${CA_BUNDLE}"
+fi
 
-# Force the associated backup host name to be "volsync"
-RESTIC_HOST="volsync"
+
+# The host name (and optional comma-separated tags) that identify this
+# volume's snapshots in the repository
+RESTIC_HOST="${RESTIC_HOST:-volsync}"
+# Restic options that select this volume's snapshots
+SNAPSHOT_FILTER=(--host "${RESTIC_HOST}")
+if [[ -n ${RESTIC_TAGS} ]]; then
+    SNAPSHOT_FILTER+=(--tag "${RESTIC_TAGS}")
+fi
 # Make restic output progress reports every 10s
 export RESTIC_PROGRESS_FPS=0.1
+# The most snapshots that may be listed in the status
+MAX_SNAPSHOT_LIST=20
+# The size limit of the termination message that write_summary produces
+MAX_SUMMARY_BYTES=4096
 
 # Print an error message and exit
 # error rc "message"
@@ -22,6 +39,55 @@ function error {
     exit "$1"
 }
 
+# Run restic, exiting with a distinct code (4) if the repository is locked so
+# that the controller can report it. The lock error is saved as the
+# termination message.
+# restic args...
+function restic {
+    local rc=0 errfile
+    errfile=$(mktemp -q)
+    command restic "$@" 2>"$errfile" || rc=$?
+    cat "$errfile" >&2
+    if [[ $rc -ne 0 ]] && grep -q "repository is already locked" "$errfile"; then
+        head -c 1024 "$errfile" > "${TERMINATION_LOG:-/dev/termination-log}" || true
+        rm -f "$errfile"
+        error 4 "repository is locked"
+    fi
+    rm -f "$errfile"
+    return $rc
+}
+
+# Remove the repository's locks if they are all older than UNLOCK_STALE_AFTER
+# seconds. A newer lock may belong to a running restic process, so nothing is
+# removed if one is present. Restic refreshes the locks of running processes
+# every 5 minutes, so ages below MIN_UNLOCK_STALE_AFTER are raised to it to
+# avoid removing a live lock held by another mover sharing the repository.
+MIN_UNLOCK_STALE_AFTER=1800
+function remove_stale_locks {
+    if [[ -z ${UNLOCK_STALE_AFTER} ]]; then
+        return
+    fi
+    if (( UNLOCK_STALE_AFTER < MIN_UNLOCK_STALE_AFTER )); then
+        echo "Stale lock age ${UNLOCK_STALE_AFTER}s is too short, using ${MIN_UNLOCK_STALE_AFTER}s"
+        UNLOCK_STALE_AFTER=${MIN_UNLOCK_STALE_AFTER}
+    fi
+    local ids id created now
+    ids=$(command restic list locks --no-lock 2>/dev/null || true)
+    if [[ -z ${ids} ]]; then
+        return
+    fi
+    now=$(date +%s)
+    for id in ${ids}; do
+        created=$(command restic cat lock "${id}" --no-lock 2>/dev/null | grep -o '"time":"[^"]*"' | cut -d'"' -f4 || true)
+        if [[ -z ${created} ]] || (( now - $(date --date="${created}" +%s) < UNLOCK_STALE_AFTER )); then
+            echo "Lock ${id} is not stale, leaving locks in place"
+            return
+        fi
+    done
+    echo "=== Removing stale locks ==="
+    command restic unlock --remove-all
+}
+
 # Error and exit if a variable isn't defined
 # check_var_defined "MY_VAR"
 function check_var_defined {
@@ -56,18 +122,97 @@ function ensure_initialized {
     rm -f "$outfile"
 }
 
+# Write a summary of the run to the termination log so that it can be
+# collected by the VolSync controller. It includes the transfer statistics of
+# the backup, the result of the repository check, and the repository
+# inventory, if each is available.
+function write_summary {
+    local fields=() summary bytes files_new files_changed
+    if [[ -f /tmp/backup.json ]]; then
+        summary=$(grep '"message_type":"summary"' /tmp/backup.json | tail -1 || true)
+        bytes=$(grep -o '"data_added":[0-9]*' <<< "$summary" | cut -d: -f2 || true)
+        files_new=$(grep -o '"files_new":[0-9]*' <<< "$summary" | cut -d: -f2 || true)
+        files_changed=$(grep -o '"files_changed":[0-9]*' <<< "$summary" | cut -d: -f2 || true)
+        fields+=("$(printf '"bytesTransferred":%d,"filesTransferred":%d' "${bytes:-0}" \
+            "$(( ${files_new:-0} + ${files_changed:-0} ))")")
+    fi
+    if [[ -n ${CHECK_RESULT} ]]; then
+        fields+=("$(printf '"checkResult":"%s","checkMessage":"%s"' "${CHECK_RESULT}" "${CHECK_MESSAGE}")")
+    fi
+    if [[ -n ${REPOSITORY_STATS} ]]; then
+        fields+=("\"repository\":${REPOSITORY_STATS}" "\"snapshots\":${SNAPSHOT_LIST}")
+    fi
+    if [[ ${#fields[@]} -eq 0 ]]; then
+        return
+    fi
+    local IFS=, output
+    output=$(printf '{%s}' "${fields[*]}")
+    # A longer message would be truncated into invalid JSON, so the oldest
+    # snapshots are dropped from the list until it fits
+    while [[ $(printf '%s' "$output" | wc -c) -gt ${MAX_SUMMARY_BYTES} ]] && jq -e '.snapshots | length > 0' <<< "$output" > /dev/null; do
+        output=$(jq -c '.snapshots |= .[:-1]' <<< "$output")
+    done
+    echo "$output" > "${TERMINATION_LOG:-/dev/termination-log}" || true
+}
+
+# Gather the repository's snapshot count and the most recent SNAPSHOT_LIMIT of
+# this volume's snapshots, newest first, for write_summary. The snapshots are
+# listed with a single "restic snapshots", so their sizes are only included if
+# restic recorded a summary with them. The repository's total size requires
+# reading its whole index, so it's only gathered when the repository is checked.
+function collect_inventory {
+    echo "=== Collecting repository inventory ==="
+    local snapshots limit total
+    limit=$(( SNAPSHOT_LIMIT < MAX_SNAPSHOT_LIST ? SNAPSHOT_LIMIT : MAX_SNAPSHOT_LIST ))
+    snapshots=$(restic snapshots --json) || return 1
+    SNAPSHOT_LIST=$(jq -c --arg host "${RESTIC_HOST}" --arg tags "${RESTIC_TAGS}" --argjson limit "${limit}" '
+        map(select(.hostname == $host and (($tags | split(",")) - (.tags // []) | length == 0)))
+        | if $limit > 0 then .[-$limit:] | reverse else [] end
+        | map({id: .short_id, time, paths} + if .summary then {size: .summary.total_bytes_processed} else {} end)
+        ' <<< "$snapshots") || return 1
+    REPOSITORY_STATS=$(jq -c '{snapshotCount: length}' <<< "$snapshots") || return 1
+    if [[ -n ${COLLECT_TOTAL_SIZE} ]]; then
+        total=$(restic stats --mode raw-data --json | jq '.total_size') || return 1
+        REPOSITORY_STATS=$(jq -c --argjson total "${total}" '. + {totalSize: $total}' <<< "$REPOSITORY_STATS")
+    fi
+}
+
+# Convert a newline-separated list into repetitions of the given restic
+# option, storing them in the array named by the first argument
+# pattern_args array_name "--option" "list"
+function pattern_args {
+    local -n _args=$1
+    local pattern
+    while IFS= read -r pattern; do
+        if [[ -n ${pattern} ]]; then
+            _args+=("$2" "${pattern}")
+        fi
+    done <<< "$3"
+}
+
 function do_backup {
     echo "=== Starting backup ==="
-    pushd "${DATA_DIR}"
-    restic backup --host "${RESTIC_HOST}" .
-    popd
+    local tags=() args=()
+    pattern_args tags --tag "${RESTIC_TAGS//,/$'\n'}"
+    pattern_args args --exclude "${EXCLUDE}"
+    pattern_args args --exclude-if-present "${EXCLUDE_IF_PRESENT}"
+    if [[ -n ${BLOCK_DEVICE} ]]; then
+        # Block volumes are backed up as a single image file. Its path
+        # (/data.img) still matches the snapshot selection during restore.
+        restic backup --host "${RESTIC_HOST}" --json "${tags[@]}" --stdin \
+            --stdin-filename data.img < "${BLOCK_DEVICE}" | tee /tmp/backup.json
+    else
+        pushd "${DATA_DIR}"
+        restic backup --host "${RESTIC_HOST}" --json "${tags[@]}" "${args[@]}" . | tee /tmp/backup.json
+        popd
+    fi
 }
 
 function do_forget {
     echo "=== Starting forget ==="
     if [[ -n ${FORGET_OPTIONS} ]]; then
         #shellcheck disable=SC2086
-        restic forget --host "${RESTIC_HOST}" ${FORGET_OPTIONS}
+        restic forget "${SNAPSHOT_FILTER[@]}" ${FORGET_OPTIONS}
     fi
 }
 
@@ -76,6 +221,46 @@ function do_prune {
     restic prune
 }
 
+# Verify the integrity of the repository. A failed check doesn't fail the
+# Job; the result is reported to the controller instead.
+function do_check {
+    echo "=== Starting check ==="
+    local args=()
+    if [[ -n ${CHECK_READ_DATA_SUBSET} ]]; then
+        args+=(--read-data-subset "${CHECK_READ_DATA_SUBSET}")
+    fi
+    set +e
+    restic check "${args[@]}" 2>&1 | tee /tmp/check.log
+    local rc=${PIPESTATUS[0]}
+    set -e
+    if [[ ${rc} -eq 0 ]]; then
+        CHECK_RESULT="Succeeded"
+        CHECK_MESSAGE=""
+    else
+        CHECK_RESULT="Failed"
+        # Keep the message short & safe to embed in JSON
+        CHECK_MESSAGE=$(grep -v '^$' /tmp/check.log | tail -1 | tr -d '"\\' | cut -c1-256)
+    fi
+}
+
+# Remove all of this volume's snapshots and the data they reference
+function do_delete {
+    # Snapshots of the legacy host may belong to any object that uses the
+    # repository, so they can't be told apart without tags
+    if [[ ${RESTIC_HOST} == "volsync" && -z ${RESTIC_TAGS} ]]; then
+        error 1 "refusing to remove the snapshots of the shared host ${RESTIC_HOST} without tags"
+    fi
+    echo "=== Removing snapshots ==="
+    local ids
+    ids=$(restic snapshots "${SNAPSHOT_FILTER[@]}" --json | grep -o '"short_id":"[0-9a-f]*"' | cut -d'"' -f4 || true)
+    if [[ -z ${ids} ]]; then
+        echo "No snapshots to remove"
+        return
+    fi
+    #shellcheck disable=SC2086
+    restic forget --prune ${ids}
+}
+
 #######################################
 # Trims the provided timestamp and
 # returns one in the format: YYYY-MM-DD hh:mm:ss
@@ -161,7 +346,7 @@ function select_restic_snapshot_to_restore() {
 
     # go through the timestamps received from restic
     IFS=$'\n'
-    for line in $(restic -r "${RESTIC_REPOSITORY}" snapshots | grep /data | awk '{print $1 "\t" $2 " " $3}'); do
+    for line in $(restic -r "${RESTIC_REPOSITORY}" snapshots "${SNAPSHOT_FILTER[@]}" | grep /data | awk '{print $1 "\t" $2 " " $3}'); do
         # extract the proper variables
         snapshot_id=$(echo -e "${line}" | cut -d$'\t' -f1)
         snapshot_ts=$(echo -e "${line}" | cut -d$'\t' -f2)
@@ -208,20 +393,30 @@ function select_restic_snapshot_to_restore() {
 #   RESTORE_AS_OF
 #   DATA_DIR
 #   RESTIC_HOST
+#   INCLUDE
 # Arguments:
 #   None
 #######################################
 function do_restore {
     echo "=== Starting restore ==="
     # restore from specific snapshot specified by timestamp, or latest
-    local snapshot_id
+    local snapshot_id args=()
+    pattern_args args --include "${INCLUDE}"
+    # Restoring nothing would leave the volume empty, most likely because the
+    # hostname doesn't match that of the ReplicationSource
+    if ! restic snapshots --json "${SNAPSHOT_FILTER[@]}" | grep -q '"short_id"'; then
+        error 1 "no snapshots found for host ${RESTIC_HOST}${RESTIC_TAGS:+ with tags ${RESTIC_TAGS}}"
+    fi
     snapshot_id=$(select_restic_snapshot_to_restore)
     if [[ -z ${snapshot_id} ]]; then 
         echo "No eligible snapshots found"
+    elif [[ -n ${BLOCK_DEVICE} ]]; then
+        echo "Selected restic snapshot with id: ${snapshot_id}"
+        restic dump "${snapshot_id}" /data.img > "${BLOCK_DEVICE}"
     else
     pushd "${DATA_DIR}"
         echo "Selected restic snapshot with id: ${snapshot_id}"
-        restic restore -t . --host "${RESTIC_HOST}" "${snapshot_id}"
+        restic restore -t . "${SNAPSHOT_FILTER[@]}" "${args[@]}" "${snapshot_id}"
         popd
     fi
 }
@@ -236,25 +431,44 @@ for var in RESTIC_CACHE_DIR \
     check_var_defined $var
 done
 
+remove_stale_locks
+
 for op in "$@"; do
     case $op in
         "backup")
-            check_contents
+            if [[ -z ${BLOCK_DEVICE} ]]; then
+                check_contents
+            fi
             ensure_initialized
             do_backup
             do_forget
+            COLLECT_INVENTORY=1
             ;;
         "prune")
             do_prune
+            COLLECT_INVENTORY=1
+            ;;
+        "check")
+            do_check
+            COLLECT_INVENTORY=1
+            COLLECT_TOTAL_SIZE=1
+            ;;
+        "delete")
+            do_delete
             ;;
         "restore")
             do_restore
+            COLLECT_INVENTORY=1
             ;;
         *)
             error 2 "unknown operation: $op"
             ;;
     esac
 done
+if [[ -n ${COLLECT_INVENTORY} && -n ${SNAPSHOT_LIMIT} ]]; then
+    collect_inventory || echo "unable to collect the repository inventory"
+fi
+write_summary
 sync
 echo "=== Done ==="
 # sleep forever so that the containers logs can be inspected
@@ -20,10 +20,22 @@ function do_rsync {
     LANG=C rrsync /data
 }
 
+function do_blockwrite {
+    # The source streams the contents of its Block volume into our device
+    if [[ ! -b /dev/block ]]; then
+        echo "Destination volume is not a Block volume"
+        exit 1
+    fi
+    dd of=/dev/block bs=4M iflag=fullblock conv=fsync status=none
+}
+
 #-- These are the only commands allowed to be executed by the source side:
 # Source can initiate an rsync
 if [[ "$SSH_ORIGINAL_COMMAND" =~ ^rsync( ) ]]; then
     do_rsync
+# Source can stream a Block volume
+elif [[ "$SSH_ORIGINAL_COMMAND" =~ ^blockwrite$ ]]; then
+    do_blockwrite
 # Source can tell us (destination) to shutdown & pass a numeric result code
 elif [[ "$SSH_ORIGINAL_COMMAND" =~ ^shutdown( )+([0-9]+)$ ]]; then
     do_shutdown "${BASH_REMATCH[2]}"
@@ -11,6 +11,35 @@ if [[ -z "$DESTINATION_ADDRESS" ]]; then
     exit 1
 fi
 
+# Write a summary of the transfer to the termination log so that it can be
+# collected by the VolSync controller
+function write_summary {
+    local files bytes
+    files=$(sed -n 's/^Number of regular files transferred: \([0-9,]*\)$/\1/p' "$1" | tail -1 | tr -d ,)
+    bytes=$(sed -n 's/^Total transferred file size: \([0-9,]*\) bytes$/\1/p' "$1" | tail -1 | tr -d ,)
+    printf '{"bytesTransferred":%d,"filesTransferred":%d}\n' "${bytes:-0}" "${files:-0}" \
+        > "${TERMINATION_LOG:-/dev/termination-log}" || true
+}
+
+# Copy the data to the destination. Block volumes are streamed in full to the
+# destination's device since rsync can't read their contents.
+function do_transfer {
+    if [[ -n "${BLOCK_DEVICE}" ]]; then
+        dd if="${BLOCK_DEVICE}" bs=4M 2>/tmp/dd.log | ssh -C "root@${DESTINATION_ADDRESS}" blockwrite
+        local status=("${PIPESTATUS[@]}")
+        if [[ ${status[0]} -ne 0 || ${status[1]} -ne 0 ]]; then
+            echo "Block transfer failed: dd rc=${status[0]}, ssh rc=${status[1]}"
+            cat /tmp/dd.log
+            return 1
+        fi
+        # Report the transfer in the same form as rsync's stats
+        echo "Number of regular files transferred: 1"
+        echo "Total transferred file size: $(sed -n 's/^\([0-9]*\) bytes.*$/\1/p' /tmp/dd.log) bytes"
+    else
+        rsync -aAhHSxz --no-h --delete --itemize-changes --info=stats2,misc2 /data/ "root@${DESTINATION_ADDRESS}":.
+    fi
+}
+
 mkdir -p ~/.ssh/controlmasters
 chmod 711 ~/.ssh
 
@@ -39,7 +68,7 @@ Host *
   TCPKeepAlive no
 SSHCONFIG
 
-MAX_RETRIES=5
+MAX_RETRIES=${MAX_RETRIES:-5}
 RETRY=0
 DELAY=2
 FACTOR=2
@@ -51,7 +80,7 @@ set +e
 while [[ ${rc} -ne 0 && ${RETRY} -lt ${MAX_RETRIES} ]]
 do
     RETRY=$((RETRY + 1))
-    rsync -aAhHSxz --delete --itemize-changes --info=stats2,misc2 /data/ "root@${DESTINATION_ADDRESS}":.
+    do_transfer | tee /tmp/rsync.log
     rc=$?
     if [[ ${rc} -ne 0 ]]; then
         echo "Syncronization failed. Retrying in ${DELAY} seconds. Retry ${RETRY}/${MAX_RETRIES}."
@@ -64,6 +93,7 @@ echo "Rsync completed in $(( SECONDS - START_TIME ))s"
 sync
 if [[ $rc -eq 0 ]]; then
     echo "Synchronization completed successfully. Notifying destination..."
+    write_summary /tmp/rsync.log
     ssh "root@${DESTINATION_ADDRESS}" shutdown 0
 else
     echo "Synchronization failed. rsync returned: $rc"